@@ -0,0 +1,91 @@
+// Command catalog-import loads an Open Food Facts export into the offline
+// product catalog that new products are prefilled from.
+//
+// Usage:
+//
+//	go run ./cmd/catalog-import [-format csv|jsonl] <file>
+//
+// The file may be gzip-compressed. Without -format the format is taken from
+// the file name (.csv, .tsv, .jsonl or .json, optionally followed by .gz).
+// The database is configured through MONGO_URL and MONGO_DB_NAME, as for the
+// server.
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"my-backend/internal/catalog"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	format := flag.String("format", "", "csv or jsonl (default: from the file name)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-format csv|jsonl] <file>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	path := flag.Arg(0)
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, relying on environment variables")
+	}
+
+	if *format == "" {
+		*format = formatOf(path)
+		if *format == "" {
+			log.Fatalf("cannot tell the format of %s; pass -format", path)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	stats, err := catalog.Import(ctx, r, *format, func(s catalog.Stats) {
+		log.Printf("read %d, imported %d, skipped %d", s.Read, s.Imported, s.Skipped)
+	})
+	if err != nil {
+		log.Fatalf("import failed after %d records: %v", stats.Read, err)
+	}
+	log.Printf("done: read %d, imported %d, skipped %d", stats.Read, stats.Imported, stats.Skipped)
+}
+
+func formatOf(path string) string {
+	name := strings.TrimSuffix(strings.ToLower(path), ".gz")
+	switch {
+	case strings.HasSuffix(name, ".csv"), strings.HasSuffix(name, ".tsv"):
+		return catalog.FormatCSV
+	case strings.HasSuffix(name, ".jsonl"), strings.HasSuffix(name, ".json"):
+		return catalog.FormatJSONL
+	}
+	return ""
+}
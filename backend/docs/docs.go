@@ -15,54 +15,40 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api": {
-            "get": {
-                "description": "Lists available API endpoints and brief descriptions.",
-                "produces": [
-                    "application/json"
+        "/api/2fa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
-                "tags": [
-                    "meta"
+                "description": "Enables two-factor login after checking a first code from the authenticator app. Returns one-time recovery codes, which are only shown once.",
+                "consumes": [
+                    "application/json"
                 ],
-                "summary": "API index",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    }
-                }
-            }
-        },
-        "/api/categories": {
-            "get": {
-                "description": "Returns categories filtered by StockID.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "categories"
+                    "two-factor"
                 ],
-                "summary": "List categories by stock",
+                "summary": "Confirm TOTP enrollment",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Stock ID (UUID)",
-                        "name": "stockId",
-                        "in": "query",
-                        "required": true
+                        "description": "Code from the authenticator app",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.twoFactorConfirmRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Categories"
-                            }
+                            "$ref": "#/definitions/handlers.recoveryCodesResponse"
                         }
                     },
                     "400": {
@@ -74,6 +60,24 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -84,9 +88,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/2fa/disable": {
             "post": {
-                "description": "Creates multiple categories in a single request.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Turns two-factor login off. Requires the account password (or, for accounts without one, a sign-in within the last 10 minutes) and a current code or recovery code.",
                 "consumes": [
                     "application/json"
                 ],
@@ -94,30 +105,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "categories"
+                    "two-factor"
                 ],
-                "summary": "Bulk create categories",
+                "summary": "Disable TOTP",
                 "parameters": [
                     {
-                        "description": "List of categories",
+                        "description": "Password and second factor",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/handlers.categoryRequest"
-                            }
+                            "$ref": "#/definitions/handlers.twoFactorDisableRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Categories"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -130,6 +138,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -142,35 +159,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/categories/{categoryId}": {
-            "delete": {
-                "description": "Sets Category to null on related products (matching StockID and category name) then deletes the category document.",
+        "/api/2fa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Generates a new TOTP secret and otpauth URI. Two-factor login is only enabled once the secret is confirmed with a code.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "categories"
-                ],
-                "summary": "Delete a category",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Category ID (UUID)",
-                        "name": "categoryId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "two-factor"
                 ],
+                "summary": "Start TOTP enrollment",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/handlers.twoFactorEnrollResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -178,8 +190,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -199,62 +211,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/health": {
+        "/api/admin/stats": {
             "get": {
-                "description": "Returns the current status of the API.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "meta"
-                ],
-                "summary": "Health check",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                "security": [
+                    {
+                        "BearerAuth": []
                     }
-                }
-            }
-        },
-        "/api/login": {
-            "post": {
-                "description": "Authenticate user by email and password.",
-                "consumes": [
-                    "application/json"
                 ],
+                "description": "Returns totals for users, stocks, products and categories plus per-stock product counts. Admin only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
-                ],
-                "summary": "Login user",
-                "parameters": [
-                    {
-                        "description": "Login credentials",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.loginRequest"
-                        }
-                    }
+                    "admin"
                 ],
+                "summary": "System-wide stock statistics",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Users"
+                            "$ref": "#/definitions/handlers.systemStats"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -262,8 +242,8 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -283,23 +263,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/products": {
+        "/api/admin/users": {
             "get": {
-                "description": "Returns products filtered by StockID.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns every registered user, optionally filtered by status and role. Admin only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "admin"
                 ],
-                "summary": "List products",
+                "summary": "List users",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Stock ID (UUID)",
-                        "name": "stockId",
-                        "in": "query",
-                        "required": true
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by role",
+                        "name": "role",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -308,53 +298,21 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Products"
+                                "$ref": "#/definitions/models.Users"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            },
-            "post": {
-                "description": "Creates a new product record.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "products"
-                ],
-                "summary": "Create a product",
-                "parameters": [
-                    {
-                        "description": "Product data",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.createProductRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/models.Products"
-                        }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -374,9 +332,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/products/{productId}": {
+        "/api/admin/users/{userId}/role": {
             "put": {
-                "description": "Updates mutable fields on an existing product.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Grants or removes the admin role. Admins cannot demote themselves. Admin only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -384,24 +347,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "admin"
                 ],
-                "summary": "Update a product",
+                "summary": "Change a user's role",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product ID (UUID)",
-                        "name": "productId",
+                        "description": "User ID (UUID)",
+                        "name": "userId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Fields to update",
+                        "description": "New role",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.updateProductRequest"
+                            "$ref": "#/definitions/handlers.updateUserRoleRequest"
                         }
                     }
                 ],
@@ -409,7 +372,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Products"
+                            "$ref": "#/definitions/models.Users"
                         }
                     },
                     "400": {
@@ -421,6 +384,24 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -440,31 +421,49 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Deletes a product by ID.",
-                "produces": [
+            }
+        },
+        "/api/admin/users/{userId}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Moves a user to a new Status and records who made the change and why. Leaving ACTIVE revokes the user's sessions. Admin only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "products"
+                    "admin"
                 ],
-                "summary": "Delete a product",
+                "summary": "Change a user's status",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Product ID (UUID)",
-                        "name": "productId",
+                        "description": "User ID (UUID)",
+                        "name": "userId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "New status and reason",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.updateUserStatusRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.Users"
                         }
                     },
                     "400": {
@@ -476,6 +475,42 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -488,35 +523,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/register": {
-            "post": {
-                "description": "Creates a new user document with a hashed password.",
-                "consumes": [
-                    "application/json"
+        "/api/admin/users/{userId}/status-history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Returns every recorded status change for a user, newest first. Admin only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Register a new user",
+                "summary": "User status history",
                 "parameters": [
                     {
-                        "description": "User registration data",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.registerRequest"
-                        }
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Users"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserStatusChanges"
+                            }
                         }
                     },
                     "400": {
@@ -528,8 +566,17 @@ const docTemplate = `{
                             }
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -549,32 +596,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/warehouse": {
+        "/api/api-keys": {
             "get": {
-                "description": "Returns warehouse filtered by UserID.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the authenticated user's API keys, including revoked and expired ones.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "warehouse"
-                ],
-                "summary": "List warehouse",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "User ID (UUID)",
-                        "name": "userId",
-                        "in": "query",
-                        "required": true
-                    }
+                    "api-keys"
                 ],
+                "summary": "List API keys",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Warehouse"
+                                "$ref": "#/definitions/models.APIKeys"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
                             }
                         }
                     },
@@ -590,7 +651,12 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Creates a new stock record.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a personal API key with the given scopes and optional expiry. The key is only returned once; use it as \"Authorization: ApiKey \u003ckey\u003e\".",
                 "consumes": [
                     "application/json"
                 ],
@@ -598,17 +664,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "warehouse"
+                    "api-keys"
                 ],
-                "summary": "Create a stock",
+                "summary": "Create an API key",
                 "parameters": [
                     {
-                        "description": "Stock data",
+                        "description": "Key name, scopes and expiry",
                         "name": "payload",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.createStockRequest"
+                            "$ref": "#/definitions/handlers.createAPIKeyRequest"
                         }
                     }
                 ],
@@ -616,7 +682,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Warehouse"
+                            "$ref": "#/definitions/handlers.createAPIKeyResponse"
                         }
                     },
                     "400": {
@@ -628,6 +694,24 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -640,21 +724,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/warehouse/{stockId}": {
+        "/api/api-keys/{keyId}": {
             "delete": {
-                "description": "Deletes a stock by ID and removes related products with the same StockID.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes one of the authenticated user's API keys immediately.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "warehouse"
+                    "api-keys"
                 ],
-                "summary": "Delete a stock",
+                "summary": "Revoke an API key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Stock ID (UUID)",
-                        "name": "stockId",
+                        "description": "API key ID (UUID)",
+                        "name": "keyId",
                         "in": "path",
                         "required": true
                     }
@@ -676,6 +765,33 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -687,122 +803,6053 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handlers.categoryRequest": {
-            "type": "object",
-            "properties": {
-                "CategoryName": {
-                    "type": "string"
-                },
-                "Discription": {
-                    "type": "string"
-                },
-                "StockID": {
-                    "type": "string"
-                }
-            }
-        },
-        "handlers.createProductRequest": {
-            "type": "object",
-            "properties": {
-                "Category": {
-                    "type": "string"
-                },
-                "ProductName": {
-                    "type": "string"
-                },
-                "ProductQty": {
-                    "type": "integer"
-                },
-                "StockID": {
-                    "type": "string"
-                },
-                "Unit": {
-                    "type": "string"
-                }
-            }
-        },
-        "handlers.createStockRequest": {
-            "type": "object",
-            "properties": {
-                "StockName": {
-                    "type": "string"
-                },
-                "UserID": {
-                    "type": "string"
-                }
-            }
-        },
-        "handlers.loginRequest": {
-            "type": "object",
-            "properties": {
-                "Email": {
-                    "type": "string"
-                },
-                "Password": {
-                    "type": "string"
-                }
-            }
         },
-        "handlers.registerRequest": {
-            "type": "object",
-            "properties": {
-                "AvatarURL": {
-                    "type": "string"
-                },
-                "DisplayName": {
-                    "type": "string"
-                },
-                "Email": {
-                    "type": "string"
-                },
-                "Password": {
-                    "type": "string"
-                }
-            }
+        "/api/audit": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns audit entries newest first. With stockId the entries of that stock are returned (requires access to the stock); without it, the changes made by the authenticated user, limited to stock changes for API keys. The total number of matches is sent in X-Total-Count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "List audit log entries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by entity type, e.g. product",
+                        "name": "entityType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by entity ID",
+                        "name": "entityId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries per page (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditLog"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/catalog/lookup": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the offline catalog entry for a barcode, to prefill a new product when the barcode is not in any stock yet. The catalog is imported from Open Food Facts with the catalog-import command.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Look up a barcode in the product catalog",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "EAN-8, UPC-A, EAN-13 or GTIN-14",
+                        "name": "barcode",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CatalogProducts"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/categories": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns categories filtered by StockID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories"
+                ],
+                "summary": "List categories by stock",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Categories"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates multiple categories in a single request.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories"
+                ],
+                "summary": "Bulk create categories",
+                "parameters": [
+                    {
+                        "description": "List of categories",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.categoryRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Categories"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/categories/{categoryId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets Category to null on related products (matching StockID and category name) then deletes the category document.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "categories"
+                ],
+                "summary": "Delete a category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID (UUID)",
+                        "name": "categoryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/health": {
+            "get": {
+                "description": "Returns the current status of the API.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meta"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/households": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the households the authenticated user belongs to, with their role in each.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "households"
+                ],
+                "summary": "List households",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.householdSummary"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a household with the authenticated user as its owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "households"
+                ],
+                "summary": "Create a household",
+                "parameters": [
+                    {
+                        "description": "Household name",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.householdRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.householdSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/households/join": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Accepts an invite code and adds the authenticated user to the household with the invite's role. Wrong codes count towards a temporary lockout per user and per client IP.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "households"
+                ],
+                "summary": "Join a household",
+                "parameters": [
+                    {
+                        "description": "Invite code",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.joinHouseholdRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.householdSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/households/{householdId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a household with its members and stocks. Members only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "households"
+                ],
+                "summary": "Get a household",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Household ID (UUID)",
+                        "name": "householdId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.householdDetail"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a household, its memberships and invitations. Its stocks are kept and stay with their owners. Household owners only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "households"
+                ],
+                "summary": "Delete a household",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Household ID (UUID)",
+                        "name": "householdId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/households/{householdId}/invites": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the household's invites that have not been accepted yet. Household owners only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "households"
+                ],
+                "summary": "List household invites",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Household ID (UUID)",
+                        "name": "householdId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.HouseholdInvites"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates an invite code for the given role. With an Email the code is also mailed and only that user can accept it. The code is only returned once. Household owners only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "households"
+                ],
+                "summary": "Invite to a household",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Household ID (UUID)",
+                        "name": "householdId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Invitee email (optional) and role",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.householdInviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.householdInviteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/households/{householdId}/invites/{inviteId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes an invite so its code can no longer be used. Household owners only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "households"
+                ],
+                "summary": "Revoke a household invite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Household ID (UUID)",
+                        "name": "householdId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Invite ID (UUID)",
+                        "name": "inviteId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/households/{householdId}/members/{userId}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets a member's household role. The last owner cannot be demoted. Household owners only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "households"
+                ],
+                "summary": "Change a member's role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Household ID (UUID)",
+                        "name": "householdId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member user ID (UUID)",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New role",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.householdRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HouseholdMembers"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a member from the household. Owners can remove anyone; every member can remove themselves to leave. The last owner cannot leave. Stocks the member owns are taken out of the household.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "households"
+                ],
+                "summary": "Remove a household member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Household ID (UUID)",
+                        "name": "householdId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member user ID (UUID)",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/login": {
+            "post": {
+                "description": "Authenticate user by email and password and start a session with an access and refresh token. Repeated failures lock the account and client IP with exponential backoff (429 with Retry-After). When two-factor authentication is enabled the response is a twoFactorChallengeResponse to be completed at /api/login/2fa.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Login user",
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/login/2fa": {
+            "post": {
+                "description": "Exchanges the challenge token returned by LoginUser plus a TOTP code or recovery code for a session.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Complete two-factor login",
+                "parameters": [
+                    {
+                        "description": "Challenge token and second factor",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.twoFactorLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes the session the access token belongs to.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/logout-all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revokes every session of the authenticated user, including the current one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Logout everywhere",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the authenticated user's profile. Requires a signed-in session; API keys are rejected.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Users"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Permanently deletes the authenticated user together with every stock they own, the products and categories in those stocks, their events, sessions, API keys and pending tokens, and leaves every household and stock share. The audit log is append-only: its entries are kept with the user's ID and email, also inside snapshots, replaced by a random pseudonym. Movements they recorded in other users' stocks are kept under the same pseudonym. Requires the password (or, for accounts without one, a sign-in within the last 10 minutes), plus a code or recovery code when two-factor login is enabled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete current user",
+                "parameters": [
+                    {
+                        "description": "Password and, with 2FA, a second factor",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.deleteAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates DisplayName and/or AvatarURL of the authenticated user. Fields left out are unchanged; an empty string clears them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update current user",
+                "parameters": [
+                    {
+                        "description": "Profile fields to change",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.updateProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Users"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/email": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sends a verification link to the new address. The account email only changes once the link is opened via /api/verify-email. Requires the password, or a sign-in within the last 10 minutes for accounts without one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change email address",
+                "parameters": [
+                    {
+                        "description": "New email and current password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.changeEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Downloads everything stored about the authenticated user: profile, owned stocks with their products, categories and movements, the movements of stocks they deleted, movements they made elsewhere, events, sessions, API keys, status history, pending reset and verification tokens (without the tokens), login throttling state, household memberships, households they created, invites they sent, accepted or were sent, stock shares in both directions, notification settings and queued notifications, their whole shopping list including dismissed entries, and the audit entries they made or that are about their account. Returns one JSON document, or a ZIP archive with one JSON file per section when format=zip.",
+                "produces": [
+                    "application/json",
+                    "application/zip"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Export current user's data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json (default) or zip",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.accountExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sets a new password after checking the current one. Accounts created through OIDC have no password yet and set their first one without CurrentPassword after a sign-in within the last 10 minutes. Every other session of the user is revoked; the calling session stays signed in.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.changePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/oidc/callback": {
+            "get": {
+                "description": "Redirect target of the identity provider. Exchanges the code, verifies the ID token and signs in the account with the same verified email, linking it on first use or creating it when none exists. Responds like /api/login, or redirects to OIDC_POST_LOGIN_REDIRECT with the tokens in the URL fragment when that is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Finish OpenID Connect login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State from /api/oidc/login",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/oidc/login": {
+            "get": {
+                "description": "Redirects the browser to the configured identity provider using the authorization code flow with PKCE.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Start OpenID Connect login",
+                "responses": {
+                    "302": {
+                        "description": "Found"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/password/forgot": {
+            "post": {
+                "description": "Emails a single-use reset link when the address belongs to an active account. Always responds 202, before the account is looked up, so neither the response nor its timing reveals which emails are registered.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Request a password reset",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.forgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/password/reset": {
+            "post": {
+                "description": "Sets a new password using a token from ForgotPassword. Tokens are single-use and expire; every existing session of the user is revoked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.resetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/products": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns a page of the stock's products. Pages are linked by an opaque cursor: pass the X-Next-Cursor header of a response as cursor to get the next page, together with the same sort, order and filters; the header is absent on the last page. Page numbers are not supported and page is rejected. The number of products matching the filters is sent in X-Total-Count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "List products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name (default), quantity or updated",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc (default) or desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only products in this category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only products with this unit",
+                        "name": "unit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only products with at least this quantity",
+                        "name": "minQty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only products with at most this quantity",
+                        "name": "maxQty",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only products at or below their MinQty (true) or not (false)",
+                        "name": "lowStock",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Products per page (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "X-Next-Cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Products"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new product record. Barcodes (EAN/UPC) and SKU are optional and must be unique within the stock. ProductName, Category and Unit left empty are prefilled from the offline product catalog entry of the first barcode; ProductName is required when there is none.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Create a product",
+                "parameters": [
+                    {
+                        "description": "Product data",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.createProductRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Products"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/products/lookup": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the products carrying the scanned EAN/UPC barcode in every stock the user can access, or only in stockId when given. A barcode is unique within a stock, so there is at most one match per stock. Codes are compared as GTIN-14, so a UPC-A code also finds its EAN-13 spelling with a leading 0. When nothing matches, GET /api/catalog/lookup can prefill a new product.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Look up a product by barcode",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "EAN-8, UPC-A, EAN-13 or GTIN-14",
+                        "name": "barcode",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only search this stock (UUID)",
+                        "name": "stockId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.barcodeMatch"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/products/{productId}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates mutable fields on an existing product. MinQty and ReorderQty are cleared with null, Barcodes and SKU with an empty value. A changed ProductQty is recorded as an ADJUST movement and cannot go below the quantity held in lots; use the movements endpoint to change quantities safely under concurrent use.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Update a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID (UUID)",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.updateProductRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Products"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a product by ID. Its movements stay in the ledger.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Delete a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID (UUID)",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/products/{productId}/movements": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the product's quantity ledger, newest first. The total number of movements is sent in X-Total-Count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "List a product's movements",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID (UUID)",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entries per page (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.StockMovements"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes a product's quantity by a movement. IN, OUT and WASTE take a positive Quantity; ADJUST takes a signed one. WASTE records stock that was thrown away and counts towards the waste report. Incoming stock with a LotNumber or ExpiresAt is kept as a lot. Outgoing stock is taken from LotID when given, otherwise from the earliest-expiring lots first. The change is applied atomically, so concurrent movements never lose updates, and a movement that would take the quantity below zero is rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "products"
+                ],
+                "summary": "Record a stock movement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID (UUID)",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Movement",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.createMovementRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.movementResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/register": {
+            "post": {
+                "description": "Creates a new user document with a hashed password in PENDING_VERIFICATION status and emails a verification link. The password must be at least 8 characters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User registration data",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.registerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Users"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the active sessions (signed-in devices) of the authenticated user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "List sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Sessions"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sessions/{sessionId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Signs out one device of the authenticated user, e.g. a lost phone.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Revoke a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID (UUID)",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shopping-list": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the authenticated user's shopping list. Every low-stock product in the stocks the user can access is added automatically with its suggested reorder quantity and removed again once it has recovered, unless it was already checked off. API keys without the products:write scope get the list without this update. Unchecked items come first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shopping-list"
+                ],
+                "summary": "Get shopping list",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.shoppingListEntry"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shopping-list/items": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Adds an item to the authenticated user's shopping list. With a ProductID the item is restocked into that product when the list is received; Name and Unit then default to the product's.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shopping-list"
+                ],
+                "summary": "Add a shopping list item",
+                "parameters": [
+                    {
+                        "description": "Item",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.shoppingListItemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingListItems"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shopping-list/items/{itemId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes an item from the list. An automatically added item stays hidden until its product has recovered and runs low again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shopping-list"
+                ],
+                "summary": "Remove a shopping list item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Item ID (UUID)",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Changes an item's name, quantity or unit, or checks it off.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shopping-list"
+                ],
+                "summary": "Update a shopping list item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Item ID (UUID)",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.updateShoppingListItemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShoppingListItems"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/shopping-list/receive": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Books every checked item into stock: items linked to a product get an IN movement of their quantity and are removed from the list; items without a product are simply removed. Items whose product is gone or that the user may not edit are skipped and stay on the list. Each item is booked at most once, even when the request is repeated or sent twice at the same time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shopping-list"
+                ],
+                "summary": "Receive the checked shopping list items",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.receiveShoppingListResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/token/refresh": {
+            "post": {
+                "description": "Exchanges a refresh token for a new access token and a rotated refresh token. Presenting an already rotated refresh token revokes the whole session.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.refreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.loginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/verify-email": {
+            "get": {
+                "description": "Consumes a verification token, confirms the address on the account (applying a pending email change) and activates accounts pending verification.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Verify email address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Verification token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/verify-email/resend": {
+            "post": {
+                "description": "Sends a new verification link to an account that is pending verification. Always responds 202 so the response does not reveal which emails are registered.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Resend verification email",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.resendVerificationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/warehouse": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns every stock the authenticated user can access: their own, those of their households and those shared with them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "List warehouse",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Warehouse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new stock record owned by the authenticated user, optionally inside a household the user can edit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Create a stock",
+                "parameters": [
+                    {
+                        "description": "Stock data",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.createStockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Warehouse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/warehouse/{stockId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a stock by ID and removes related products with the same StockID and the stock's shares. The movement ledger is kept. Only the stock owner can delete it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Delete a stock",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/warehouse/{stockId}/expiring": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the stock's lots that expire within the given number of days, including those already expired, soonest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "List expiring lots",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window in days (default 7, max 365)",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.expiringLot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/warehouse/{stockId}/household": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Makes a stock available to a household's members, or takes it out of its household when HouseholdID is null. Only the stock owner can do this, and they must be at least an editor of the target household.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Move a stock into a household",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target household, or null",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.assignHouseholdRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Warehouse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/warehouse/{stockId}/low-stock": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the stock's products whose ProductQty is at or below their MinQty, with a suggested reorder quantity (ReorderQty, or enough to get back above MinQty). Products without a MinQty are never listed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "List low-stock products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.lowStockItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/warehouse/{stockId}/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns how the authenticated user is notified about the stock. Channel secrets are never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Get notification settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replaces the authenticated user's notification channels and quiet hours for the stock. Channels are email (to the account's address), webhook (JSON POST, signed with Secret when given) and ntfy (topic URL, Secret is an access token). A channel sent without Secret keeps the secret stored for the same Type and URL. Notifications due during quiet hours are delivered when they end. Anyone who can view the stock can subscribe; webhook and ntfy URLs must point to public hosts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Update notification settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Channels and quiet hours",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.notificationSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/warehouse/{stockId}/shares": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the users a stock is shared with. Stock owners only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "List stock shares",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handlers.stockShareView"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Gives the user with the given email viewer or editor access to the stock. Sharing again with the same user changes their role. Stock owners only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Share a stock",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Email of the user and role",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.shareStockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockShares"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/warehouse/{stockId}/shares/{userId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a user's access to the stock. Stock owners can revoke anyone; a user can also remove their own share.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Revoke a stock share",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/warehouse/{stockId}/transfer": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Makes another user the owner of the stock. The previous owner keeps editor access through a share and the stock leaves its household. Only the current owner can transfer a stock.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Transfer stock ownership",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Email of the new owner",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handlers.transferStockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Warehouse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/warehouse/{stockId}/waste-expired": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Records a WASTE movement for every lot in the stock that has expired, taking its whole remaining quantity. Lots that changed in the meantime are reported as Skipped.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Write off expired lots",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.wasteExpiredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/warehouse/{stockId}/waste-report": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Sums the stock's WASTE movements in a month per category and product, largest first. Products without a category are reported under an empty Category.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "warehouse"
+                ],
+                "summary": "Monthly waste report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stock ID (UUID)",
+                        "name": "stockId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Month as YYYY-MM (default: the current month, UTC)",
+                        "name": "month",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handlers.wasteReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "handlers.accountExport": {
+            "type": "object",
+            "properties": {
+                "APIKeys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.APIKeys"
+                    }
+                },
+                "AuditLog": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditLog"
+                    }
+                },
+                "Categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Categories"
+                    }
+                },
+                "CreatedHouseholds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Households"
+                    }
+                },
+                "EmailVerifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EmailVerifications"
+                    }
+                },
+                "Events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Events"
+                    }
+                },
+                "ExportedAt": {
+                    "type": "string"
+                },
+                "HouseholdInvites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HouseholdInvites"
+                    }
+                },
+                "Households": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HouseholdMembers"
+                    }
+                },
+                "LoginAttempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LoginAttempts"
+                    }
+                },
+                "Movements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockMovements"
+                    }
+                },
+                "NotificationOutbox": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NotificationOutbox"
+                    }
+                },
+                "Notifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NotificationSettings"
+                    }
+                },
+                "PasswordResets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PasswordResets"
+                    }
+                },
+                "Products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Products"
+                    }
+                },
+                "Sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Sessions"
+                    }
+                },
+                "SharedByMe": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockShares"
+                    }
+                },
+                "SharedWithMe": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockShares"
+                    }
+                },
+                "ShoppingList": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ShoppingListItems"
+                    }
+                },
+                "StatusHistory": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserStatusChanges"
+                    }
+                },
+                "User": {
+                    "$ref": "#/definitions/models.Users"
+                },
+                "Warehouse": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Warehouse"
+                    }
+                }
+            }
+        },
+        "handlers.assignHouseholdRequest": {
+            "type": "object",
+            "properties": {
+                "HouseholdID": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.barcodeMatch": {
+            "type": "object",
+            "properties": {
+                "Barcodes": {
+                    "description": "Barcodes are the product's EAN/UPC codes and SKU its own article\nnumber; both are unique within the stock.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "Category": {
+                    "type": "string"
+                },
+                "Lots": {
+                    "description": "Lots split part or all of ProductQty into batches with their own expiry\ndate; whatever ProductQty holds beyond them is untracked.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductLots"
+                    }
+                },
+                "LowStockNotifiedAt": {
+                    "description": "LowStockNotifiedAt is set when a low-stock notification went out and\ncleared once the quantity recovers, so each shortage is reported once.",
+                    "type": "string"
+                },
+                "MinQty": {
+                    "description": "MinQty is the reorder point: the product is low on stock once\nProductQty is at or below it. ReorderQty is how much to buy then.",
+                    "type": "integer"
+                },
+                "ProductID": {
+                    "type": "string"
+                },
+                "ProductName": {
+                    "type": "string"
+                },
+                "ProductQty": {
+                    "type": "integer"
+                },
+                "ReorderQty": {
+                    "type": "integer"
+                },
+                "SKU": {
+                    "type": "string"
+                },
+                "StockID": {
+                    "type": "string"
+                },
+                "StockName": {
+                    "type": "string"
+                },
+                "Unit": {
+                    "type": "string"
+                },
+                "UpdatedAt": {
+                    "description": "UpdatedAt is when the product last changed; products that have not\nchanged since it was introduced have none.",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.categoryRequest": {
+            "type": "object",
+            "properties": {
+                "CategoryName": {
+                    "type": "string"
+                },
+                "Discription": {
+                    "type": "string"
+                },
+                "StockID": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.changeEmailRequest": {
+            "type": "object",
+            "properties": {
+                "NewEmail": {
+                    "type": "string"
+                },
+                "Password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.changePasswordRequest": {
+            "type": "object",
+            "properties": {
+                "CurrentPassword": {
+                    "type": "string"
+                },
+                "NewPassword": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.createAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "ExpiresAt": {
+                    "type": "string"
+                },
+                "Name": {
+                    "type": "string"
+                },
+                "Scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.createAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "APIKey": {
+                    "$ref": "#/definitions/models.APIKeys"
+                },
+                "Key": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.createMovementRequest": {
+            "type": "object",
+            "properties": {
+                "ExpiresAt": {
+                    "type": "string"
+                },
+                "LotID": {
+                    "type": "string"
+                },
+                "LotNumber": {
+                    "type": "string"
+                },
+                "Note": {
+                    "type": "string"
+                },
+                "Quantity": {
+                    "type": "integer"
+                },
+                "Reason": {
+                    "type": "string"
+                },
+                "Type": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.createProductRequest": {
+            "type": "object",
+            "properties": {
+                "Barcodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "Category": {
+                    "type": "string"
+                },
+                "ExpiresAt": {
+                    "type": "string"
+                },
+                "LotNumber": {
+                    "description": "LotNumber and ExpiresAt put the opening quantity into a lot.",
+                    "type": "string"
+                },
+                "MinQty": {
+                    "type": "integer"
+                },
+                "ProductName": {
+                    "type": "string"
+                },
+                "ProductQty": {
+                    "type": "integer"
+                },
+                "ReorderQty": {
+                    "type": "integer"
+                },
+                "SKU": {
+                    "type": "string"
+                },
+                "StockID": {
+                    "type": "string"
+                },
+                "Unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.createStockRequest": {
+            "type": "object",
+            "properties": {
+                "HouseholdID": {
+                    "type": "string"
+                },
+                "StockName": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.deleteAccountRequest": {
+            "type": "object",
+            "properties": {
+                "Code": {
+                    "type": "string"
+                },
+                "Password": {
+                    "type": "string"
+                },
+                "RecoveryCode": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.expiringLot": {
+            "type": "object",
+            "properties": {
+                "Category": {
+                    "type": "string"
+                },
+                "DaysLeft": {
+                    "description": "DaysLeft is negative for lots that have already expired.",
+                    "type": "integer"
+                },
+                "ExpiresAt": {
+                    "type": "string"
+                },
+                "LotID": {
+                    "type": "string"
+                },
+                "LotNumber": {
+                    "type": "string"
+                },
+                "ProductID": {
+                    "type": "string"
+                },
+                "ProductName": {
+                    "type": "string"
+                },
+                "Quantity": {
+                    "type": "integer"
+                },
+                "Unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.forgotPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "Email": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.householdDetail": {
+            "type": "object",
+            "properties": {
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "CreatedBy": {
+                    "type": "string"
+                },
+                "HouseholdID": {
+                    "type": "string"
+                },
+                "Members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.householdMemberView"
+                    }
+                },
+                "Name": {
+                    "type": "string"
+                },
+                "Role": {
+                    "type": "string"
+                },
+                "Stocks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Warehouse"
+                    }
+                }
+            }
+        },
+        "handlers.householdInviteRequest": {
+            "type": "object",
+            "properties": {
+                "Email": {
+                    "type": "string"
+                },
+                "Role": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.householdInviteResponse": {
+            "type": "object",
+            "properties": {
+                "Code": {
+                    "type": "string"
+                },
+                "Invite": {
+                    "$ref": "#/definitions/models.HouseholdInvites"
+                }
+            }
+        },
+        "handlers.householdMemberView": {
+            "type": "object",
+            "properties": {
+                "DisplayName": {
+                    "type": "string"
+                },
+                "Email": {
+                    "type": "string"
+                },
+                "JoinedAt": {
+                    "type": "string"
+                },
+                "Role": {
+                    "type": "string"
+                },
+                "UserID": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.householdRequest": {
+            "type": "object",
+            "properties": {
+                "Name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.householdRoleRequest": {
+            "type": "object",
+            "properties": {
+                "Role": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.householdSummary": {
+            "type": "object",
+            "properties": {
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "CreatedBy": {
+                    "type": "string"
+                },
+                "HouseholdID": {
+                    "type": "string"
+                },
+                "Name": {
+                    "type": "string"
+                },
+                "Role": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.joinHouseholdRequest": {
+            "type": "object",
+            "properties": {
+                "Code": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.loginRequest": {
+            "type": "object",
+            "properties": {
+                "Email": {
+                    "type": "string"
+                },
+                "Password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.loginResponse": {
+            "type": "object",
+            "properties": {
+                "AccessToken": {
+                    "type": "string"
+                },
+                "ExpiresAt": {
+                    "type": "string"
+                },
+                "RefreshExpiresAt": {
+                    "type": "string"
+                },
+                "RefreshToken": {
+                    "type": "string"
+                },
+                "SessionID": {
+                    "type": "string"
+                },
+                "TokenType": {
+                    "type": "string"
+                },
+                "User": {
+                    "$ref": "#/definitions/models.Users"
+                }
+            }
+        },
+        "handlers.lowStockItem": {
+            "type": "object",
+            "properties": {
+                "Barcodes": {
+                    "description": "Barcodes are the product's EAN/UPC codes and SKU its own article\nnumber; both are unique within the stock.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "Category": {
+                    "type": "string"
+                },
+                "Lots": {
+                    "description": "Lots split part or all of ProductQty into batches with their own expiry\ndate; whatever ProductQty holds beyond them is untracked.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductLots"
+                    }
+                },
+                "LowStockNotifiedAt": {
+                    "description": "LowStockNotifiedAt is set when a low-stock notification went out and\ncleared once the quantity recovers, so each shortage is reported once.",
+                    "type": "string"
+                },
+                "MinQty": {
+                    "description": "MinQty is the reorder point: the product is low on stock once\nProductQty is at or below it. ReorderQty is how much to buy then.",
+                    "type": "integer"
+                },
+                "ProductID": {
+                    "type": "string"
+                },
+                "ProductName": {
+                    "type": "string"
+                },
+                "ProductQty": {
+                    "type": "integer"
+                },
+                "ReorderQty": {
+                    "type": "integer"
+                },
+                "SKU": {
+                    "type": "string"
+                },
+                "StockID": {
+                    "type": "string"
+                },
+                "SuggestedQty": {
+                    "type": "integer"
+                },
+                "Unit": {
+                    "type": "string"
+                },
+                "UpdatedAt": {
+                    "description": "UpdatedAt is when the product last changed; products that have not\nchanged since it was introduced have none.",
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.movementResponse": {
+            "type": "object",
+            "properties": {
+                "Movement": {
+                    "$ref": "#/definitions/models.StockMovements"
+                },
+                "Product": {
+                    "$ref": "#/definitions/models.Products"
+                }
+            }
+        },
+        "handlers.notificationChannelRequest": {
+            "type": "object",
+            "properties": {
+                "Secret": {
+                    "type": "string"
+                },
+                "Type": {
+                    "type": "string"
+                },
+                "URL": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.notificationSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "Channels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.notificationChannelRequest"
+                    }
+                },
+                "QuietHours": {
+                    "$ref": "#/definitions/models.QuietHours"
+                }
+            }
+        },
+        "handlers.receiveShoppingListResponse": {
+            "type": "object",
+            "properties": {
+                "Received": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockMovements"
+                    }
+                },
+                "RemovedItems": {
+                    "type": "integer"
+                },
+                "Skipped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.receiveSkippedItem"
+                    }
+                }
+            }
+        },
+        "handlers.receiveSkippedItem": {
+            "type": "object",
+            "properties": {
+                "ItemID": {
+                    "type": "string"
+                },
+                "Name": {
+                    "type": "string"
+                },
+                "Reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.recoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "RecoveryCodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handlers.refreshTokenRequest": {
+            "type": "object",
+            "properties": {
+                "RefreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.registerRequest": {
+            "type": "object",
+            "properties": {
+                "AvatarURL": {
+                    "type": "string"
+                },
+                "DisplayName": {
+                    "type": "string"
+                },
+                "Email": {
+                    "type": "string"
+                },
+                "Password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.resendVerificationRequest": {
+            "type": "object",
+            "properties": {
+                "Email": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.resetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "NewPassword": {
+                    "type": "string"
+                },
+                "Token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.shareStockRequest": {
+            "type": "object",
+            "properties": {
+                "Email": {
+                    "type": "string"
+                },
+                "Role": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.shoppingListEntry": {
+            "type": "object",
+            "properties": {
+                "Checked": {
+                    "type": "boolean"
+                },
+                "CheckedAt": {
+                    "type": "string"
+                },
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "ItemID": {
+                    "type": "string"
+                },
+                "Name": {
+                    "type": "string"
+                },
+                "ProductID": {
+                    "type": "string"
+                },
+                "Quantity": {
+                    "type": "integer"
+                },
+                "Source": {
+                    "type": "string"
+                },
+                "StockID": {
+                    "type": "string"
+                },
+                "StockName": {
+                    "type": "string"
+                },
+                "Unit": {
+                    "type": "string"
+                },
+                "UserID": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.shoppingListItemRequest": {
+            "type": "object",
+            "properties": {
+                "Name": {
+                    "type": "string"
+                },
+                "ProductID": {
+                    "type": "string"
+                },
+                "Quantity": {
+                    "type": "integer"
+                },
+                "Unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.stockShareView": {
+            "type": "object",
+            "properties": {
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "DisplayName": {
+                    "type": "string"
+                },
+                "Email": {
+                    "type": "string"
+                },
+                "Role": {
+                    "type": "string"
+                },
+                "SharedBy": {
+                    "type": "string"
+                },
+                "StockID": {
+                    "type": "string"
+                },
+                "UserID": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.stockStats": {
+            "type": "object",
+            "properties": {
+                "ProductCount": {
+                    "type": "integer"
+                },
+                "StockID": {
+                    "type": "string"
+                },
+                "StockName": {
+                    "type": "string"
+                },
+                "TotalQty": {
+                    "type": "integer"
+                },
+                "UserID": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.systemStats": {
+            "type": "object",
+            "properties": {
+                "Categories": {
+                    "type": "integer"
+                },
+                "PerStock": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.stockStats"
+                    }
+                },
+                "Products": {
+                    "type": "integer"
+                },
+                "Stocks": {
+                    "type": "integer"
+                },
+                "TotalQty": {
+                    "type": "integer"
+                },
+                "Users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.transferStockRequest": {
+            "type": "object",
+            "properties": {
+                "Email": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.twoFactorConfirmRequest": {
+            "type": "object",
+            "properties": {
+                "Code": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.twoFactorDisableRequest": {
+            "type": "object",
+            "properties": {
+                "Code": {
+                    "type": "string"
+                },
+                "Password": {
+                    "type": "string"
+                },
+                "RecoveryCode": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.twoFactorEnrollResponse": {
+            "type": "object",
+            "properties": {
+                "OTPAuthURI": {
+                    "type": "string"
+                },
+                "Secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.twoFactorLoginRequest": {
+            "type": "object",
+            "properties": {
+                "ChallengeToken": {
+                    "type": "string"
+                },
+                "Code": {
+                    "type": "string"
+                },
+                "RecoveryCode": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.updateProductRequest": {
+            "type": "object",
+            "properties": {
+                "Barcodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "Category": {
+                    "type": "string"
+                },
+                "MinQty": {
+                    "type": "integer"
+                },
+                "ProductName": {
+                    "type": "string"
+                },
+                "ProductQty": {
+                    "type": "integer"
+                },
+                "ReorderQty": {
+                    "type": "integer"
+                },
+                "SKU": {
+                    "type": "string"
+                },
+                "Unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.updateProfileRequest": {
+            "type": "object",
+            "properties": {
+                "AvatarURL": {
+                    "type": "string"
+                },
+                "DisplayName": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.updateShoppingListItemRequest": {
+            "type": "object",
+            "properties": {
+                "Checked": {
+                    "type": "boolean"
+                },
+                "Name": {
+                    "type": "string"
+                },
+                "Quantity": {
+                    "type": "integer"
+                },
+                "Unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.updateUserRoleRequest": {
+            "type": "object",
+            "properties": {
+                "Role": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.updateUserStatusRequest": {
+            "type": "object",
+            "properties": {
+                "Reason": {
+                    "type": "string"
+                },
+                "Status": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.wasteCategory": {
+            "type": "object",
+            "properties": {
+                "Category": {
+                    "type": "string"
+                },
+                "Products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.wasteProduct"
+                    }
+                },
+                "Quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.wasteExpiredResponse": {
+            "type": "object",
+            "properties": {
+                "Skipped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.wasteSkippedLot"
+                    }
+                },
+                "Wasted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockMovements"
+                    }
+                }
+            }
+        },
+        "handlers.wasteProduct": {
+            "type": "object",
+            "properties": {
+                "Movements": {
+                    "type": "integer"
+                },
+                "ProductID": {
+                    "type": "string"
+                },
+                "ProductName": {
+                    "type": "string"
+                },
+                "Quantity": {
+                    "type": "integer"
+                },
+                "Unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "handlers.wasteReport": {
+            "type": "object",
+            "properties": {
+                "Categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handlers.wasteCategory"
+                    }
+                },
+                "Month": {
+                    "type": "string"
+                },
+                "StockID": {
+                    "type": "string"
+                },
+                "TotalQty": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handlers.wasteSkippedLot": {
+            "type": "object",
+            "properties": {
+                "LotID": {
+                    "type": "string"
+                },
+                "ProductID": {
+                    "type": "string"
+                },
+                "Reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.APIKeys": {
+            "type": "object",
+            "properties": {
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "ExpiresAt": {
+                    "type": "string"
+                },
+                "KeyID": {
+                    "type": "string"
+                },
+                "LastUsedAt": {
+                    "type": "string"
+                },
+                "Name": {
+                    "type": "string"
+                },
+                "Prefix": {
+                    "type": "string"
+                },
+                "RevokedAt": {
+                    "type": "string"
+                },
+                "Scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "UserID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuditLog": {
+            "type": "object",
+            "properties": {
+                "APIKeyID": {
+                    "type": "string"
+                },
+                "Action": {
+                    "type": "string"
+                },
+                "ActorID": {
+                    "type": "string"
+                },
+                "After": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "Before": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "EntityID": {
+                    "type": "string"
+                },
+                "EntityType": {
+                    "type": "string"
+                },
+                "EntryID": {
+                    "type": "string"
+                },
+                "StockID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CatalogProducts": {
+            "type": "object",
+            "properties": {
+                "Barcode": {
+                    "type": "string"
+                },
+                "Brand": {
+                    "type": "string"
+                },
+                "Category": {
+                    "type": "string"
+                },
+                "ImportedAt": {
+                    "type": "string"
+                },
+                "ProductName": {
+                    "type": "string"
+                },
+                "Quantity": {
+                    "description": "Quantity is the package size as printed, e.g. \"6 x 33 cl\".",
+                    "type": "string"
+                },
+                "Source": {
+                    "type": "string"
+                },
+                "Unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Categories": {
+            "type": "object",
+            "properties": {
+                "CategoryID": {
+                    "type": "string"
+                },
+                "CategoryName": {
+                    "type": "string"
+                },
+                "Discription": {
+                    "type": "string"
+                },
+                "StockID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EmailVerifications": {
+            "type": "object",
+            "properties": {
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "Email": {
+                    "type": "string"
+                },
+                "ExpiresAt": {
+                    "type": "string"
+                },
+                "UsedAt": {
+                    "type": "string"
+                },
+                "UserID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Events": {
+            "type": "object",
+            "properties": {
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "EndAt": {
+                    "type": "string"
+                },
+                "EventID": {
+                    "type": "string"
+                },
+                "EventOwner": {
+                    "type": "string"
+                },
+                "EventOwnerName": {
+                    "type": "string"
+                },
+                "Location": {
+                    "type": "string"
+                },
+                "StartAt": {
+                    "type": "string"
+                },
+                "Status": {
+                    "type": "string"
+                },
+                "Title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HouseholdInvites": {
+            "type": "object",
+            "properties": {
+                "AcceptedAt": {
+                    "type": "string"
+                },
+                "AcceptedBy": {
+                    "type": "string"
+                },
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "CreatedBy": {
+                    "type": "string"
+                },
+                "Email": {
+                    "type": "string"
+                },
+                "ExpiresAt": {
+                    "type": "string"
+                },
+                "HouseholdID": {
+                    "type": "string"
+                },
+                "InviteID": {
+                    "type": "string"
+                },
+                "Role": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.HouseholdMembers": {
+            "type": "object",
+            "properties": {
+                "HouseholdID": {
+                    "type": "string"
+                },
+                "JoinedAt": {
+                    "type": "string"
+                },
+                "Role": {
+                    "type": "string"
+                },
+                "UserID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Households": {
+            "type": "object",
+            "properties": {
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "CreatedBy": {
+                    "type": "string"
+                },
+                "HouseholdID": {
+                    "type": "string"
+                },
+                "Name": {
+                    "type": "string"
+                }
+            }
         },
-        "handlers.updateProductRequest": {
+        "models.LoginAttempts": {
             "type": "object",
             "properties": {
-                "Category": {
+                "Failures": {
+                    "type": "integer"
+                },
+                "Key": {
                     "type": "string"
                 },
-                "ProductName": {
+                "LastFailureAt": {
                     "type": "string"
                 },
-                "ProductQty": {
+                "LockedUntil": {
+                    "type": "string"
+                },
+                "UpdatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MovementLots": {
+            "type": "object",
+            "properties": {
+                "Delta": {
                     "type": "integer"
                 },
-                "Unit": {
+                "ExpiresAt": {
+                    "type": "string"
+                },
+                "LotID": {
+                    "type": "string"
+                },
+                "LotNumber": {
                     "type": "string"
                 }
             }
         },
-        "models.Categories": {
+        "models.NotificationChannel": {
             "type": "object",
             "properties": {
-                "CategoryID": {
+                "Type": {
                     "type": "string"
                 },
-                "CategoryName": {
+                "URL": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.NotificationOutbox": {
+            "type": "object",
+            "properties": {
+                "Attempts": {
+                    "type": "integer"
+                },
+                "Channel": {
+                    "$ref": "#/definitions/models.NotificationChannel"
+                },
+                "CreatedAt": {
                     "type": "string"
                 },
-                "Discription": {
+                "Data": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "Kind": {
+                    "type": "string"
+                },
+                "LastError": {
+                    "type": "string"
+                },
+                "Link": {
+                    "type": "string"
+                },
+                "Message": {
+                    "type": "string"
+                },
+                "NotBefore": {
+                    "type": "string"
+                },
+                "NotificationID": {
+                    "type": "string"
+                },
+                "SentAt": {
+                    "type": "string"
+                },
+                "StockID": {
+                    "type": "string"
+                },
+                "Title": {
                     "type": "string"
                 },
+                "UserID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.NotificationSettings": {
+            "type": "object",
+            "properties": {
+                "Channels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NotificationChannel"
+                    }
+                },
+                "QuietHours": {
+                    "$ref": "#/definitions/models.QuietHours"
+                },
                 "StockID": {
                     "type": "string"
+                },
+                "UpdatedAt": {
+                    "type": "string"
+                },
+                "UserID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PasswordResets": {
+            "type": "object",
+            "properties": {
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "ExpiresAt": {
+                    "type": "string"
+                },
+                "UsedAt": {
+                    "type": "string"
+                },
+                "UserID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProductLots": {
+            "type": "object",
+            "properties": {
+                "ExpiresAt": {
+                    "type": "string"
+                },
+                "ExpiryNotifiedAt": {
+                    "description": "ExpiryNotifiedAt is set once subscribers were told the lot is about\nto expire.",
+                    "type": "string"
+                },
+                "LotID": {
+                    "type": "string"
+                },
+                "LotNumber": {
+                    "type": "string"
+                },
+                "Quantity": {
+                    "type": "integer"
+                },
+                "ReceivedAt": {
+                    "type": "string"
                 }
             }
         },
         "models.Products": {
             "type": "object",
             "properties": {
+                "Barcodes": {
+                    "description": "Barcodes are the product's EAN/UPC codes and SKU its own article\nnumber; both are unique within the stock.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "Category": {
                     "type": "string"
                 },
+                "Lots": {
+                    "description": "Lots split part or all of ProductQty into batches with their own expiry\ndate; whatever ProductQty holds beyond them is untracked.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductLots"
+                    }
+                },
+                "LowStockNotifiedAt": {
+                    "description": "LowStockNotifiedAt is set when a low-stock notification went out and\ncleared once the quantity recovers, so each shortage is reported once.",
+                    "type": "string"
+                },
+                "MinQty": {
+                    "description": "MinQty is the reorder point: the product is low on stock once\nProductQty is at or below it. ReorderQty is how much to buy then.",
+                    "type": "integer"
+                },
                 "ProductID": {
                     "type": "string"
                 },
@@ -812,11 +6859,196 @@ const docTemplate = `{
                 "ProductQty": {
                     "type": "integer"
                 },
+                "ReorderQty": {
+                    "type": "integer"
+                },
+                "SKU": {
+                    "type": "string"
+                },
+                "StockID": {
+                    "type": "string"
+                },
+                "Unit": {
+                    "type": "string"
+                },
+                "UpdatedAt": {
+                    "description": "UpdatedAt is when the product last changed; products that have not\nchanged since it was introduced have none.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.QuietHours": {
+            "type": "object",
+            "properties": {
+                "End": {
+                    "type": "string"
+                },
+                "Start": {
+                    "type": "string"
+                },
+                "TimeZone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Sessions": {
+            "type": "object",
+            "properties": {
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "ExpiresAt": {
+                    "type": "string"
+                },
+                "IPAddress": {
+                    "type": "string"
+                },
+                "LastUsedAt": {
+                    "type": "string"
+                },
+                "RevokeReason": {
+                    "type": "string"
+                },
+                "RevokedAt": {
+                    "type": "string"
+                },
+                "SessionID": {
+                    "type": "string"
+                },
+                "UserAgent": {
+                    "type": "string"
+                },
+                "UserID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ShoppingListItems": {
+            "type": "object",
+            "properties": {
+                "Checked": {
+                    "type": "boolean"
+                },
+                "CheckedAt": {
+                    "type": "string"
+                },
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "ItemID": {
+                    "type": "string"
+                },
+                "Name": {
+                    "type": "string"
+                },
+                "ProductID": {
+                    "type": "string"
+                },
+                "Quantity": {
+                    "type": "integer"
+                },
+                "Source": {
+                    "type": "string"
+                },
                 "StockID": {
                     "type": "string"
                 },
                 "Unit": {
                     "type": "string"
+                },
+                "UserID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StockMovements": {
+            "type": "object",
+            "properties": {
+                "APIKeyID": {
+                    "type": "string"
+                },
+                "ActorID": {
+                    "type": "string"
+                },
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "Delta": {
+                    "type": "integer"
+                },
+                "Lots": {
+                    "description": "Lots lists how the movement changed the product's lots, if at all.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MovementLots"
+                    }
+                },
+                "MovementID": {
+                    "type": "string"
+                },
+                "Note": {
+                    "type": "string"
+                },
+                "ProductID": {
+                    "type": "string"
+                },
+                "QtyAfter": {
+                    "type": "integer"
+                },
+                "Reason": {
+                    "type": "string"
+                },
+                "StockID": {
+                    "type": "string"
+                },
+                "Type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StockShares": {
+            "type": "object",
+            "properties": {
+                "CreatedAt": {
+                    "type": "string"
+                },
+                "Role": {
+                    "type": "string"
+                },
+                "SharedBy": {
+                    "type": "string"
+                },
+                "StockID": {
+                    "type": "string"
+                },
+                "UserID": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserStatusChanges": {
+            "type": "object",
+            "properties": {
+                "ActorID": {
+                    "type": "string"
+                },
+                "ChangeID": {
+                    "type": "string"
+                },
+                "ChangedAt": {
+                    "type": "string"
+                },
+                "FromStatus": {
+                    "type": "string"
+                },
+                "Reason": {
+                    "type": "string"
+                },
+                "ToStatus": {
+                    "type": "string"
+                },
+                "UserID": {
+                    "type": "string"
                 }
             }
         },
@@ -826,15 +7058,35 @@ const docTemplate = `{
                 "AvatarURL": {
                     "type": "string"
                 },
+                "CreatedAt": {
+                    "type": "string"
+                },
                 "DisplayName": {
                     "type": "string"
                 },
                 "Email": {
                     "type": "string"
                 },
+                "EmailVerifiedAt": {
+                    "type": "string"
+                },
+                "OIDCIssuer": {
+                    "description": "Identity at the OpenID Connect provider the account is linked to.",
+                    "type": "string"
+                },
+                "OIDCSubject": {
+                    "type": "string"
+                },
+                "Role": {
+                    "type": "string"
+                },
                 "Status": {
                     "type": "string"
                 },
+                "TOTPEnabled": {
+                    "description": "Two-factor authentication. Secrets and recovery code hashes are never serialized.",
+                    "type": "boolean"
+                },
                 "UserId": {
                     "type": "string"
                 }
@@ -843,6 +7095,9 @@ const docTemplate = `{
         "models.Warehouse": {
             "type": "object",
             "properties": {
+                "HouseholdID": {
+                    "type": "string"
+                },
                 "StockID": {
                     "type": "string"
                 },
@@ -854,6 +7109,14 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "\"Bearer \u003cAccessToken\u003e\" or \"ApiKey \u003ckey\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
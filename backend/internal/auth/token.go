@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"my-backend/internal/config"
+)
+
+const (
+	defaultAccessTokenTTL   = 15 * time.Minute
+	defaultRefreshTokenTTL  = 30 * 24 * time.Hour
+	defaultPasswordResetTTL = time.Hour
+	defaultVerificationTTL  = 48 * time.Hour
+
+	defaultChallengeTTL = 5 * time.Minute
+
+	// TokenTypeAccess marks tokens that authorize regular API calls.
+	TokenTypeAccess = "access"
+	// TokenTypeTwoFactorChallenge marks tokens proving the password step of a
+	// two-factor login; they cannot be used as access tokens.
+	TokenTypeTwoFactorChallenge = "2fa_challenge"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// Claims is the payload carried by signed tokens.
+type Claims struct {
+	Subject   string `json:"sub"`
+	SessionID string `json:"sid,omitempty"`
+	Type      string `json:"typ"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+var encodedHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+func signingKey() ([]byte, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return nil, errors.New("JWT_SECRET is not set")
+	}
+	return []byte(secret), nil
+}
+
+// AccessTokenTTL returns how long issued access tokens stay valid.
+func AccessTokenTTL() time.Duration {
+	return config.Duration("ACCESS_TOKEN_TTL", defaultAccessTokenTTL)
+}
+
+// RefreshTokenTTL returns how long a session survives without being refreshed.
+func RefreshTokenTTL() time.Duration {
+	return config.Duration("REFRESH_TOKEN_TTL", defaultRefreshTokenTTL)
+}
+
+// PasswordResetTTL returns how long password reset tokens stay valid.
+func PasswordResetTTL() time.Duration {
+	return config.Duration("PASSWORD_RESET_TTL", defaultPasswordResetTTL)
+}
+
+// EmailVerificationTTL returns how long email verification links stay valid.
+func EmailVerificationTTL() time.Duration {
+	return config.Duration("EMAIL_VERIFICATION_TTL", defaultVerificationTTL)
+}
+
+// IssueAccessToken signs an access token (HS256 JWT) for the given user and session.
+func IssueAccessToken(userID, sessionID string) (string, time.Time, error) {
+	now := time.Now().UTC()
+	expiresAt := now.Add(AccessTokenTTL())
+
+	token, err := sign(Claims{
+		Subject:   userID,
+		SessionID: sessionID,
+		Type:      TokenTypeAccess,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// ParseAccessToken verifies the signature and expiry of an access token.
+func ParseAccessToken(token string) (*Claims, error) {
+	claims, err := parse(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Type != TokenTypeAccess || claims.SessionID == "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// IssueChallengeToken signs a short-lived token that lets the user finish a
+// two-factor login without sending the password again.
+func IssueChallengeToken(userID string) (string, time.Time, error) {
+	now := time.Now().UTC()
+	expiresAt := now.Add(defaultChallengeTTL)
+
+	token, err := sign(Claims{
+		Subject:   userID,
+		Type:      TokenTypeTwoFactorChallenge,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// ParseChallengeToken verifies a token issued by IssueChallengeToken.
+func ParseChallengeToken(token string) (*Claims, error) {
+	claims, err := parse(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Type != TokenTypeTwoFactorChallenge {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func sign(claims Claims) (string, error) {
+	key, err := signingKey()
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := encodedHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + signature(key, unsigned), nil
+}
+
+func parse(token string) (*Claims, error) {
+	key, err := signingKey()
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != encodedHeader {
+		return nil, ErrInvalidToken
+	}
+
+	expected := signature(key, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if claims.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+
+	return &claims, nil
+}
+
+func signature(key []byte, unsigned string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
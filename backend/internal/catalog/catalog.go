@@ -0,0 +1,108 @@
+// Package catalog maintains the offline product catalog that new products are
+// prefilled from. It is filled from Open Food Facts dumps and never talks to
+// the network.
+package catalog
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"my-backend/internal/db"
+	"my-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GTIN returns code padded to the 14 digits the catalog is keyed by. ok is
+// false unless code is 8 to 14 digits.
+func GTIN(code string) (gtin string, ok bool) {
+	code = strings.TrimSpace(code)
+	if len(code) < 8 || len(code) > 14 {
+		return "", false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	return strings.Repeat("0", 14-len(code)) + code, true
+}
+
+// Lookup returns the catalog entry for barcode, or nil when there is none.
+func Lookup(ctx context.Context, barcode string) (*models.CatalogProducts, error) {
+	gtin, ok := GTIN(barcode)
+	if !ok {
+		return nil, nil
+	}
+
+	collection, err := db.CatalogProductsCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var entry models.CatalogProducts
+	err = collection.FindOne(ctx, bson.M{"GTIN": gtin}).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// packageUnit matches the unit at the end of a package size such as "500 g",
+// "1.5L" or "6 x 33 cl".
+var packageUnit = regexp.MustCompile(`(?i)[\d.,]\s*([a-z]+(?: oz)?)\.?\s*$`)
+
+var knownUnits = map[string]string{
+	"g": "g", "gr": "g", "kg": "kg", "mg": "mg",
+	"ml": "ml", "cl": "cl", "dl": "dl", "l": "l", "lt": "l",
+	"oz": "oz", "fl oz": "fl oz", "lb": "lb", "lbs": "lb",
+}
+
+// unitOf returns the normalized unit of a package size, or "" when it has
+// none that is recognised.
+func unitOf(quantity string) string {
+	m := packageUnit.FindStringSubmatch(quantity)
+	if m == nil {
+		return ""
+	}
+	return knownUnits[strings.ToLower(m[1])]
+}
+
+// categoryName turns an Open Food Facts category, either a display name or a
+// tag like "en:sweet-snacks", into a display name.
+func categoryName(category string) string {
+	category = strings.TrimSpace(category)
+	if i := strings.Index(category, ":"); i == 2 {
+		category = strings.ReplaceAll(category[i+1:], "-", " ")
+		if category != "" {
+			category = strings.ToUpper(category[:1]) + category[1:]
+		}
+	}
+	return category
+}
+
+// lastItem returns the last entry of a comma-separated list, which for Open
+// Food Facts categories is the most specific one.
+func lastItem(list string) string {
+	items := strings.Split(list, ",")
+	for i := len(items) - 1; i >= 0; i-- {
+		if item := strings.TrimSpace(items[i]); item != "" {
+			return item
+		}
+	}
+	return ""
+}
+
+func firstItem(list string) string {
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			return item
+		}
+	}
+	return ""
+}
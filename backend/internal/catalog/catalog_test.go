@@ -0,0 +1,78 @@
+package catalog
+
+import "testing"
+
+func TestGTIN(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		want   string
+		wantOK bool
+	}{
+		{"EAN-8", "96385074", "00000096385074", true},
+		{"UPC-A", "036000291452", "00036000291452", true},
+		{"EAN-13", "3017620422003", "03017620422003", true},
+		{"GTIN-14", "10036000291459", "10036000291459", true},
+		{"surrounding spaces", " 036000291452\t", "00036000291452", true},
+		{"too short", "1234567", "", false},
+		{"too long", "100360002914590", "", false},
+		{"letters", "30176204220AB", "", false},
+		{"inner space", "3017620 422003", "", false},
+		{"empty", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := GTIN(tt.code)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("GTIN(%q) = %q, %v, want %q, %v", tt.code, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestUnitOf(t *testing.T) {
+	tests := []struct {
+		quantity string
+		want     string
+	}{
+		{"500 g", "g"},
+		{"500g", "g"},
+		{"1 kg", "kg"},
+		{"1.5L", "l"},
+		{"1,5 lt", "l"},
+		{"6 x 33 cl", "cl"},
+		{"330 ml.", "ml"},
+		{"12 fl oz", "fl oz"},
+		{"16 OZ", "oz"},
+		{"2 lbs", "lb"},
+		{"12 pieces", ""},
+		{"family pack", ""},
+		{"g", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := unitOf(tt.quantity); got != tt.want {
+			t.Errorf("unitOf(%q) = %q, want %q", tt.quantity, got, tt.want)
+		}
+	}
+}
+
+func TestCategoryName(t *testing.T) {
+	tests := []struct {
+		category string
+		want     string
+	}{
+		{"en:sweet-snacks", "Sweet snacks"},
+		{" fr:produits-laitiers ", "Produits laitiers"},
+		{"Dairies", "Dairies"},
+		{"Plant-based foods", "Plant-based foods"},
+		{"en:", ""},
+		{"http://example.com", "http://example.com"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := categoryName(tt.category); got != tt.want {
+			t.Errorf("categoryName(%q) = %q, want %q", tt.category, got, tt.want)
+		}
+	}
+}
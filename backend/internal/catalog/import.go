@@ -0,0 +1,261 @@
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Import formats.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+const importBatchSize = 1000
+
+// Stats counts what an import did with the records it read.
+type Stats struct {
+	Read     int
+	Imported int
+	// Skipped records had no usable barcode or name, or could not be parsed.
+	Skipped int
+}
+
+// offRecord holds the Open Food Facts fields the catalog uses. The names are
+// the same in the CSV export and the JSONL dump.
+type offRecord struct {
+	Code           string `json:"code"`
+	ProductName    string `json:"product_name"`
+	ProductNameEN  string `json:"product_name_en"`
+	GenericName    string `json:"generic_name"`
+	Brands         string `json:"brands"`
+	Categories     string `json:"categories"`
+	MainCategoryEN string `json:"main_category_en"`
+	Quantity       string `json:"quantity"`
+}
+
+// Import reads an Open Food Facts export in format (FormatCSV, tab- or
+// comma-separated with a header row, or FormatJSONL) and upserts every
+// product with a barcode and a name into the catalog. Entries already in the
+// catalog are replaced. progress, when not nil, is called after each batch.
+func Import(ctx context.Context, r io.Reader, format string, progress func(Stats)) (Stats, error) {
+	collection, err := db.CatalogProductsCollection(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	imp := importer{ctx: ctx, collection: collection, progress: progress, now: time.Now().UTC()}
+	switch format {
+	case FormatCSV:
+		err = imp.readCSV(r)
+	case FormatJSONL:
+		err = imp.readJSONL(r)
+	default:
+		return Stats{}, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return imp.stats, err
+	}
+	return imp.stats, imp.flush()
+}
+
+type importer struct {
+	ctx        context.Context
+	collection *mongo.Collection
+	progress   func(Stats)
+	now        time.Time
+	stats      Stats
+	batch      map[string]models.CatalogProducts
+}
+
+func (imp *importer) readCSV(r io.Reader) error {
+	br := bufio.NewReaderSize(r, 1<<20)
+	reader := csv.NewReader(br)
+	reader.Comma = detectDelimiter(br)
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	if _, ok := columns["code"]; !ok {
+		return errors.New(`header has no "code" column`)
+	}
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			imp.stats.Read++
+			imp.stats.Skipped++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := imp.add(offRecord{
+			Code:           field(row, "code"),
+			ProductName:    field(row, "product_name"),
+			ProductNameEN:  field(row, "product_name_en"),
+			GenericName:    field(row, "generic_name"),
+			Brands:         field(row, "brands"),
+			Categories:     field(row, "categories"),
+			MainCategoryEN: field(row, "main_category_en"),
+			Quantity:       field(row, "quantity"),
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// detectDelimiter picks tab or comma by which comes first in the header; the
+// official Open Food Facts CSV export is tab-separated.
+func detectDelimiter(br *bufio.Reader) rune {
+	head, _ := br.Peek(64 << 10)
+	if i := bytes.IndexByte(head, '\n'); i >= 0 {
+		head = head[:i]
+	}
+	if bytes.IndexByte(head, '\t') >= 0 {
+		return '\t'
+	}
+	return ','
+}
+
+func (imp *importer) readJSONL(r io.Reader) error {
+	br := bufio.NewReaderSize(r, 1<<20)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var rec offRecord
+			// A field of an unexpected type only loses that field; anything
+			// else makes the line unusable.
+			var typeErr *json.UnmarshalTypeError
+			if jsonErr := json.Unmarshal(line, &rec); jsonErr != nil && !errors.As(jsonErr, &typeErr) {
+				imp.stats.Read++
+				imp.stats.Skipped++
+			} else if err := imp.add(rec); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// add converts rec into a catalog entry and queues it, writing the batch once
+// it is full.
+func (imp *importer) add(rec offRecord) error {
+	imp.stats.Read++
+
+	entry, ok := entryFrom(rec, imp.now)
+	if !ok {
+		imp.stats.Skipped++
+		return nil
+	}
+	if imp.batch == nil {
+		imp.batch = make(map[string]models.CatalogProducts, importBatchSize)
+	}
+	imp.batch[entry.GTIN] = entry
+
+	if len(imp.batch) >= importBatchSize {
+		return imp.flush()
+	}
+	return nil
+}
+
+func (imp *importer) flush() error {
+	if len(imp.batch) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(imp.batch))
+	for gtin, entry := range imp.batch {
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"GTIN": gtin}).
+			SetReplacement(entry).
+			SetUpsert(true))
+	}
+	res, err := imp.collection.BulkWrite(imp.ctx, writes, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return err
+	}
+	imp.stats.Imported += int(res.MatchedCount + res.UpsertedCount)
+	imp.batch = nil
+
+	if imp.progress != nil {
+		imp.progress(imp.stats)
+	}
+	return nil
+}
+
+// entryFrom maps an Open Food Facts record to a catalog entry. ok is false
+// for records without a valid barcode or any name.
+func entryFrom(rec offRecord, now time.Time) (models.CatalogProducts, bool) {
+	code := strings.TrimSpace(rec.Code)
+	gtin, ok := GTIN(code)
+	if !ok {
+		return models.CatalogProducts{}, false
+	}
+
+	name := strings.TrimSpace(rec.ProductName)
+	for _, fallback := range []string{rec.ProductNameEN, rec.GenericName} {
+		if name == "" {
+			name = strings.TrimSpace(fallback)
+		}
+	}
+	if name == "" {
+		return models.CatalogProducts{}, false
+	}
+
+	category := strings.TrimSpace(rec.MainCategoryEN)
+	if category == "" {
+		category = lastItem(rec.Categories)
+	}
+
+	quantity := strings.TrimSpace(rec.Quantity)
+	return models.CatalogProducts{
+		GTIN:        gtin,
+		Barcode:     code,
+		ProductName: name,
+		Brand:       firstItem(rec.Brands),
+		Category:    categoryName(category),
+		Unit:        unitOf(quantity),
+		Quantity:    quantity,
+		Source:      models.CatalogSourceOpenFoodFacts,
+		ImportedAt:  now,
+	}, true
+}
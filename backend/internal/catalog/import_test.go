@@ -0,0 +1,190 @@
+package catalog
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+
+	"my-backend/internal/models"
+)
+
+var importedAt = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestEntryFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		rec    offRecord
+		want   models.CatalogProducts
+		wantOK bool
+	}{
+		{
+			name: "full record",
+			rec: offRecord{
+				Code: "3017620422003", ProductName: " Nutella ", Brands: "Ferrero, Nutella",
+				Categories: "en:breakfasts,en:spreads", MainCategoryEN: "en:sweet-spreads", Quantity: " 400 g ",
+			},
+			want: models.CatalogProducts{
+				GTIN: "03017620422003", Barcode: "3017620422003", ProductName: "Nutella", Brand: "Ferrero",
+				Category: "Sweet spreads", Unit: "g", Quantity: "400 g",
+			},
+			wantOK: true,
+		},
+		{
+			name:   "padded UPC-A",
+			rec:    offRecord{Code: " 036000291452 ", ProductName: "Soda", Quantity: "6 x 33 cl"},
+			want:   models.CatalogProducts{GTIN: "00036000291452", Barcode: "036000291452", ProductName: "Soda", Unit: "cl", Quantity: "6 x 33 cl"},
+			wantOK: true,
+		},
+		{
+			name:   "English name and most specific category",
+			rec:    offRecord{Code: "96385074", ProductNameEN: "Mints", Categories: "en:sweets, en:breath-mints, "},
+			want:   models.CatalogProducts{GTIN: "00000096385074", Barcode: "96385074", ProductName: "Mints", Category: "Breath mints"},
+			wantOK: true,
+		},
+		{
+			name:   "generic name",
+			rec:    offRecord{Code: "5901234123457", GenericName: "Still water"},
+			want:   models.CatalogProducts{GTIN: "05901234123457", Barcode: "5901234123457", ProductName: "Still water"},
+			wantOK: true,
+		},
+		{name: "no name", rec: offRecord{Code: "5901234123457", ProductName: "  "}},
+		{name: "no barcode", rec: offRecord{ProductName: "Loose apples"}},
+		{name: "invalid barcode", rec: offRecord{Code: "apple-123", ProductName: "Apples"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := entryFrom(tt.rec, importedAt)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			tt.want.Source = models.CatalogSourceOpenFoodFacts
+			tt.want.ImportedAt = importedAt
+			if got != tt.want {
+				t.Errorf("entry\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectDelimiter(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  rune
+	}{
+		{"tab-separated", "code\tproduct_name\n1\tx\n", '\t'},
+		{"comma-separated", "code,product_name\n1,x\n", ','},
+		{"tab only after the header", "code,product_name\n1,a\tb\n", ','},
+		{"single column", "code\n1\n", ','},
+		{"header without newline", "code\tproduct_name", '\t'},
+		{"empty", "", ','},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectDelimiter(bufio.NewReader(strings.NewReader(tt.input))); got != tt.want {
+				t.Errorf("delimiter %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// entry is what the readers should have queued for one barcode.
+type entry struct {
+	gtin, name, category, unit string
+}
+
+func TestReaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		want   []entry
+		stats  Stats
+	}{
+		{
+			name:   "TSV with a BOM",
+			format: FormatCSV,
+			input: "\ufeffcode\tproduct_name\tbrands\tcategories\tmain_category_en\tquantity\n" +
+				"3017620422003\tNutella\tFerrero\ten:breakfasts,en:spreads\t\t400 g\n" +
+				"not-a-barcode\tBroken\t\t\t\t\n" +
+				"036000291452\tSoda\tAcme\t\ten:carbonated-drinks\t6 x 33 cl\n",
+			want: []entry{
+				{"03017620422003", "Nutella", "Spreads", "g"},
+				{"00036000291452", "Soda", "Carbonated drinks", "cl"},
+			},
+			stats: Stats{Read: 3, Skipped: 1},
+		},
+		{
+			name:   "CSV with quoted fields and short rows",
+			format: FormatCSV,
+			input: "\ufeffquantity,code,product_name\r\n" +
+				"\"6 x 33 cl\",036000291452,\"Soda, lemon\"\r\n" +
+				"500 g,96385074\r\n" +
+				"1 l,5901234123457,Milk\r\n",
+			want: []entry{
+				{"00036000291452", "Soda, lemon", "", "cl"},
+				{"05901234123457", "Milk", "", "l"},
+			},
+			stats: Stats{Read: 3, Skipped: 1},
+		},
+		{
+			name:   "JSONL",
+			format: FormatJSONL,
+			input: `{"code":"3017620422003","product_name":"Nutella","quantity":"400 g"}` + "\n" +
+				`{"code":"96385074","product_name":` + "\n" +
+				"\n" +
+				`{"code":"036000291452","product_name_en":"Soda","quantity":330}` + "\n" +
+				`{"code":"5901234123457","generic_name":"Water","categories":"en:waters, en:spring-waters"}`,
+			want: []entry{
+				{"03017620422003", "Nutella", "", "g"},
+				{"00036000291452", "Soda", "", ""},
+				{"05901234123457", "Water", "Spring waters", ""},
+			},
+			stats: Stats{Read: 4, Skipped: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imp := importer{now: importedAt}
+			var err error
+			switch tt.format {
+			case FormatCSV:
+				err = imp.readCSV(strings.NewReader(tt.input))
+			case FormatJSONL:
+				err = imp.readJSONL(strings.NewReader(tt.input))
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if imp.stats != tt.stats {
+				t.Errorf("stats %+v, want %+v", imp.stats, tt.stats)
+			}
+			if len(imp.batch) != len(tt.want) {
+				t.Errorf("%d entries queued, want %d", len(imp.batch), len(tt.want))
+			}
+			for _, want := range tt.want {
+				got, ok := imp.batch[want.gtin]
+				if !ok {
+					t.Errorf("no entry for %s", want.gtin)
+					continue
+				}
+				if got.ProductName != want.name || got.Category != want.category || got.Unit != want.unit {
+					t.Errorf("entry for %s: %q, %q, %q, want %q, %q, %q",
+						want.gtin, got.ProductName, got.Category, got.Unit, want.name, want.category, want.unit)
+				}
+			}
+		})
+	}
+}
+
+func TestReadCSVNeedsACodeColumn(t *testing.T) {
+	imp := importer{now: importedAt}
+	if err := imp.readCSV(strings.NewReader("barcode,product_name\n3017620422003,Nutella\n")); err == nil {
+		t.Error("a header without a code column was accepted")
+	}
+}
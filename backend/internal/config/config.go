@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration reads a Go duration (e.g. "15m") from the environment, returning
+// fallback when the variable is unset or invalid.
+func Duration(name string, fallback time.Duration) time.Duration {
+	if raw := os.Getenv(name); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			return d
+		}
+	}
+	return fallback
+}
+
+// Int reads a positive integer from the environment, returning fallback when
+// the variable is unset or invalid.
+func Int(name string, fallback int) int {
+	if raw := os.Getenv(name); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			return n
+		}
+	}
+	return fallback
+}
+
+// List reads a comma-separated list from the environment, dropping blank
+// entries. It is nil when the variable is unset.
+func List(name string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -3,7 +3,9 @@ package db
 import (
 	"context"
 	"errors"
+	"log"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,6 +20,66 @@ var (
 	clientErr      error
 	usersSetupOnce sync.Once
 	usersSetupErr  error
+
+	usersMigrateOnce sync.Once
+	usersMigrateErr  error
+
+	sessionsSetupOnce sync.Once
+	sessionsSetupErr  error
+
+	statusChangesSetupOnce sync.Once
+	statusChangesSetupErr  error
+
+	passwordResetsSetupOnce sync.Once
+	passwordResetsSetupErr  error
+
+	emailVerificationsSetupOnce sync.Once
+	emailVerificationsSetupErr  error
+
+	loginAttemptsSetupOnce sync.Once
+	loginAttemptsSetupErr  error
+
+	apiKeysSetupOnce sync.Once
+	apiKeysSetupErr  error
+
+	oidcStatesSetupOnce sync.Once
+	oidcStatesSetupErr  error
+
+	householdsSetupOnce sync.Once
+	householdsSetupErr  error
+
+	householdMembersSetupOnce sync.Once
+	householdMembersSetupErr  error
+
+	householdInvitesSetupOnce sync.Once
+	householdInvitesSetupErr  error
+
+	stockSharesSetupOnce sync.Once
+	stockSharesSetupErr  error
+
+	auditLogSetupOnce sync.Once
+	auditLogSetupErr  error
+
+	stockMovementsSetupOnce sync.Once
+	stockMovementsSetupErr  error
+
+	notificationSettingsSetupOnce sync.Once
+	notificationSettingsSetupErr  error
+
+	notificationOutboxSetupOnce sync.Once
+	notificationOutboxSetupErr  error
+
+	shoppingListSetupOnce sync.Once
+	shoppingListSetupErr  error
+
+	productsSetupOnce sync.Once
+	productsSetupErr  error
+
+	productsMigrateOnce sync.Once
+	productsMigrateErr  error
+
+	catalogProductsSetupOnce sync.Once
+	catalogProductsSetupErr  error
 )
 
 const defaultDBName = "event_hub"
@@ -73,41 +135,365 @@ func Client(ctx context.Context) (*mongo.Client, error) {
 
 // UsersCollection returns the users collection, creating it and ensuring indexes if missing.
 func UsersCollection(ctx context.Context) (*mongo.Collection, error) {
+	collection, err := indexedCollection(ctx, "users", &usersSetupOnce, &usersSetupErr,
+		// Field names are PascalCase like the rest of the document; the old
+		// "email_unique" index targeted a field that is never written and is
+		// dropped below.
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "Email", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("Email_unique"),
+		},
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "OIDCIssuer", Value: 1}, {Key: "OIDCSubject", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("oidc_identity_unique").
+				SetPartialFilterExpression(bson.M{"OIDCSubject": bson.M{"$exists": true}}),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	usersMigrateOnce.Do(func() {
+		migrateCtx, cancel := mongoTimeoutContext(ctx)
+		defer cancel()
+		if usersMigrateErr = dropIndexIfExists(migrateCtx, collection, "email_unique"); usersMigrateErr != nil {
+			return
+		}
+		usersMigrateErr = lowercaseEmails(migrateCtx, collection)
+	})
+	if usersMigrateErr != nil {
+		return nil, usersMigrateErr
+	}
+
+	return collection, nil
+}
+
+// dropIndexIfExists drops the named index, doing nothing when the collection
+// does not have it.
+func dropIndexIfExists(ctx context.Context, collection *mongo.Collection, name string) error {
+	_, err := collection.Indexes().DropOne(ctx, name)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && (cmdErr.Code == 27 || cmdErr.Name == "IndexNotFound") {
+		return nil
+	}
+	return err
+}
+
+// lowercaseEmails rewrites addresses stored before emails were lowercased, so
+// that lookups by the lowercased form find them. An address whose lowercase
+// form already belongs to another account is left as it is.
+func lowercaseEmails(ctx context.Context, collection *mongo.Collection) error {
+	cursor, err := collection.Find(ctx, bson.M{"Email": bson.M{"$regex": "[A-Z]"}},
+		options.Find().SetProjection(bson.M{"Email": 1}))
+	if err != nil {
+		return err
+	}
+	var users []struct {
+		ID    any    `bson:"_id"`
+		Email string `bson:"Email"`
+	}
+	if err := cursor.All(ctx, &users); err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		_, err := collection.UpdateByID(ctx, user.ID, bson.M{"$set": bson.M{"Email": strings.ToLower(user.Email)}})
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+		if err != nil {
+			log.Printf("users: not lowercasing %s, the lowercase address belongs to another account", user.Email)
+		}
+	}
+	return nil
+}
+
+// SessionsCollection returns the sessions collection, creating it and ensuring indexes if missing.
+// Expired sessions are removed by a TTL index on ExpiresAt.
+func SessionsCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "sessions", &sessionsSetupOnce, &sessionsSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "RefreshTokenHash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("refresh_token_hash_unique"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "PreviousTokenHashes", Value: 1}},
+			Options: options.Index().SetName("previous_token_hashes"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "UserID", Value: 1}},
+			Options: options.Index().SetName("user_id"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "ExpiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("expires_at_ttl"),
+		},
+	)
+}
+
+// UserStatusChangesCollection returns the user status audit collection, creating it and ensuring indexes if missing.
+func UserStatusChangesCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "user_status_changes", &statusChangesSetupOnce, &statusChangesSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "UserID", Value: 1}, {Key: "ChangedAt", Value: -1}},
+			Options: options.Index().SetName("user_id_changed_at"),
+		},
+	)
+}
+
+// PasswordResetsCollection returns the password reset token collection, creating it and ensuring indexes if missing.
+func PasswordResetsCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "password_resets", &passwordResetsSetupOnce, &passwordResetsSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "TokenHash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("token_hash_unique"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "UserID", Value: 1}},
+			Options: options.Index().SetName("user_id"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "ExpiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("expires_at_ttl"),
+		},
+	)
+}
+
+// EmailVerificationsCollection returns the email verification token collection, creating it and ensuring indexes if missing.
+func EmailVerificationsCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "email_verifications", &emailVerificationsSetupOnce, &emailVerificationsSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "TokenHash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("token_hash_unique"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "UserID", Value: 1}},
+			Options: options.Index().SetName("user_id"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "ExpiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("expires_at_ttl"),
+		},
+	)
+}
+
+// LoginAttemptsCollection returns the failed login tracking collection, creating it and ensuring indexes if missing.
+// Counters are forgotten a day after the last failure.
+func LoginAttemptsCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "login_attempts", &loginAttemptsSetupOnce, &loginAttemptsSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "Key", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("key_unique"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "UpdatedAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32((24 * time.Hour).Seconds())).SetName("updated_at_ttl"),
+		},
+	)
+}
+
+// APIKeysCollection returns the API key collection, creating it and ensuring indexes if missing.
+func APIKeysCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "api_keys", &apiKeysSetupOnce, &apiKeysSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "KeyHash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("key_hash_unique"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "UserID", Value: 1}},
+			Options: options.Index().SetName("user_id"),
+		},
+	)
+}
+
+// OIDCStatesCollection returns the pending OpenID Connect login collection, creating it and ensuring indexes if missing.
+func OIDCStatesCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "oidc_states", &oidcStatesSetupOnce, &oidcStatesSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "StateHash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("state_hash_unique"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "ExpiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("expires_at_ttl"),
+		},
+	)
+}
+
+// HouseholdsCollection returns the households collection, creating it and ensuring indexes if missing.
+func HouseholdsCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "households", &householdsSetupOnce, &householdsSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "HouseholdID", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("household_id_unique"),
+		},
+	)
+}
+
+// HouseholdMembersCollection returns the household membership collection, creating it and ensuring indexes if missing.
+func HouseholdMembersCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "household_members", &householdMembersSetupOnce, &householdMembersSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "HouseholdID", Value: 1}, {Key: "UserID", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("household_user_unique"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "UserID", Value: 1}},
+			Options: options.Index().SetName("user_id"),
+		},
+	)
+}
+
+// HouseholdInvitesCollection returns the household invitation collection, creating it and ensuring indexes if missing.
+// Expired invitations are removed by a TTL index on ExpiresAt.
+func HouseholdInvitesCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "household_invites", &householdInvitesSetupOnce, &householdInvitesSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "CodeHash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("code_hash_unique"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "HouseholdID", Value: 1}},
+			Options: options.Index().SetName("household_id"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "ExpiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0).SetName("expires_at_ttl"),
+		},
+	)
+}
+
+// StockSharesCollection returns the per-stock sharing collection, creating it and ensuring indexes if missing.
+func StockSharesCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "stock_shares", &stockSharesSetupOnce, &stockSharesSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "StockID", Value: 1}, {Key: "UserID", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("stock_user_unique"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "UserID", Value: 1}},
+			Options: options.Index().SetName("user_id"),
+		},
+	)
+}
+
+// AuditLogCollection returns the append-only audit log collection, creating it and ensuring indexes if missing.
+func AuditLogCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "audit_log", &auditLogSetupOnce, &auditLogSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "StockID", Value: 1}, {Key: "CreatedAt", Value: -1}},
+			Options: options.Index().SetName("stock_created_at"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "ActorID", Value: 1}, {Key: "CreatedAt", Value: -1}},
+			Options: options.Index().SetName("actor_created_at"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "EntityType", Value: 1}, {Key: "EntityID", Value: 1}, {Key: "CreatedAt", Value: -1}},
+			Options: options.Index().SetName("entity_created_at"),
+		},
+	)
+}
+
+// StockMovementsCollection returns the quantity ledger collection, creating it and ensuring indexes if missing.
+func StockMovementsCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "stock_movements", &stockMovementsSetupOnce, &stockMovementsSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "ProductID", Value: 1}, {Key: "CreatedAt", Value: -1}},
+			Options: options.Index().SetName("product_created_at"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "StockID", Value: 1}, {Key: "CreatedAt", Value: -1}},
+			Options: options.Index().SetName("stock_created_at"),
+		},
+	)
+}
+
+// NotificationSettingsCollection returns the per-stock notification settings collection, creating it and ensuring indexes if missing.
+func NotificationSettingsCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "notification_settings", &notificationSettingsSetupOnce, &notificationSettingsSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "StockID", Value: 1}, {Key: "UserID", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("stock_user_unique"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "UserID", Value: 1}},
+			Options: options.Index().SetName("user_id"),
+		},
+	)
+}
+
+// NotificationOutboxCollection returns the pending notification collection, creating it and ensuring indexes if missing.
+// Entries expire 30 days after they were queued, delivered or not.
+func NotificationOutboxCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "notification_outbox", &notificationOutboxSetupOnce, &notificationOutboxSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "SentAt", Value: 1}, {Key: "NotBefore", Value: 1}},
+			Options: options.Index().SetName("pending"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "UserID", Value: 1}},
+			Options: options.Index().SetName("user_id"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "CreatedAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(30 * 24 * 60 * 60).SetName("created_at_ttl"),
+		},
+	)
+}
+
+// ShoppingListCollection returns the shopping list item collection, creating it and ensuring indexes if missing.
+// A product appears at most once on each user's list.
+func ShoppingListCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "shopping_list", &shoppingListSetupOnce, &shoppingListSetupErr,
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "UserID", Value: 1}, {Key: "ProductID", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("user_product_unique").
+				SetPartialFilterExpression(bson.M{"ProductID": bson.M{"$exists": true}}),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "StockID", Value: 1}},
+			Options: options.Index().SetName("stock_id"),
+		},
+	)
+}
+
+// indexedCollection returns the named collection, creating it and its indexes
+// the first time it is requested.
+func indexedCollection(ctx context.Context, name string, once *sync.Once, setupErr *error, indexes ...mongo.IndexModel) (*mongo.Collection, error) {
 	c, err := Client(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	database := c.Database(dbName())
-	usersSetupOnce.Do(func() {
+	once.Do(func() {
 		setupCtx, cancel := mongoTimeoutContext(ctx)
 		defer cancel()
 
-		names, err := database.ListCollectionNames(setupCtx, bson.D{{Key: "name", Value: "users"}})
+		names, err := database.ListCollectionNames(setupCtx, bson.D{{Key: "name", Value: name}})
 		if err != nil {
-			usersSetupErr = err
+			*setupErr = err
 			return
 		}
 
 		if len(names) == 0 {
-			usersSetupErr = database.CreateCollection(setupCtx, "users")
-			if usersSetupErr != nil {
+			*setupErr = database.CreateCollection(setupCtx, name)
+			if *setupErr != nil {
 				return
 			}
 		}
 
-		indexModel := mongo.IndexModel{
-			Keys:    bson.D{{Key: "email", Value: 1}},
-			Options: options.Index().SetUnique(true).SetName("email_unique"),
+		if len(indexes) > 0 {
+			_, *setupErr = database.Collection(name).Indexes().CreateMany(setupCtx, indexes)
 		}
-		_, usersSetupErr = database.Collection("users").Indexes().CreateOne(setupCtx, indexModel)
 	})
 
-	if usersSetupErr != nil {
-		return nil, usersSetupErr
+	if *setupErr != nil {
+		return nil, *setupErr
 	}
 
-	return database.Collection("users"), nil
+	return database.Collection(name), nil
 }
 
 // EventsCollection returns the events collection, creating it if missing.
@@ -132,26 +518,104 @@ func EventsCollection(ctx context.Context) (*mongo.Collection, error) {
 	return database.Collection("events"), nil
 }
 
-// ProductsCollection returns the products collection, creating it if missing.
+// ProductsCollection returns the products collection, creating it and ensuring indexes if missing.
+// Barcodes (by their GTIN-14 form) and SKUs are unique within a stock; the other indexes back the sort orders of the product list.
 func ProductsCollection(ctx context.Context) (*mongo.Collection, error) {
-	c, err := Client(ctx)
+	collection, err := indexedCollection(ctx, "products", &productsSetupOnce, &productsSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "StockID", Value: 1}, {Key: "ProductName", Value: 1}, {Key: "ProductID", Value: 1}},
+			Options: options.Index().SetName("stock_name"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "StockID", Value: 1}, {Key: "ProductQty", Value: 1}, {Key: "ProductID", Value: 1}},
+			Options: options.Index().SetName("stock_qty"),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "StockID", Value: 1}, {Key: "UpdatedAt", Value: 1}, {Key: "ProductID", Value: 1}},
+			Options: options.Index().SetName("stock_updated"),
+		},
+		// The old "stock_barcode_unique" index compared barcodes as entered
+		// and is dropped below.
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "StockID", Value: 1}, {Key: "GTINs", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("stock_gtin_unique").
+				SetPartialFilterExpression(bson.M{"GTINs": bson.M{"$exists": true}}),
+		},
+		mongo.IndexModel{
+			Keys: bson.D{{Key: "StockID", Value: 1}, {Key: "SKU", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("stock_sku_unique").
+				SetPartialFilterExpression(bson.M{"SKU": bson.M{"$exists": true}}),
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	database := c.Database(dbName())
+	productsMigrateOnce.Do(func() {
+		migrateCtx, cancel := mongoTimeoutContext(ctx)
+		defer cancel()
+		if productsMigrateErr = dropIndexIfExists(migrateCtx, collection, "stock_barcode_unique"); productsMigrateErr != nil {
+			return
+		}
+		productsMigrateErr = backfillGTINs(migrateCtx, collection)
+	})
+	if productsMigrateErr != nil {
+		return nil, productsMigrateErr
+	}
+
+	return collection, nil
+}
 
-	names, err := database.ListCollectionNames(ctx, bson.D{{Key: "name", Value: "products"}})
+// backfillGTINs pads the barcodes of products stored before GTINs existed to
+// GTIN-14. A product whose padded codes already belong to another product of
+// its stock is left without them and logged.
+func backfillGTINs(ctx context.Context, collection *mongo.Collection) error {
+	cursor, err := collection.Find(ctx,
+		bson.M{"Barcodes": bson.M{"$exists": true}, "GTINs": bson.M{"$exists": false}},
+		options.Find().SetProjection(bson.M{"ProductID": 1, "Barcodes": 1}))
 	if err != nil {
-		return nil, err
+		return err
 	}
-	if len(names) == 0 {
-		if err := database.CreateCollection(ctx, "products"); err != nil {
-			return nil, err
+	var products []struct {
+		ID        any      `bson:"_id"`
+		ProductID any      `bson:"ProductID"`
+		Barcodes  []string `bson:"Barcodes"`
+	}
+	if err := cursor.All(ctx, &products); err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		gtins := make([]string, 0, len(product.Barcodes))
+		seen := make(map[string]bool, len(product.Barcodes))
+		for _, code := range product.Barcodes {
+			if len(code) < 14 {
+				code = strings.Repeat("0", 14-len(code)) + code
+			}
+			if !seen[code] {
+				seen[code] = true
+				gtins = append(gtins, code)
+			}
+		}
+		_, err := collection.UpdateByID(ctx, product.ID, bson.M{"$set": bson.M{"GTINs": gtins}})
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+		if err != nil {
+			log.Printf("products: not indexing the barcodes of %v, another product in its stock has the same codes", product.ProductID)
 		}
 	}
+	return nil
+}
 
-	return database.Collection("products"), nil
+// CatalogProductsCollection returns the offline product catalog collection, creating it and ensuring indexes if missing.
+func CatalogProductsCollection(ctx context.Context) (*mongo.Collection, error) {
+	return indexedCollection(ctx, "catalog_products", &catalogProductsSetupOnce, &catalogProductsSetupErr,
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "GTIN", Value: 1}},
+			Options: options.Index().SetUnique(true).SetName("gtin_unique"),
+		},
+	)
 }
 
 // WarehouseCollection returns the warehouse collection, creating it if missing.
@@ -0,0 +1,693 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// collectionFunc matches the db.XCollection accessors.
+type collectionFunc func(ctx context.Context) (*mongo.Collection, error)
+
+type deleteAccountRequest struct {
+	Password     string `json:"Password"`
+	Code         string `json:"Code"`
+	RecoveryCode string `json:"RecoveryCode"`
+}
+
+// accountExport is everything stored about a user. Secrets such as password
+// and token hashes are excluded by the models' json tags.
+type accountExport struct {
+	ExportedAt         time.Time                     `json:"ExportedAt"`
+	User               models.Users                  `json:"User"`
+	Warehouse          []models.Warehouse            `json:"Warehouse"`
+	Products           []models.Products             `json:"Products"`
+	Categories         []models.Categories           `json:"Categories"`
+	Movements          []models.StockMovements       `json:"Movements"`
+	Events             []models.Events               `json:"Events"`
+	Sessions           []models.Sessions             `json:"Sessions"`
+	APIKeys            []models.APIKeys              `json:"APIKeys"`
+	StatusHistory      []models.UserStatusChanges    `json:"StatusHistory"`
+	PasswordResets     []models.PasswordResets       `json:"PasswordResets"`
+	EmailVerifications []models.EmailVerifications   `json:"EmailVerifications"`
+	LoginAttempts      []models.LoginAttempts        `json:"LoginAttempts"`
+	Households         []models.HouseholdMembers     `json:"Households"`
+	CreatedHouseholds  []models.Households           `json:"CreatedHouseholds"`
+	HouseholdInvites   []models.HouseholdInvites     `json:"HouseholdInvites"`
+	SharedWithMe       []models.StockShares          `json:"SharedWithMe"`
+	SharedByMe         []models.StockShares          `json:"SharedByMe"`
+	Notifications      []models.NotificationSettings `json:"Notifications"`
+	NotificationOutbox []models.NotificationOutbox   `json:"NotificationOutbox"`
+	ShoppingList       []models.ShoppingListItems    `json:"ShoppingList"`
+	AuditLog           []models.AuditLog             `json:"AuditLog"`
+}
+
+// DeleteAccount godoc
+// @Summary      Delete current user
+// @Description  Permanently deletes the authenticated user together with every stock they own, the products and categories in those stocks, their events, sessions, API keys and pending tokens, and leaves every household and stock share. The audit log is append-only: its entries are kept with the user's ID and email, also inside snapshots, replaced by a random pseudonym. Movements they recorded in other users' stocks are kept under the same pseudonym. Requires the password (or, for accounts without one, a sign-in within the last 10 minutes), plus a code or recovery code when two-factor login is enabled.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        payload  body      deleteAccountRequest  true  "Password and, with 2FA, a second factor"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/me [delete]
+func DeleteAccount(c *fiber.Ctx) error {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+	}
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req deleteAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	// Cascading through large inventories can take longer than a single request.
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	if err := confirmIdentity(ctx, c, user, req.Password); err != nil {
+		return err
+	}
+
+	if user.TOTPEnabled {
+		valid, err := verifySecondFactor(ctx, user, req.Code, req.RecoveryCode)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to verify code")
+		}
+		if !valid {
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid code")
+		}
+	}
+
+	usersCol, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	if user.EffectiveRole() == models.RoleAdmin {
+		admins, err := usersCol.CountDocuments(ctx, bson.M{"Role": models.RoleAdmin, "UserId": bson.M{"$ne": user.UserID}})
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to count admins")
+		}
+		if admins == 0 {
+			return fiber.NewError(fiber.StatusConflict, "the last admin cannot delete their account")
+		}
+	}
+
+	if err := leaveAllHouseholds(ctx, c, userUUID); err != nil {
+		return err
+	}
+
+	stockIDs, err := ownedStockIDs(ctx, userUUID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch owned stocks")
+	}
+	deletedIDs, err := deletedStockIDs(ctx, userUUID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch deleted stocks")
+	}
+	ledgerStockIDs := append(append(bson.A{}, stockIDs...), deletedIDs...)
+
+	// Inventory goes first and the user document last, so a failure part-way
+	// leaves an account that can simply retry the deletion.
+	steps := []struct {
+		key        string
+		collection collectionFunc
+		filter     bson.M
+	}{
+		{"deleted_products", db.ProductsCollection, bson.M{"StockID": bson.M{"$in": stockIDs}}},
+		{"deleted_categories", db.CategoriesCollection, bson.M{"StockID": bson.M{"$in": stockIDs}}},
+		{"deleted_movements", db.StockMovementsCollection, bson.M{"StockID": bson.M{"$in": ledgerStockIDs}}},
+		{"deleted_notification_settings", db.NotificationSettingsCollection, bson.M{"$or": bson.A{
+			bson.M{"StockID": bson.M{"$in": stockIDs}},
+			bson.M{"UserID": userUUID},
+		}}},
+		{"deleted_notifications", db.NotificationOutboxCollection, bson.M{"$or": bson.A{
+			bson.M{"StockID": bson.M{"$in": stockIDs}},
+			bson.M{"UserID": userUUID},
+		}}},
+		{"deleted_shopping_list_items", db.ShoppingListCollection, bson.M{"$or": bson.A{
+			bson.M{"StockID": bson.M{"$in": stockIDs}},
+			bson.M{"UserID": userUUID},
+		}}},
+		{"deleted_stock_shares", db.StockSharesCollection, bson.M{"$or": bson.A{
+			bson.M{"StockID": bson.M{"$in": stockIDs}},
+			bson.M{"UserID": userUUID},
+		}}},
+		{"deleted_stocks", db.WarehouseCollection, bson.M{"UserID": userUUID}},
+		{"deleted_events", db.EventsCollection, bson.M{"EventOwner": userUUID}},
+		{"deleted_api_keys", db.APIKeysCollection, bson.M{"UserID": userUUID}},
+		{"deleted_password_resets", db.PasswordResetsCollection, bson.M{"UserID": userUUID}},
+		{"deleted_email_verifications", db.EmailVerificationsCollection, bson.M{"UserID": userUUID}},
+		{"deleted_status_changes", db.UserStatusChangesCollection, bson.M{"UserID": userUUID}},
+		{"deleted_login_attempts", db.LoginAttemptsCollection, bson.M{"Key": bson.M{"$in": bson.A{
+			"account:" + strings.ToLower(user.Email),
+			"invite:user:" + user.UserID,
+		}}}},
+		{"deleted_sessions", db.SessionsCollection, bson.M{"UserID": userUUID}},
+	}
+
+	result := fiber.Map{}
+	for _, step := range steps {
+		collection, err := step.collection(ctx)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+		}
+		res, err := collection.DeleteMany(ctx, step.filter)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to delete account data")
+		}
+		result[step.key] = res.DeletedCount
+	}
+
+	pseudonym := uuid.New()
+	pseudonymized, err := pseudonymizeAudit(ctx, user, ledgerStockIDs, pseudonym)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to pseudonymize audit log")
+	}
+	result["pseudonymized_audit_entries"] = pseudonymized
+
+	// Movements left at this point were recorded in other users' stocks and
+	// belong to their ledger, so they are kept under the same pseudonym.
+	movementsCol, err := db.StockMovementsCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	movementsRes, err := movementsCol.UpdateMany(ctx, bson.M{"ActorID": userUUID}, bson.M{"$set": bson.M{"ActorID": pseudonym}})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to pseudonymize movements")
+	}
+	result["pseudonymized_movements"] = movementsRes.ModifiedCount
+
+	userRes, err := usersCol.DeleteOne(ctx, bson.M{"UserId": user.UserID})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to delete user")
+	}
+	result["deleted_user"] = userRes.DeletedCount
+	recordAuditAs(ctx, c, pseudonym, models.AuditActionDelete, models.AuditEntityUser, pseudonym.String(), uuid.Nil, nil, nil)
+
+	return c.JSON(result)
+}
+
+// pseudonymizeAudit replaces a deleted user's ID in the audit log with
+// pseudonym, so the history of shared stocks survives without pointing at the
+// person. Snapshots of the user itself hold their email and name and are
+// dropped; in snapshots of every other entity their ID and email are replaced
+// and their name and avatar removed. stockIDs are the stocks the user owned or
+// deleted. It returns how many entries changed.
+func pseudonymizeAudit(ctx context.Context, user models.Users, stockIDs bson.A, pseudonym uuid.UUID) (int64, error) {
+	collection, err := db.AuditLogCollection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	actorID, err := uuid.Parse(user.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := collection.UpdateMany(ctx,
+		bson.M{"EntityType": models.AuditEntityUser, "EntityID": user.UserID},
+		bson.M{"$unset": bson.M{"Before": "", "After": ""}},
+	); err != nil {
+		return 0, err
+	}
+
+	mentions, err := auditMentions(ctx, collection, user, actorID, stockIDs)
+	if err != nil {
+		return 0, err
+	}
+	changed, err := scrubAuditSnapshots(ctx, collection, mentions, snapshotScrubber{user: user, pseudonym: pseudonym.String()})
+	if err != nil {
+		return changed, err
+	}
+
+	for _, step := range []struct {
+		filter bson.M
+		update interface{}
+	}{
+		{bson.M{"EntityID": user.UserID}, bson.M{"$set": bson.M{"EntityID": pseudonym.String()}}},
+		// Household members are identified as "<householdID>:<userID>".
+		{
+			bson.M{"EntityType": models.AuditEntityHouseholdMember, "EntityID": bson.M{"$regex": ":" + user.UserID + "$"}},
+			mongo.Pipeline{{{Key: "$set", Value: bson.M{
+				"EntityID": bson.M{"$concat": bson.A{bson.M{"$substrCP": bson.A{"$EntityID", 0, len(uuid.Nil.String()) + 1}}, pseudonym.String()}},
+			}}}},
+		},
+		{bson.M{"ActorID": actorID}, bson.M{"$set": bson.M{"ActorID": pseudonym}}},
+	} {
+		res, err := collection.UpdateMany(ctx, step.filter, step.update)
+		if err != nil {
+			return changed, err
+		}
+		changed += res.ModifiedCount
+	}
+	return changed, nil
+}
+
+// auditMentions matches the entries whose snapshots can mention the user:
+// those they made, those about their household memberships, the invites sent
+// to their email and everything recorded in a stock they owned, acted in or
+// were shared. Snapshots only hold users who took part in a change or who it
+// concerns, so the rest of the log need not be walked.
+func auditMentions(ctx context.Context, collection *mongo.Collection, user models.Users, actorID uuid.UUID, owned bson.A) (bson.M, error) {
+	touched, err := collection.Distinct(ctx, "StockID", bson.M{
+		"StockID": bson.M{"$ne": nil},
+		"$or": bson.A{
+			bson.M{"ActorID": actorID},
+			bson.M{"EntityType": models.AuditEntityStockShare, "EntityID": user.UserID},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bson.M{"$or": bson.A{
+		bson.M{"ActorID": actorID},
+		bson.M{"StockID": bson.M{"$in": append(append(bson.A{}, owned...), touched...)}},
+		bson.M{"EntityType": models.AuditEntityHouseholdMember, "EntityID": bson.M{"$regex": ":" + user.UserID + "$"}},
+		bson.M{"EntityType": models.AuditEntityHouseholdInvite, "$or": bson.A{
+			bson.M{"Before.Email": canonicalEmail(user.Email)},
+			bson.M{"After.Email": canonicalEmail(user.Email)},
+		}},
+	}}, nil
+}
+
+// scrubAuditSnapshots rewrites every Before and After snapshot of the entries
+// matching filter that mentions the user. Snapshots are free-form JSON, so
+// each one is walked in full.
+func scrubAuditSnapshots(ctx context.Context, collection *mongo.Collection, filter bson.M, scrubber snapshotScrubber) (int64, error) {
+	cursor, err := collection.Find(ctx,
+		bson.M{"$and": bson.A{
+			filter,
+			bson.M{"$or": bson.A{bson.M{"Before": bson.M{"$exists": true}}, bson.M{"After": bson.M{"$exists": true}}}},
+		}},
+		options.Find().SetProjection(bson.M{"EntryID": 1, "Before": 1, "After": 1}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var changed int64
+	for cursor.Next(ctx) {
+		var entry models.AuditLog
+		if err := cursor.Decode(&entry); err != nil {
+			return changed, err
+		}
+		before, beforeChanged := scrubber.scrub(entry.Before)
+		after, afterChanged := scrubber.scrub(entry.After)
+		if !beforeChanged && !afterChanged {
+			continue
+		}
+		set := bson.M{}
+		if beforeChanged {
+			set["Before"] = before
+		}
+		if afterChanged {
+			set["After"] = after
+		}
+		if _, err := collection.UpdateOne(ctx, bson.M{"EntryID": entry.EntryID}, bson.M{"$set": set}); err != nil {
+			return changed, err
+		}
+		changed++
+	}
+	return changed, cursor.Err()
+}
+
+// snapshotScrubber removes one user's identifiers from audit snapshots.
+type snapshotScrubber struct {
+	user      models.Users
+	pseudonym string
+}
+
+// scrub returns v with the user's ID replaced by the pseudonym wherever it
+// appears in a string, strings equal to their email replaced by the
+// pseudonym, and DisplayName and AvatarURL fields holding theirs removed.
+func (s snapshotScrubber) scrub(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case string:
+		if s.user.Email != "" && strings.EqualFold(v, s.user.Email) {
+			return s.pseudonym, true
+		}
+		if s.user.UserID != "" && strings.Contains(v, s.user.UserID) {
+			return strings.ReplaceAll(v, s.user.UserID, s.pseudonym), true
+		}
+		return v, false
+	case map[string]interface{}:
+		return s.scrubMap(v)
+	case bson.M:
+		return s.scrubMap(v)
+	case bson.D:
+		changed := false
+		out := make(bson.D, 0, len(v))
+		for _, e := range v {
+			if s.personalField(e.Key, e.Value) {
+				changed = true
+				continue
+			}
+			value, c := s.scrub(e.Value)
+			changed = changed || c
+			out = append(out, bson.E{Key: e.Key, Value: value})
+		}
+		return out, changed
+	case bson.A:
+		return s.scrubSlice(v)
+	case []interface{}:
+		return s.scrubSlice(v)
+	default:
+		return v, false
+	}
+}
+
+func (s snapshotScrubber) scrubMap(m map[string]interface{}) (interface{}, bool) {
+	if m == nil {
+		return m, false
+	}
+	changed := false
+	out := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		if s.personalField(key, value) {
+			changed = true
+			continue
+		}
+		scrubbed, c := s.scrub(value)
+		changed = changed || c
+		out[key] = scrubbed
+	}
+	return out, changed
+}
+
+func (s snapshotScrubber) scrubSlice(items []interface{}) (interface{}, bool) {
+	changed := false
+	out := make([]interface{}, len(items))
+	for i, item := range items {
+		var c bool
+		out[i], c = s.scrub(item)
+		changed = changed || c
+	}
+	return out, changed
+}
+
+// personalField reports whether key/value is the user's name or avatar.
+func (s snapshotScrubber) personalField(key string, value interface{}) bool {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return false
+	}
+	switch key {
+	case "DisplayName":
+		return str == s.user.DisplayName
+	case "AvatarURL":
+		return str == s.user.AvatarURL
+	}
+	return false
+}
+
+// ExportAccount godoc
+// @Summary      Export current user's data
+// @Description  Downloads everything stored about the authenticated user: profile, owned stocks with their products, categories and movements, the movements of stocks they deleted, movements they made elsewhere, events, sessions, API keys, status history, pending reset and verification tokens (without the tokens), login throttling state, household memberships, households they created, invites they sent, accepted or were sent, stock shares in both directions, notification settings and queued notifications, their whole shopping list including dismissed entries, and the audit entries they made or that are about their account. Returns one JSON document, or a ZIP archive with one JSON file per section when format=zip.
+// @Tags         users
+// @Produce      json
+// @Produce      application/zip
+// @Security     BearerAuth
+// @Param        format  query  string  false  "json (default) or zip"
+// @Success      200  {object}  accountExport
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/me/export [get]
+func ExportAccount(c *fiber.Ctx) error {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+	}
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.Query("format", "json")))
+	if format != "json" && format != "zip" {
+		return fiber.NewError(fiber.StatusBadRequest, "format must be json or zip")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	export, err := collectAccountExport(ctx, user, userUUID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to collect account data")
+	}
+
+	filename := "warehouse-export-" + export.ExportedAt.Format("20060102-150405")
+
+	if format == "json" {
+		body, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to encode export")
+		}
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return c.Send(body)
+	}
+
+	archive, err := zipAccountExport(export)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to build export archive")
+	}
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	c.Set(fiber.HeaderContentType, "application/zip")
+	return c.Send(archive)
+}
+
+func collectAccountExport(ctx context.Context, user models.Users, userUUID uuid.UUID) (accountExport, error) {
+	export := accountExport{
+		ExportedAt: time.Now().UTC(),
+		User:       user,
+	}
+
+	var err error
+	if export.Warehouse, err = findAll[models.Warehouse](ctx, db.WarehouseCollection, bson.M{"UserID": userUUID}); err != nil {
+		return accountExport{}, err
+	}
+
+	stockIDs := make(bson.A, 0, len(export.Warehouse))
+	for _, stock := range export.Warehouse {
+		stockIDs = append(stockIDs, stock.StockID)
+	}
+
+	if export.Products, err = findAll[models.Products](ctx, db.ProductsCollection, bson.M{"StockID": bson.M{"$in": stockIDs}}); err != nil {
+		return accountExport{}, err
+	}
+	if export.Categories, err = findAll[models.Categories](ctx, db.CategoriesCollection, bson.M{"StockID": bson.M{"$in": stockIDs}}); err != nil {
+		return accountExport{}, err
+	}
+	deletedIDs, err := deletedStockIDs(ctx, userUUID)
+	if err != nil {
+		return accountExport{}, err
+	}
+	if export.Movements, err = findAll[models.StockMovements](ctx, db.StockMovementsCollection, bson.M{"$or": bson.A{
+		bson.M{"StockID": bson.M{"$in": append(append(bson.A{}, stockIDs...), deletedIDs...)}},
+		bson.M{"ActorID": userUUID},
+	}}); err != nil {
+		return accountExport{}, err
+	}
+	if export.Events, err = findAll[models.Events](ctx, db.EventsCollection, bson.M{"EventOwner": userUUID}); err != nil {
+		return accountExport{}, err
+	}
+	if export.Sessions, err = findAll[models.Sessions](ctx, db.SessionsCollection, bson.M{"UserID": userUUID}); err != nil {
+		return accountExport{}, err
+	}
+	if export.APIKeys, err = findAll[models.APIKeys](ctx, db.APIKeysCollection, bson.M{"UserID": userUUID}); err != nil {
+		return accountExport{}, err
+	}
+	if export.StatusHistory, err = findAll[models.UserStatusChanges](ctx, db.UserStatusChangesCollection, bson.M{"UserID": userUUID}); err != nil {
+		return accountExport{}, err
+	}
+	if export.PasswordResets, err = findAll[models.PasswordResets](ctx, db.PasswordResetsCollection, bson.M{"UserID": userUUID}); err != nil {
+		return accountExport{}, err
+	}
+	if export.EmailVerifications, err = findAll[models.EmailVerifications](ctx, db.EmailVerificationsCollection, bson.M{"UserID": userUUID}); err != nil {
+		return accountExport{}, err
+	}
+	if export.LoginAttempts, err = findAll[models.LoginAttempts](ctx, db.LoginAttemptsCollection, bson.M{"Key": bson.M{"$in": bson.A{
+		"account:" + strings.ToLower(user.Email),
+		"invite:user:" + user.UserID,
+	}}}); err != nil {
+		return accountExport{}, err
+	}
+	if export.Households, err = findAll[models.HouseholdMembers](ctx, db.HouseholdMembersCollection, bson.M{"UserID": userUUID}); err != nil {
+		return accountExport{}, err
+	}
+	if export.CreatedHouseholds, err = findAll[models.Households](ctx, db.HouseholdsCollection, bson.M{"CreatedBy": userUUID}); err != nil {
+		return accountExport{}, err
+	}
+	if export.HouseholdInvites, err = findAll[models.HouseholdInvites](ctx, db.HouseholdInvitesCollection, bson.M{"$or": bson.A{
+		bson.M{"CreatedBy": userUUID},
+		bson.M{"AcceptedBy": userUUID},
+		bson.M{"Email": strings.ToLower(user.Email)},
+	}}); err != nil {
+		return accountExport{}, err
+	}
+	if export.SharedWithMe, err = findAll[models.StockShares](ctx, db.StockSharesCollection, bson.M{"UserID": userUUID}); err != nil {
+		return accountExport{}, err
+	}
+	if export.SharedByMe, err = findAll[models.StockShares](ctx, db.StockSharesCollection, bson.M{"$or": bson.A{
+		bson.M{"StockID": bson.M{"$in": stockIDs}},
+		bson.M{"SharedBy": userUUID},
+	}}); err != nil {
+		return accountExport{}, err
+	}
+	if export.Notifications, err = findAll[models.NotificationSettings](ctx, db.NotificationSettingsCollection, bson.M{"UserID": userUUID}); err != nil {
+		return accountExport{}, err
+	}
+	if export.NotificationOutbox, err = findAll[models.NotificationOutbox](ctx, db.NotificationOutboxCollection, bson.M{"UserID": userUUID}); err != nil {
+		return accountExport{}, err
+	}
+	if export.ShoppingList, err = findAll[models.ShoppingListItems](ctx, db.ShoppingListCollection, bson.M{"UserID": userUUID}); err != nil {
+		return accountExport{}, err
+	}
+	if export.AuditLog, err = findAll[models.AuditLog](ctx, db.AuditLogCollection, bson.M{"$or": bson.A{
+		bson.M{"ActorID": userUUID},
+		bson.M{"EntityType": models.AuditEntityUser, "EntityID": user.UserID},
+	}}); err != nil {
+		return accountExport{}, err
+	}
+
+	return export, nil
+}
+
+// zipAccountExport writes each section of the export to its own JSON file.
+func zipAccountExport(export accountExport) ([]byte, error) {
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"user.json", export.User},
+		{"warehouse.json", export.Warehouse},
+		{"products.json", export.Products},
+		{"categories.json", export.Categories},
+		{"movements.json", export.Movements},
+		{"events.json", export.Events},
+		{"sessions.json", export.Sessions},
+		{"api_keys.json", export.APIKeys},
+		{"status_history.json", export.StatusHistory},
+		{"password_resets.json", export.PasswordResets},
+		{"email_verifications.json", export.EmailVerifications},
+		{"login_attempts.json", export.LoginAttempts},
+		{"households.json", export.Households},
+		{"created_households.json", export.CreatedHouseholds},
+		{"household_invites.json", export.HouseholdInvites},
+		{"shared_with_me.json", export.SharedWithMe},
+		{"shared_by_me.json", export.SharedByMe},
+		{"notification_settings.json", export.Notifications},
+		{"notification_outbox.json", export.NotificationOutbox},
+		{"shopping_list.json", export.ShoppingList},
+		{"audit_log.json", export.AuditLog},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ownedStockIDs returns the IDs of every stock the user owns.
+func ownedStockIDs(ctx context.Context, userID uuid.UUID) (bson.A, error) {
+	stocks, err := findAll[models.Warehouse](ctx, db.WarehouseCollection, bson.M{"UserID": userID})
+	if err != nil {
+		return nil, err
+	}
+	ids := make(bson.A, 0, len(stocks))
+	for _, stock := range stocks {
+		ids = append(ids, stock.StockID)
+	}
+	return ids, nil
+}
+
+// deletedStockIDs returns the stocks the user has deleted, as recorded in the
+// audit log. Only a stock's owner can delete it, and its movements outlive
+// it, so these still hold ledger entries that belong to the user.
+func deletedStockIDs(ctx context.Context, userID uuid.UUID) (bson.A, error) {
+	collection, err := db.AuditLogCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entityIDs, err := collection.Distinct(ctx, "EntityID", bson.M{
+		"ActorID":    userID,
+		"EntityType": models.AuditEntityStock,
+		"Action":     models.AuditActionDelete,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make(bson.A, 0, len(entityIDs))
+	for _, raw := range entityIDs {
+		entityID, _ := raw.(string)
+		if id, err := uuid.Parse(entityID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// findAll decodes every document matching filter. It never returns a nil
+// slice so empty sections encode as [] rather than null.
+func findAll[T any](ctx context.Context, collection collectionFunc, filter bson.M) ([]T, error) {
+	col, err := collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := col.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	items := []T{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
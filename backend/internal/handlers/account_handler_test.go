@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	"my-backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSnapshotScrubber(t *testing.T) {
+	const (
+		userID    = "6f1c2f8e-6a43-4a51-9c3e-2f0b7f9a1d10"
+		otherID   = "0d9e8f7a-1b2c-4d3e-8f4a-5b6c7d8e9f00"
+		pseudonym = "3b0a6c1e-2d4f-4e5a-9b8c-7d6e5f4a3b21"
+	)
+	s := snapshotScrubber{
+		user:      models.Users{UserID: userID, Email: "ann@example.com", DisplayName: "Ann", AvatarURL: "https://example.com/ann.png"},
+		pseudonym: pseudonym,
+	}
+
+	tests := []struct {
+		name    string
+		in      interface{}
+		want    interface{}
+		changed bool
+	}{
+		{
+			name:    "stock share",
+			in:      map[string]interface{}{"StockID": otherID, "UserID": userID, "SharedBy": otherID, "Email": "Ann@Example.com", "Role": "viewer"},
+			want:    map[string]interface{}{"StockID": otherID, "UserID": pseudonym, "SharedBy": otherID, "Email": pseudonym, "Role": "viewer"},
+			changed: true,
+		},
+		{
+			name:    "household member entity ID and name",
+			in:      bson.M{"ID": otherID + ":" + userID, "DisplayName": "Ann", "Role": "editor"},
+			want:    map[string]interface{}{"ID": otherID + ":" + pseudonym, "Role": "editor"},
+			changed: true,
+		},
+		{
+			name: "nested documents and arrays",
+			in: bson.D{
+				{Key: "Members", Value: bson.A{bson.M{"UserID": userID, "AvatarURL": "https://example.com/ann.png"}, bson.M{"UserID": otherID}}},
+				{Key: "CreatedBy", Value: userID},
+			},
+			want: bson.D{
+				{Key: "Members", Value: []interface{}{map[string]interface{}{"UserID": pseudonym}, map[string]interface{}{"UserID": otherID}}},
+				{Key: "CreatedBy", Value: pseudonym},
+			},
+			changed: true,
+		},
+		{
+			name:    "someone else's name is kept",
+			in:      map[string]interface{}{"UserID": otherID, "DisplayName": "Bob", "Email": "bob@example.com", "Qty": int32(3)},
+			want:    map[string]interface{}{"UserID": otherID, "DisplayName": "Bob", "Email": "bob@example.com", "Qty": int32(3)},
+			changed: false,
+		},
+		{
+			name:    "a product named like the user is kept",
+			in:      map[string]interface{}{"ProductName": "Ann"},
+			want:    map[string]interface{}{"ProductName": "Ann"},
+			changed: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := s.scrub(tt.in)
+			if changed != tt.changed {
+				t.Errorf("changed = %v, want %v", changed, tt.changed)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scrub = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
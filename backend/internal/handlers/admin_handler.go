@@ -0,0 +1,458 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type updateUserStatusRequest struct {
+	Status string `json:"Status"`
+	Reason string `json:"Reason"`
+}
+
+type updateUserRoleRequest struct {
+	Role string `json:"Role"`
+}
+
+type stockStats struct {
+	StockID      uuid.UUID `json:"StockID"`
+	StockName    string    `json:"StockName"`
+	UserID       uuid.UUID `json:"UserID"`
+	ProductCount int64     `json:"ProductCount"`
+	TotalQty     int64     `json:"TotalQty"`
+}
+
+type systemStats struct {
+	Users      int64        `json:"Users"`
+	Stocks     int64        `json:"Stocks"`
+	Products   int64        `json:"Products"`
+	Categories int64        `json:"Categories"`
+	TotalQty   int64        `json:"TotalQty"`
+	PerStock   []stockStats `json:"PerStock"`
+}
+
+// allowedStatusTransitions lists the statuses an admin may move a user to
+// from each current status. PENDING_VERIFICATION is only ever entered by
+// registration, never set by hand.
+var allowedStatusTransitions = map[string][]string{
+	models.UserStatusActive:              {models.UserStatusInactive, models.UserStatusSuspended},
+	models.UserStatusInactive:            {models.UserStatusActive, models.UserStatusSuspended},
+	models.UserStatusSuspended:           {models.UserStatusActive, models.UserStatusInactive},
+	models.UserStatusPendingVerification: {models.UserStatusActive, models.UserStatusInactive, models.UserStatusSuspended},
+}
+
+// adminSettableStatuses are the statuses an admin may set: every target of
+// allowedStatusTransitions.
+var adminSettableStatuses = map[string]bool{
+	models.UserStatusActive:    true,
+	models.UserStatusInactive:  true,
+	models.UserStatusSuspended: true,
+}
+
+var validUserRoles = map[string]bool{
+	models.RoleUser:  true,
+	models.RoleAdmin: true,
+}
+
+// AdminListUsers godoc
+// @Summary      List users
+// @Description  Returns every registered user, optionally filtered by status and role. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status  query  string  false  "Filter by status"
+// @Param        role    query  string  false  "Filter by role"
+// @Success      200  {array}   models.Users
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/admin/users [get]
+func AdminListUsers(c *fiber.Ctx) error {
+	filter := bson.M{}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		filter["Status"] = strings.ToUpper(status)
+	}
+	if role := strings.TrimSpace(c.Query("role")); role != "" {
+		role = strings.ToLower(role)
+		if role == models.RoleUser {
+			filter["Role"] = bson.M{"$in": bson.A{models.RoleUser, nil}}
+		} else {
+			filter["Role"] = role
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "Email", Value: 1}})
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch users")
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.Users
+	if err := cursor.All(ctx, &users); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to decode users")
+	}
+
+	return c.JSON(users)
+}
+
+// AdminUpdateUserStatus godoc
+// @Summary      Change a user's status
+// @Description  Moves a user to a new Status and records who made the change and why. Leaving ACTIVE revokes the user's sessions. Admin only.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userId   path      string                   true  "User ID (UUID)"
+// @Param        payload  body      updateUserStatusRequest  true  "New status and reason"
+// @Success      200      {object}  models.Users
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /api/admin/users/{userId}/status [put]
+func AdminUpdateUserStatus(c *fiber.Ctx) error {
+	userIDParam, err := adminTargetUserID(c)
+	if err != nil {
+		return err
+	}
+
+	actorUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+	if actorUUID.String() == userIDParam {
+		return fiber.NewError(fiber.StatusBadRequest, "you cannot change your own status")
+	}
+
+	var req updateUserStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	req.Status = strings.ToUpper(strings.TrimSpace(req.Status))
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Status == "" || req.Reason == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Status and Reason are required")
+	}
+	if !adminSettableStatuses[req.Status] {
+		return fiber.NewError(fiber.StatusBadRequest, "Status must be ACTIVE, INACTIVE or SUSPENDED")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	usersCol, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	changesCol, err := db.UserStatusChangesCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var target models.Users
+	if err := usersCol.FindOne(ctx, bson.M{"UserId": userIDParam}).Decode(&target); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusNotFound, "user not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch user")
+	}
+
+	fromStatus := target.Status
+	if fromStatus == "" {
+		fromStatus = models.UserStatusActive
+	}
+	if !statusTransitionAllowed(fromStatus, req.Status) {
+		return fiber.NewError(fiber.StatusConflict, "cannot change status from "+fromStatus+" to "+req.Status)
+	}
+
+	// Matching on the status we read makes concurrent changes fail instead of
+	// silently recording the wrong FromStatus.
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	res := usersCol.FindOneAndUpdate(ctx,
+		bson.M{"UserId": userIDParam, "Status": target.Status},
+		bson.M{"$set": bson.M{"Status": req.Status}},
+		opts,
+	)
+	var updated models.Users
+	if err := res.Decode(&updated); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusConflict, "user status changed concurrently; retry")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to update user")
+	}
+
+	targetUUID, _ := uuid.Parse(userIDParam)
+	change := models.UserStatusChanges{
+		ChangeID:   uuid.New(),
+		UserID:     targetUUID,
+		ActorID:    actorUUID,
+		FromStatus: fromStatus,
+		ToStatus:   req.Status,
+		Reason:     req.Reason,
+		ChangedAt:  time.Now().UTC(),
+	}
+	if _, err := changesCol.InsertOne(ctx, change); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to record status change")
+	}
+	recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntityUser, userIDParam, uuid.Nil, target, updated)
+
+	if !updated.IsActive() {
+		if _, err := revokeSessions(ctx, bson.M{"UserID": targetUUID}, "account "+strings.ToLower(req.Status)); err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to revoke sessions")
+		}
+	}
+
+	return c.JSON(updated)
+}
+
+// AdminUserStatusHistory godoc
+// @Summary      User status history
+// @Description  Returns every recorded status change for a user, newest first. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userId  path  string  true  "User ID (UUID)"
+// @Success      200  {array}   models.UserStatusChanges
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/admin/users/{userId}/status-history [get]
+func AdminUserStatusHistory(c *fiber.Ctx) error {
+	userIDParam, err := adminTargetUserID(c)
+	if err != nil {
+		return err
+	}
+	targetUUID, _ := uuid.Parse(userIDParam)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection, err := db.UserStatusChangesCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "ChangedAt", Value: -1}})
+	cursor, err := collection.Find(ctx, bson.M{"UserID": targetUUID}, opts)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch status history")
+	}
+	defer cursor.Close(ctx)
+
+	var changes []models.UserStatusChanges
+	if err := cursor.All(ctx, &changes); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to decode status history")
+	}
+
+	return c.JSON(changes)
+}
+
+// AdminUpdateUserRole godoc
+// @Summary      Change a user's role
+// @Description  Grants or removes the admin role. Admins cannot demote themselves. Admin only.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userId   path      string                 true  "User ID (UUID)"
+// @Param        payload  body      updateUserRoleRequest  true  "New role"
+// @Success      200      {object}  models.Users
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /api/admin/users/{userId}/role [put]
+func AdminUpdateUserRole(c *fiber.Ctx) error {
+	userIDParam, err := adminTargetUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req updateUserRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	req.Role = strings.ToLower(strings.TrimSpace(req.Role))
+	if !validUserRoles[req.Role] {
+		return fiber.NewError(fiber.StatusBadRequest, "Role is not a valid user role")
+	}
+
+	if current, ok := middleware.CurrentUser(c); ok && current.UserID == userIDParam && req.Role != models.RoleAdmin {
+		return fiber.NewError(fiber.StatusBadRequest, "you cannot remove your own admin role")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return updateUserField(ctx, c, userIDParam, "Role", req.Role)
+}
+
+// AdminSystemStats godoc
+// @Summary      System-wide stock statistics
+// @Description  Returns totals for users, stocks, products and categories plus per-stock product counts. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  systemStats
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/admin/stats [get]
+func AdminSystemStats(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	usersCol, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	warehouseCol, err := db.WarehouseCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	productsCol, err := db.ProductsCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	categoriesCol, err := db.CategoriesCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var stats systemStats
+	if stats.Users, err = usersCol.CountDocuments(ctx, bson.M{}); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to count users")
+	}
+	if stats.Categories, err = categoriesCol.CountDocuments(ctx, bson.M{}); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to count categories")
+	}
+
+	cursor, err := warehouseCol.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "StockName", Value: 1}}))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch warehouse")
+	}
+	var stocks []models.Warehouse
+	if err := cursor.All(ctx, &stocks); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to decode warehouse")
+	}
+
+	aggCursor, err := productsCol.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":          "$StockID",
+			"ProductCount": bson.M{"$sum": 1},
+			"TotalQty":     bson.M{"$sum": "$ProductQty"},
+		}}},
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to aggregate products")
+	}
+	var groups []struct {
+		StockID      uuid.UUID `bson:"_id"`
+		ProductCount int64     `bson:"ProductCount"`
+		TotalQty     int64     `bson:"TotalQty"`
+	}
+	if err := aggCursor.All(ctx, &groups); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to decode product totals")
+	}
+
+	perStock := make(map[uuid.UUID]stockStats, len(stocks))
+	for _, stock := range stocks {
+		perStock[stock.StockID] = stockStats{
+			StockID:   stock.StockID,
+			StockName: stock.StockName,
+			UserID:    stock.UserID,
+		}
+	}
+	for _, group := range groups {
+		stats.Products += group.ProductCount
+		stats.TotalQty += group.TotalQty
+		if entry, ok := perStock[group.StockID]; ok {
+			entry.ProductCount = group.ProductCount
+			entry.TotalQty = group.TotalQty
+			perStock[group.StockID] = entry
+		}
+	}
+
+	stats.Stocks = int64(len(stocks))
+	stats.PerStock = make([]stockStats, 0, len(stocks))
+	for _, stock := range stocks {
+		stats.PerStock = append(stats.PerStock, perStock[stock.StockID])
+	}
+
+	return c.JSON(stats)
+}
+
+func statusTransitionAllowed(from, to string) bool {
+	for _, allowed := range allowedStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func adminTargetUserID(c *fiber.Ctx) (string, error) {
+	userIDParam := strings.TrimSpace(c.Params("userId"))
+	if userIDParam == "" {
+		return "", fiber.NewError(fiber.StatusBadRequest, "userId is required")
+	}
+	if _, err := uuid.Parse(userIDParam); err != nil {
+		return "", fiber.NewError(fiber.StatusBadRequest, "userId must be a valid UUID")
+	}
+	return userIDParam, nil
+}
+
+func updateUserField(ctx context.Context, c *fiber.Ctx, userID, field string, value interface{}) error {
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var before models.Users
+	if err := collection.FindOne(ctx, bson.M{"UserId": userID}).Decode(&before); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusNotFound, "user not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch user")
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	res := collection.FindOneAndUpdate(ctx, bson.M{"UserId": userID}, bson.M{"$set": bson.M{field: value}}, opts)
+	var updated models.Users
+	if err := res.Decode(&updated); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusNotFound, "user not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to update user")
+	}
+	recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntityUser, userID, uuid.Nil, before, updated)
+
+	return c.JSON(updated)
+}
@@ -0,0 +1,38 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminUpdateUserStatusValidatesTarget(t *testing.T) {
+	app := newApp(t)
+	admin := insertUser(t, models.Users{Role: models.RoleAdmin})
+	var login struct {
+		AccessToken string `json:"AccessToken"`
+	}
+	if status := doJSON(t, app, http.MethodPost, "/api/login", "", fiber.Map{"Email": admin.Email, "Password": testPassword}, &login); status != http.StatusOK {
+		t.Fatalf("login as admin: status %d", status)
+	}
+	target := newUser(t, app)
+	path := "/api/admin/users/" + target.ID.String() + "/status"
+
+	tests := []struct {
+		status string
+		want   int
+	}{
+		{models.UserStatusPendingVerification, http.StatusBadRequest},
+		{"DELETED", http.StatusBadRequest},
+		{models.UserStatusSuspended, http.StatusOK},
+	}
+	for _, tt := range tests {
+		resp, raw := do(t, app, http.MethodPut, path, login.AccessToken, fiber.Map{"Status": tt.status, "Reason": "test"})
+		if resp.StatusCode != tt.want {
+			t.Errorf("Status %s: status %d, want %d: %s", tt.status, resp.StatusCode, tt.want, raw)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"my-backend/internal/auth"
+	"my-backend/internal/db"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	apiKeyPrefix       = "pwm_"
+	apiKeyPrefixLength = len(apiKeyPrefix) + 8
+	maxAPIKeysPerUser  = 50
+)
+
+type createAPIKeyRequest struct {
+	Name      string     `json:"Name"`
+	Scopes    []string   `json:"Scopes"`
+	ExpiresAt *time.Time `json:"ExpiresAt"`
+}
+
+type createAPIKeyResponse struct {
+	Key    string         `json:"Key"`
+	APIKey models.APIKeys `json:"APIKey"`
+}
+
+// CreateAPIKey godoc
+// @Summary      Create an API key
+// @Description  Creates a personal API key with the given scopes and optional expiry. The key is only returned once; use it as "Authorization: ApiKey <key>".
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        payload  body      createAPIKeyRequest  true  "Key name, scopes and expiry"
+// @Success      201  {object}  createAPIKeyResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/api-keys [post]
+func CreateAPIKey(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req createAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Name is required")
+	}
+	if len(req.Scopes) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "at least one scope is required")
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !validAPIKeyScope(scope) {
+			return fiber.NewError(fiber.StatusBadRequest, "unknown scope "+scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	now := time.Now().UTC()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return fiber.NewError(fiber.StatusBadRequest, "ExpiresAt must be in the future")
+	}
+
+	token, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to generate API key")
+	}
+	rawKey := apiKeyPrefix + token
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection, err := db.APIKeysCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	active, err := collection.CountDocuments(ctx, bson.M{"UserID": userUUID, "RevokedAt": nil})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to count API keys")
+	}
+	if active >= maxAPIKeysPerUser {
+		return fiber.NewError(fiber.StatusBadRequest, "too many API keys; revoke unused ones first")
+	}
+
+	key := models.APIKeys{
+		KeyID:     uuid.New(),
+		UserID:    userUUID,
+		Name:      req.Name,
+		Prefix:    rawKey[:apiKeyPrefixLength],
+		KeyHash:   auth.HashOpaqueToken(rawKey),
+		Scopes:    scopes,
+		CreatedAt: now,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	if _, err := collection.InsertOne(ctx, key); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to create API key")
+	}
+	recordAudit(ctx, c, models.AuditActionCreate, models.AuditEntityAPIKey, key.KeyID.String(), uuid.Nil, nil, key)
+
+	return c.Status(fiber.StatusCreated).JSON(createAPIKeyResponse{
+		Key:    rawKey,
+		APIKey: key,
+	})
+}
+
+// ListAPIKeys godoc
+// @Summary      List API keys
+// @Description  Returns the authenticated user's API keys, including revoked and expired ones.
+// @Tags         api-keys
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   models.APIKeys
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/api-keys [get]
+func ListAPIKeys(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection, err := db.APIKeysCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "CreatedAt", Value: -1}})
+	cursor, err := collection.Find(ctx, bson.M{"UserID": userUUID}, opts)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch API keys")
+	}
+	defer cursor.Close(ctx)
+
+	keys := []models.APIKeys{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to decode API keys")
+	}
+
+	return c.JSON(keys)
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke an API key
+// @Description  Revokes one of the authenticated user's API keys immediately.
+// @Tags         api-keys
+// @Produce      json
+// @Security     BearerAuth
+// @Param        keyId  path  string  true  "API key ID (UUID)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/api-keys/{keyId} [delete]
+func RevokeAPIKey(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	keyIDParam := strings.TrimSpace(c.Params("keyId"))
+	if keyIDParam == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "keyId is required")
+	}
+
+	keyUUID, err := uuid.Parse(keyIDParam)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "keyId must be a valid UUID")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection, err := db.APIKeysCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var revoked models.APIKeys
+	err = collection.FindOneAndUpdate(ctx,
+		bson.M{"KeyID": keyUUID, "UserID": userUUID, "RevokedAt": nil},
+		bson.M{"$set": bson.M{"RevokedAt": time.Now().UTC()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&revoked)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusNotFound, "API key not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to revoke API key")
+	}
+	before := revoked
+	before.RevokedAt = nil
+	recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntityAPIKey, keyUUID.String(), uuid.Nil, before, revoked)
+
+	return c.JSON(fiber.Map{
+		"revoked_api_keys": 1,
+	})
+}
+
+func validAPIKeyScope(scope string) bool {
+	for _, valid := range models.APIKeyScopes {
+		if scope == valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAPIKeysCannotReachAccountEndpoints(t *testing.T) {
+	app := newApp(t)
+	user := newUser(t, app)
+
+	var created struct {
+		Key string `json:"Key"`
+	}
+	body := fiber.Map{"Name": "scanner", "Scopes": []string{models.ScopeWarehouseRead}}
+	if status := doJSON(t, app, http.MethodPost, "/api/api-keys", user.Token, body, &created); status != http.StatusCreated {
+		t.Fatalf("create API key: status %d", status)
+	}
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/warehouse", http.StatusOK},
+		{http.MethodGet, "/api/me", http.StatusForbidden},
+		{http.MethodGet, "/api/me/export", http.StatusForbidden},
+		{http.MethodGet, "/api/sessions", http.StatusForbidden},
+		{http.MethodGet, "/api/api-keys", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set(fiber.HeaderAuthorization, "ApiKey "+created.Key)
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s %s with an API key: status %d, want %d", tt.method, tt.path, resp.StatusCode, tt.want)
+		}
+	}
+}
+
+func TestAPIKeysOnlySeeStockAuditEntries(t *testing.T) {
+	app := newApp(t)
+	user := newUser(t, app)
+
+	var created struct {
+		Key string `json:"Key"`
+	}
+	body := fiber.Map{"Name": "scanner", "Scopes": []string{models.ScopeWarehouseRead, models.ScopeWarehouseWrite}}
+	if status := doJSON(t, app, http.MethodPost, "/api/api-keys", user.Token, body, &created); status != http.StatusCreated {
+		t.Fatalf("create API key: status %d", status)
+	}
+	if status := doJSON(t, app, http.MethodPost, "/api/warehouse", user.Token, fiber.Map{"StockName": "Pantry"}, nil); status != http.StatusCreated {
+		t.Fatalf("create stock: status %d", status)
+	}
+
+	var entries []models.AuditLog
+	if status := doJSON(t, app, http.MethodGet, "/api/audit", user.Token, nil, &entries); status != http.StatusOK {
+		t.Fatalf("list audit as session: status %d", status)
+	}
+	sawKey := false
+	for _, entry := range entries {
+		sawKey = sawKey || entry.EntityType == models.AuditEntityAPIKey
+	}
+	if !sawKey {
+		t.Error("session: the API key creation is missing from the audit log")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/audit", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "ApiKey "+created.Key)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	entries = nil
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || len(entries) == 0 {
+		t.Fatalf("list audit with an API key: status %d with %d entries", resp.StatusCode, len(entries))
+	}
+	for _, entry := range entries {
+		if entry.StockID == nil {
+			t.Errorf("API key sees the %s entry %s, which belongs to no stock", entry.EntityType, entry.EntityID)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/inventory"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// recordAudit appends an entry to the audit log. stockID is uuid.Nil for
+// changes that do not belong to a stock; before or after is nil for creates
+// and deletes. The change itself has already happened, so a failed write is
+// logged rather than turned into an error response.
+func recordAudit(ctx context.Context, c *fiber.Ctx, action, entityType, entityID string, stockID uuid.UUID, before, after interface{}) {
+	actorID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		log.Printf("audit: %s %s %s without an authenticated user", action, entityType, entityID)
+		return
+	}
+	recordAuditAs(ctx, c, actorID, action, entityType, entityID, stockID, before, after)
+}
+
+// recordAuditAs is recordAudit for requests without an authenticated user,
+// such as registration or a password reset, where the actor is known from
+// the token the request carried.
+func recordAuditAs(ctx context.Context, c *fiber.Ctx, actorID uuid.UUID, action, entityType, entityID string, stockID uuid.UUID, before, after interface{}) {
+	entry := models.AuditLog{
+		EntryID:    uuid.New(),
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     auditSnapshot(before),
+		After:      auditSnapshot(after),
+		CreatedAt:  time.Now().UTC(),
+	}
+	if key, ok := middleware.CurrentAPIKey(c); ok {
+		entry.APIKeyID = &key.KeyID
+	}
+	if stockID != uuid.Nil {
+		entry.StockID = &stockID
+	}
+
+	collection, err := db.AuditLogCollection(ctx)
+	if err == nil {
+		_, err = collection.InsertOne(ctx, entry)
+	}
+	if err != nil {
+		log.Printf("audit: failed to record %s %s %s: %v", action, entityType, entityID, err)
+	}
+}
+
+// auditSnapshot converts v to its JSON form so the log stores exactly what
+// the API exposes.
+func auditSnapshot(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil
+	}
+	return snapshot
+}
+
+// ListAudit godoc
+// @Summary      List audit log entries
+// @Description  Returns audit entries newest first. With stockId the entries of that stock are returned (requires access to the stock); without it, the changes made by the authenticated user, limited to stock changes for API keys. The total number of matches is sent in X-Total-Count.
+// @Tags         audit
+// @Produce      json
+// @Security     BearerAuth
+// @Param        stockId     query  string  false  "Stock ID (UUID)"
+// @Param        entityType  query  string  false  "Filter by entity type, e.g. product"
+// @Param        entityId    query  string  false  "Filter by entity ID"
+// @Param        page        query  int     false  "Page number, starting at 1"
+// @Param        limit       query  int     false  "Entries per page (default 50, max 200)"
+// @Success      200  {array}   models.AuditLog
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/audit [get]
+func ListAudit(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	page, limit, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"ActorID": userUUID}
+	if stockIDParam := strings.TrimSpace(c.Query("stockId")); stockIDParam != "" {
+		stockUUID, err := uuid.Parse(stockIDParam)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "stockId must be a valid UUID")
+		}
+		if _, err := authorizeStock(ctx, c, stockUUID, inventory.AccessViewer); err != nil {
+			return err
+		}
+		filter = bson.M{"StockID": stockUUID}
+	} else if _, ok := middleware.CurrentAPIKey(c); ok {
+		// Account security changes are only visible to a signed-in session,
+		// like the account routes themselves.
+		filter["StockID"] = bson.M{"$ne": nil}
+	}
+	if entityType := strings.TrimSpace(c.Query("entityType")); entityType != "" {
+		filter["EntityType"] = strings.ToLower(entityType)
+	}
+	if entityID := strings.TrimSpace(c.Query("entityId")); entityID != "" {
+		filter["EntityID"] = entityID
+	}
+
+	collection, err := db.AuditLogCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to count audit entries")
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "CreatedAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip((page - 1) * limit).
+		SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch audit entries")
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.AuditLog{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to decode audit entries")
+	}
+
+	c.Set("X-Total-Count", strconv.FormatInt(total, 10))
+	return c.JSON(entries)
+}
+
+// pageParams reads the 1-based page and limit query parameters.
+func pageParams(c *fiber.Ctx) (int64, int64, error) {
+	page := int64(1)
+	if raw := strings.TrimSpace(c.Query("page")); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || v < 1 {
+			return 0, 0, fiber.NewError(fiber.StatusBadRequest, "page must be a positive integer")
+		}
+		page = v
+	}
+	limit, err := limitParam(c)
+	if err != nil {
+		return 0, 0, err
+	}
+	return page, limit, nil
+}
+
+// limitParam reads the limit query parameter, capped at maxPageLimit.
+func limitParam(c *fiber.Ctx) (int64, error) {
+	raw := strings.TrimSpace(c.Query("limit"))
+	if raw == "" {
+		return defaultPageLimit, nil
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || v < 1 {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "limit must be a positive integer")
+	}
+	if v > maxPageLimit {
+		v = maxPageLimit
+	}
+	return v, nil
+}
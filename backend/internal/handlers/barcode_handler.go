@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"my-backend/internal/catalog"
+	"my-backend/internal/db"
+	"my-backend/internal/inventory"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	maxProductBarcodes = 20
+	maxSKULength       = 64
+)
+
+// barcodeMatch is a product found by barcode, with the name of its stock.
+type barcodeMatch struct {
+	models.Products
+	StockName string `json:"StockName"`
+}
+
+// LookupProduct godoc
+// @Summary      Look up a product by barcode
+// @Description  Returns the products carrying the scanned EAN/UPC barcode in every stock the user can access, or only in stockId when given. A barcode is unique within a stock, so there is at most one match per stock. Codes are compared as GTIN-14, so a UPC-A code also finds its EAN-13 spelling with a leading 0. When nothing matches, GET /api/catalog/lookup can prefill a new product.
+// @Tags         products
+// @Produce      json
+// @Security     BearerAuth
+// @Param        barcode  query  string  true   "EAN-8, UPC-A, EAN-13 or GTIN-14"
+// @Param        stockId  query  string  false  "Only search this stock (UUID)"
+// @Success      200  {array}   barcodeMatch
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/products/lookup [get]
+func LookupProduct(c *fiber.Ctx) error {
+	barcodes, err := normalizeBarcodes([]string{c.Query("barcode")})
+	if err != nil {
+		return err
+	}
+	if len(barcodes) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "barcode is required")
+	}
+
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var stocks []models.Warehouse
+	if stockIDRaw := strings.TrimSpace(c.Query("stockId")); stockIDRaw != "" {
+		stockUUID, err := uuid.Parse(stockIDRaw)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "stockId must be a valid UUID")
+		}
+		stock, err := authorizeStock(ctx, c, stockUUID, inventory.AccessViewer)
+		if err != nil {
+			return err
+		}
+		stocks = []models.Warehouse{stock}
+	} else {
+		filter, err := accessibleStocksFilter(ctx, userUUID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to resolve stock access")
+		}
+		if stocks, err = findAll[models.Warehouse](ctx, db.WarehouseCollection, filter); err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch warehouse")
+		}
+	}
+
+	stockNames := make(map[uuid.UUID]string, len(stocks))
+	stockIDs := make(bson.A, 0, len(stocks))
+	for _, stock := range stocks {
+		stockNames[stock.StockID] = stock.StockName
+		stockIDs = append(stockIDs, stock.StockID)
+	}
+
+	products, err := findAll[models.Products](ctx, db.ProductsCollection, bson.M{
+		"StockID": bson.M{"$in": stockIDs},
+		"GTINs":   barcodeGTINs(barcodes)[0],
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch products")
+	}
+	if len(products) == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "no product with this barcode")
+	}
+
+	matches := make([]barcodeMatch, 0, len(products))
+	for _, product := range products {
+		matches = append(matches, barcodeMatch{Products: product, StockName: stockNames[product.StockID]})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].StockName < matches[j].StockName
+	})
+
+	return c.JSON(matches)
+}
+
+// normalizeBarcodes strips spaces and dashes from each code, checks that it
+// is a GTIN (EAN-8, UPC-A, EAN-13 or GTIN-14) with a valid check digit and
+// drops blanks and duplicates, including other spellings of a code already
+// given.
+func normalizeBarcodes(raw []string) ([]string, error) {
+	if len(raw) > maxProductBarcodes {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "a product can have at most 20 barcodes")
+	}
+
+	barcodes := make([]string, 0, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for _, code := range raw {
+		code = strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		if !validGTIN(code) {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "barcode "+code+" is not a valid EAN/UPC code")
+		}
+		gtin, _ := catalog.GTIN(code)
+		if seen[gtin] {
+			continue
+		}
+		seen[gtin] = true
+		barcodes = append(barcodes, code)
+	}
+	return barcodes, nil
+}
+
+// barcodeGTINs returns normalized barcodes padded to GTIN-14, the form
+// products are looked up and kept unique by.
+func barcodeGTINs(barcodes []string) []string {
+	gtins := make([]string, 0, len(barcodes))
+	for _, code := range barcodes {
+		gtin, _ := catalog.GTIN(code)
+		gtins = append(gtins, gtin)
+	}
+	return gtins
+}
+
+// validGTIN reports whether code is an 8, 12, 13 or 14 digit GTIN whose last
+// digit matches the GS1 check digit.
+func validGTIN(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		d := code[i]
+		if d < '0' || d > '9' {
+			return false
+		}
+		weight := 1
+		if (len(code)-2-i)%2 == 0 {
+			weight = 3
+		}
+		sum += int(d-'0') * weight
+	}
+	check := code[len(code)-1]
+	return check >= '0' && check <= '9' && int(check-'0') == (10-sum%10)%10
+}
+
+// normalizeSKU trims the SKU and checks its length.
+func normalizeSKU(raw string) (string, error) {
+	sku := strings.TrimSpace(raw)
+	if utf8.RuneCountInString(sku) > maxSKULength {
+		return "", fiber.NewError(fiber.StatusBadRequest, "SKU must be at most 64 characters")
+	}
+	return sku, nil
+}
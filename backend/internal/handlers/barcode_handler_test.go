@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidGTIN(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{"EAN-8", "96385074", true},
+		{"EAN-8 with a wrong check digit", "96385075", false},
+		{"UPC-A", "036000291452", true},
+		{"UPC-A with a wrong check digit", "036000291453", false},
+		{"EAN-13", "4006381333931", true},
+		{"EAN-13 with a wrong check digit", "4006381333932", false},
+		{"EAN-13 spelling of a UPC-A code", "0036000291452", true},
+		{"GTIN-14", "10036000291459", true},
+		{"GTIN-14 with a wrong check digit", "10036000291450", false},
+		{"too short", "1234565", false},
+		{"between lengths", "12345678901", false},
+		{"too long", "100360002914590", false},
+		{"empty", "", false},
+		{"letter", "40063813339A1", false},
+		{"letter as check digit", "400638133393X", false},
+		{"unicode digit", "40063813339\u0663", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validGTIN(tt.code); got != tt.want {
+				t.Errorf("validGTIN(%q) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeBarcodesKeepsOneSpellingPerGTIN(t *testing.T) {
+	barcodes, err := normalizeBarcodes([]string{"036000 291452", "0036000-291452", "00036000291452", "96385074"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"036000291452", "96385074"}; !reflect.DeepEqual(barcodes, want) {
+		t.Errorf("barcodes %q, want %q", barcodes, want)
+	}
+	if got, want := barcodeGTINs(barcodes), []string{"00036000291452", "00000096385074"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GTINs %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"my-backend/internal/catalog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LookupCatalog godoc
+// @Summary      Look up a barcode in the product catalog
+// @Description  Returns the offline catalog entry for a barcode, to prefill a new product when the barcode is not in any stock yet. The catalog is imported from Open Food Facts with the catalog-import command.
+// @Tags         products
+// @Produce      json
+// @Security     BearerAuth
+// @Param        barcode  query  string  true  "EAN-8, UPC-A, EAN-13 or GTIN-14"
+// @Success      200  {object}  models.CatalogProducts
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/catalog/lookup [get]
+func LookupCatalog(c *fiber.Ctx) error {
+	barcodes, err := normalizeBarcodes([]string{c.Query("barcode")})
+	if err != nil {
+		return err
+	}
+	if len(barcodes) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "barcode is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entry, err := catalog.Lookup(ctx, barcodes[0])
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to look up the product catalog")
+	}
+	if entry == nil {
+		return fiber.NewError(fiber.StatusNotFound, "barcode not in the product catalog")
+	}
+
+	return c.JSON(entry)
+}
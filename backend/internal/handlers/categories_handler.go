@@ -2,18 +2,17 @@ package handlers
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"my-backend/internal/db"
+	"my-backend/internal/inventory"
 	"my-backend/internal/models"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type categoryRequest struct {
@@ -27,9 +26,12 @@ type categoryRequest struct {
 // @Description  Returns categories filtered by StockID.
 // @Tags         categories
 // @Produce      json
+// @Security     BearerAuth
 // @Param        stockId  query  string  true  "Stock ID (UUID)"
 // @Success      200  {array}   models.Categories
 // @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /api/categories [get]
 func ListCategories(c *fiber.Ctx) error {
@@ -46,6 +48,10 @@ func ListCategories(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if _, err := authorizeStock(ctx, c, stockUUID, inventory.AccessViewer); err != nil {
+		return err
+	}
+
 	collection, err := db.CategoriesCollection(ctx)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
@@ -71,9 +77,12 @@ func ListCategories(c *fiber.Ctx) error {
 // @Tags         categories
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        payload  body      []categoryRequest  true  "List of categories"
 // @Success      201  {array}   models.Categories
 // @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /api/categories [post]
 func CreateCategories(c *fiber.Ctx) error {
@@ -88,6 +97,7 @@ func CreateCategories(c *fiber.Ctx) error {
 
 	categories := make([]models.Categories, len(payload))
 	docs := make([]interface{}, len(payload))
+	stockIDs := make(map[uuid.UUID]struct{})
 
 	for i, cat := range payload {
 		cat.StockID = strings.TrimSpace(cat.StockID)
@@ -111,11 +121,18 @@ func CreateCategories(c *fiber.Ctx) error {
 		}
 		categories[i] = category
 		docs[i] = category
+		stockIDs[stockUUID] = struct{}{}
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	for stockUUID := range stockIDs {
+		if _, err := authorizeStock(ctx, c, stockUUID, inventory.AccessEditor); err != nil {
+			return err
+		}
+	}
+
 	collection, err := db.CategoriesCollection(ctx)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
@@ -124,6 +141,9 @@ func CreateCategories(c *fiber.Ctx) error {
 	if _, err := collection.InsertMany(ctx, docs); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to create categories")
 	}
+	for _, category := range categories {
+		recordAudit(ctx, c, models.AuditActionCreate, models.AuditEntityCategory, category.CategoryID.String(), category.StockID, nil, category)
+	}
 
 	return c.Status(fiber.StatusCreated).JSON(categories)
 }
@@ -133,9 +153,11 @@ func CreateCategories(c *fiber.Ctx) error {
 // @Description  Sets Category to null on related products (matching StockID and category name) then deletes the category document.
 // @Tags         categories
 // @Produce      json
+// @Security     BearerAuth
 // @Param        categoryId  path  string  true  "Category ID (UUID)"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
 // @Failure      404  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /api/categories/{categoryId} [delete]
@@ -153,6 +175,11 @@ func DeleteCategory(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	category, err := authorizeCategory(ctx, c, categoryUUID, inventory.AccessEditor)
+	if err != nil {
+		return err
+	}
+
 	categoriesCol, err := db.CategoriesCollection(ctx)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
@@ -162,14 +189,6 @@ func DeleteCategory(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
 	}
 
-	var category models.Categories
-	if err := categoriesCol.FindOne(ctx, bson.M{"CategoryID": categoryUUID}).Decode(&category); err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return fiber.NewError(fiber.StatusNotFound, "category not found")
-		}
-		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch category")
-	}
-
 	updateRes, err := productsCol.UpdateMany(
 		ctx,
 		bson.M{"StockID": category.StockID, "Category": category.CategoryName},
@@ -183,6 +202,9 @@ func DeleteCategory(c *fiber.Ctx) error {
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to delete category")
 	}
+	if deleteRes.DeletedCount > 0 {
+		recordAudit(ctx, c, models.AuditActionDelete, models.AuditEntityCategory, categoryUUID.String(), category.StockID, category, nil)
+	}
 
 	return c.JSON(fiber.Map{
 		"updated_products": updateRes.ModifiedCount,
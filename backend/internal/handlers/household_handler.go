@@ -0,0 +1,1048 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/inventory"
+	mailer "my-backend/internal/mail"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const householdInviteTTL = 7 * 24 * time.Hour
+
+// householdRoleRank orders household roles for authorizeHousehold. It is kept
+// apart from inventory.HouseholdStockAccess, which caps owners at editing the stocks.
+var householdRoleRank = map[string]int{
+	models.HouseholdRoleViewer: 1,
+	models.HouseholdRoleEditor: 2,
+	models.HouseholdRoleOwner:  3,
+}
+
+type householdRequest struct {
+	Name string `json:"Name"`
+}
+
+type householdInviteRequest struct {
+	Email string `json:"Email"`
+	Role  string `json:"Role"`
+}
+
+type householdInviteResponse struct {
+	Code   string                  `json:"Code"`
+	Invite models.HouseholdInvites `json:"Invite"`
+}
+
+type joinHouseholdRequest struct {
+	Code string `json:"Code"`
+}
+
+type householdRoleRequest struct {
+	Role string `json:"Role"`
+}
+
+type assignHouseholdRequest struct {
+	HouseholdID *string `json:"HouseholdID"`
+}
+
+type householdSummary struct {
+	models.Households
+	Role string `json:"Role"`
+}
+
+type householdMemberView struct {
+	UserID      uuid.UUID `json:"UserID"`
+	Email       string    `json:"Email"`
+	DisplayName string    `json:"DisplayName,omitempty"`
+	Role        string    `json:"Role"`
+	JoinedAt    time.Time `json:"JoinedAt"`
+}
+
+type householdDetail struct {
+	models.Households
+	Role    string                `json:"Role"`
+	Members []householdMemberView `json:"Members"`
+	Stocks  []models.Warehouse    `json:"Stocks"`
+}
+
+// CreateHousehold godoc
+// @Summary      Create a household
+// @Description  Creates a household with the authenticated user as its owner.
+// @Tags         households
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        payload  body      householdRequest  true  "Household name"
+// @Success      201  {object}  householdSummary
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/households [post]
+func CreateHousehold(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req householdRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Name is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	householdsCol, err := db.HouseholdsCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	membersCol, err := db.HouseholdMembersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	now := time.Now().UTC()
+	household := models.Households{
+		HouseholdID: uuid.New(),
+		Name:        req.Name,
+		CreatedBy:   userUUID,
+		CreatedAt:   now,
+	}
+	if _, err := householdsCol.InsertOne(ctx, household); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to create household")
+	}
+
+	owner := models.HouseholdMembers{
+		HouseholdID: household.HouseholdID,
+		UserID:      userUUID,
+		Role:        models.HouseholdRoleOwner,
+		JoinedAt:    now,
+	}
+	if _, err := membersCol.InsertOne(ctx, owner); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to add household owner")
+	}
+	recordAudit(ctx, c, models.AuditActionCreate, models.AuditEntityHousehold, household.HouseholdID.String(), uuid.Nil, nil, household)
+
+	return c.Status(fiber.StatusCreated).JSON(householdSummary{Households: household, Role: owner.Role})
+}
+
+// ListHouseholds godoc
+// @Summary      List households
+// @Description  Returns the households the authenticated user belongs to, with their role in each.
+// @Tags         households
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   householdSummary
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/households [get]
+func ListHouseholds(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	memberships, err := findAll[models.HouseholdMembers](ctx, db.HouseholdMembersCollection, bson.M{"UserID": userUUID})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch memberships")
+	}
+
+	roles := make(map[uuid.UUID]string, len(memberships))
+	ids := make(bson.A, 0, len(memberships))
+	for _, member := range memberships {
+		roles[member.HouseholdID] = member.Role
+		ids = append(ids, member.HouseholdID)
+	}
+
+	households, err := findAll[models.Households](ctx, db.HouseholdsCollection, bson.M{"HouseholdID": bson.M{"$in": ids}})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch households")
+	}
+
+	result := make([]householdSummary, 0, len(households))
+	for _, household := range households {
+		result = append(result, householdSummary{Households: household, Role: roles[household.HouseholdID]})
+	}
+
+	return c.JSON(result)
+}
+
+// GetHousehold godoc
+// @Summary      Get a household
+// @Description  Returns a household with its members and stocks. Members only.
+// @Tags         households
+// @Produce      json
+// @Security     BearerAuth
+// @Param        householdId  path  string  true  "Household ID (UUID)"
+// @Success      200  {object}  householdDetail
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/households/{householdId} [get]
+func GetHousehold(c *fiber.Ctx) error {
+	householdUUID, err := householdIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	household, member, err := authorizeHousehold(ctx, c, householdUUID, models.HouseholdRoleViewer)
+	if err != nil {
+		return err
+	}
+
+	members, err := findAll[models.HouseholdMembers](ctx, db.HouseholdMembersCollection, bson.M{"HouseholdID": householdUUID})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch members")
+	}
+
+	userIDs := make(bson.A, 0, len(members))
+	for _, m := range members {
+		userIDs = append(userIDs, m.UserID.String())
+	}
+	users, err := findAll[models.Users](ctx, db.UsersCollection, bson.M{"UserId": bson.M{"$in": userIDs}})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch users")
+	}
+	usersByID := make(map[string]models.Users, len(users))
+	for _, u := range users {
+		usersByID[u.UserID] = u
+	}
+
+	detail := householdDetail{
+		Households: household,
+		Role:       member.Role,
+		Members:    make([]householdMemberView, 0, len(members)),
+	}
+	for _, m := range members {
+		u := usersByID[m.UserID.String()]
+		detail.Members = append(detail.Members, householdMemberView{
+			UserID:      m.UserID,
+			Email:       u.Email,
+			DisplayName: u.DisplayName,
+			Role:        m.Role,
+			JoinedAt:    m.JoinedAt,
+		})
+	}
+
+	if detail.Stocks, err = findAll[models.Warehouse](ctx, db.WarehouseCollection, bson.M{"HouseholdID": householdUUID}); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch stocks")
+	}
+
+	return c.JSON(detail)
+}
+
+// DeleteHousehold godoc
+// @Summary      Delete a household
+// @Description  Deletes a household, its memberships and invitations. Its stocks are kept and stay with their owners. Household owners only.
+// @Tags         households
+// @Produce      json
+// @Security     BearerAuth
+// @Param        householdId  path  string  true  "Household ID (UUID)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/households/{householdId} [delete]
+func DeleteHousehold(c *fiber.Ctx) error {
+	householdUUID, err := householdIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	household, _, err := authorizeHousehold(ctx, c, householdUUID, models.HouseholdRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	result, err := deleteHousehold(ctx, householdUUID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to delete household")
+	}
+	recordAudit(ctx, c, models.AuditActionDelete, models.AuditEntityHousehold, householdUUID.String(), uuid.Nil, household, nil)
+
+	return c.JSON(result)
+}
+
+// CreateHouseholdInvite godoc
+// @Summary      Invite to a household
+// @Description  Creates an invite code for the given role. With an Email the code is also mailed and only that user can accept it. The code is only returned once. Household owners only.
+// @Tags         households
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        householdId  path      string                  true  "Household ID (UUID)"
+// @Param        payload      body      householdInviteRequest  true  "Invitee email (optional) and role"
+// @Success      201  {object}  householdInviteResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/households/{householdId}/invites [post]
+func CreateHouseholdInvite(c *fiber.Ctx) error {
+	householdUUID, err := householdIDParam(c)
+	if err != nil {
+		return err
+	}
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req householdInviteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	role, err := validHouseholdRole(req.Role)
+	if err != nil {
+		return err
+	}
+
+	var email string
+	if strings.TrimSpace(req.Email) != "" {
+		if email, err = normalizeEmail(req.Email); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	household, _, err := authorizeHousehold(ctx, c, householdUUID, models.HouseholdRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	code, err := newReadableCode()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to generate invite code")
+	}
+
+	collection, err := db.HouseholdInvitesCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	now := time.Now().UTC()
+	invite := models.HouseholdInvites{
+		InviteID:    uuid.New(),
+		HouseholdID: householdUUID,
+		Email:       email,
+		CodeHash:    hashReadableCode(code),
+		Role:        role,
+		CreatedBy:   userUUID,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(householdInviteTTL),
+	}
+	if _, err := collection.InsertOne(ctx, invite); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to create invite")
+	}
+	recordAudit(ctx, c, models.AuditActionCreate, models.AuditEntityHouseholdInvite, invite.InviteID.String(), uuid.Nil, nil, invite)
+
+	if email != "" {
+		msg := mailer.Message{
+			To:      email,
+			Subject: "You are invited to " + household.Name,
+			Body: fmt.Sprintf("You have been invited to join the household %q as %s.\n\n"+
+				"Sign in and enter the code %s, or open this link (valid for %s):\n%s",
+				household.Name, role, code, householdInviteTTL, mailer.AppURL("/households/join?code="+url.QueryEscape(code))),
+		}
+		go func() {
+			sendCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := mailer.DefaultSender().Send(sendCtx, msg); err != nil {
+				log.Printf("failed to send household invite: %v", err)
+			}
+		}()
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(householdInviteResponse{Code: code, Invite: invite})
+}
+
+// ListHouseholdInvites godoc
+// @Summary      List household invites
+// @Description  Returns the household's invites that have not been accepted yet. Household owners only.
+// @Tags         households
+// @Produce      json
+// @Security     BearerAuth
+// @Param        householdId  path  string  true  "Household ID (UUID)"
+// @Success      200  {array}   models.HouseholdInvites
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/households/{householdId}/invites [get]
+func ListHouseholdInvites(c *fiber.Ctx) error {
+	householdUUID, err := householdIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, _, err := authorizeHousehold(ctx, c, householdUUID, models.HouseholdRoleOwner); err != nil {
+		return err
+	}
+
+	invites, err := findAll[models.HouseholdInvites](ctx, db.HouseholdInvitesCollection, bson.M{
+		"HouseholdID": householdUUID,
+		"AcceptedAt":  nil,
+		"ExpiresAt":   bson.M{"$gt": time.Now().UTC()},
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch invites")
+	}
+
+	return c.JSON(invites)
+}
+
+// RevokeHouseholdInvite godoc
+// @Summary      Revoke a household invite
+// @Description  Deletes an invite so its code can no longer be used. Household owners only.
+// @Tags         households
+// @Produce      json
+// @Security     BearerAuth
+// @Param        householdId  path  string  true  "Household ID (UUID)"
+// @Param        inviteId     path  string  true  "Invite ID (UUID)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/households/{householdId}/invites/{inviteId} [delete]
+func RevokeHouseholdInvite(c *fiber.Ctx) error {
+	householdUUID, err := householdIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	inviteUUID, err := uuid.Parse(strings.TrimSpace(c.Params("inviteId")))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "inviteId must be a valid UUID")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, _, err := authorizeHousehold(ctx, c, householdUUID, models.HouseholdRoleOwner); err != nil {
+		return err
+	}
+
+	collection, err := db.HouseholdInvitesCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var invite models.HouseholdInvites
+	if err := collection.FindOneAndDelete(ctx, bson.M{"InviteID": inviteUUID, "HouseholdID": householdUUID, "AcceptedAt": nil}).Decode(&invite); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusNotFound, "invite not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to revoke invite")
+	}
+	recordAudit(ctx, c, models.AuditActionDelete, models.AuditEntityHouseholdInvite, inviteUUID.String(), uuid.Nil, invite, nil)
+
+	return c.JSON(fiber.Map{
+		"revoked_invites": 1,
+	})
+}
+
+// JoinHousehold godoc
+// @Summary      Join a household
+// @Description  Accepts an invite code and adds the authenticated user to the household with the invite's role. Wrong codes count towards a temporary lockout per user and per client IP.
+// @Tags         households
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        payload  body      joinHouseholdRequest  true  "Invite code"
+// @Success      200  {object}  householdSummary
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/households/join [post]
+func JoinHousehold(c *fiber.Ctx) error {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+	}
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req joinHouseholdRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+	if strings.TrimSpace(req.Code) == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Code is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	throttleKeys := inviteThrottleKeys(c, userUUID)
+	if err := checkLoginLockout(ctx, c, throttleKeys); err != nil {
+		return err
+	}
+
+	invitesCol, err := db.HouseholdInvitesCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	membersCol, err := db.HouseholdMembersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	householdsCol, err := db.HouseholdsCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	now := time.Now().UTC()
+	filter := bson.M{
+		"CodeHash":   hashReadableCode(req.Code),
+		"AcceptedAt": nil,
+		"ExpiresAt":  bson.M{"$gt": now},
+	}
+
+	var invite models.HouseholdInvites
+	if err := invitesCol.FindOne(ctx, filter).Decode(&invite); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return inviteFailed(ctx, throttleKeys, fiber.NewError(fiber.StatusBadRequest, "invite code is invalid or has expired"))
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch invite")
+	}
+	if invite.Email != "" && !strings.EqualFold(invite.Email, user.Email) {
+		return inviteFailed(ctx, throttleKeys, fiber.NewError(fiber.StatusForbidden, "this invite is for a different email address"))
+	}
+	if err := clearLoginFailures(ctx, throttleKeys); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to reset invite attempts")
+	}
+
+	existing, err := inventory.HouseholdMembership(ctx, invite.HouseholdID, userUUID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch membership")
+	}
+	if existing != nil {
+		return fiber.NewError(fiber.StatusConflict, "you are already a member of this household")
+	}
+
+	var household models.Households
+	if err := householdsCol.FindOne(ctx, bson.M{"HouseholdID": invite.HouseholdID}).Decode(&household); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusBadRequest, "invite code is invalid or has expired")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch household")
+	}
+
+	// Consuming the invite atomically guarantees it is only used once. It is
+	// handed back if the membership cannot be created.
+	filter["InviteID"] = invite.InviteID
+	res, err := invitesCol.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"AcceptedAt": now, "AcceptedBy": userUUID}})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to accept invite")
+	}
+	if res.ModifiedCount == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invite code is invalid or has expired")
+	}
+
+	member := models.HouseholdMembers{
+		HouseholdID: invite.HouseholdID,
+		UserID:      userUUID,
+		Role:        invite.Role,
+		JoinedAt:    now,
+	}
+	if _, err := membersCol.InsertOne(ctx, member); err != nil {
+		if _, rollbackErr := invitesCol.UpdateOne(ctx,
+			bson.M{"InviteID": invite.InviteID, "AcceptedBy": userUUID},
+			bson.M{"$unset": bson.M{"AcceptedAt": "", "AcceptedBy": ""}},
+		); rollbackErr != nil {
+			log.Printf("households: failed to release invite %s: %v", invite.InviteID, rollbackErr)
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return fiber.NewError(fiber.StatusConflict, "you are already a member of this household")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to join household")
+	}
+	recordAudit(ctx, c, models.AuditActionCreate, models.AuditEntityHouseholdMember, householdMemberEntityID(member.HouseholdID, userUUID), uuid.Nil, nil, member)
+
+	return c.JSON(householdSummary{Households: household, Role: member.Role})
+}
+
+// UpdateHouseholdMember godoc
+// @Summary      Change a member's role
+// @Description  Sets a member's household role. The last owner cannot be demoted. Household owners only.
+// @Tags         households
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        householdId  path      string                true  "Household ID (UUID)"
+// @Param        userId       path      string                true  "Member user ID (UUID)"
+// @Param        payload      body      householdRoleRequest  true  "New role"
+// @Success      200  {object}  models.HouseholdMembers
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/households/{householdId}/members/{userId} [put]
+func UpdateHouseholdMember(c *fiber.Ctx) error {
+	householdUUID, err := householdIDParam(c)
+	if err != nil {
+		return err
+	}
+	memberUUID, err := uuid.Parse(strings.TrimSpace(c.Params("userId")))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "userId must be a valid UUID")
+	}
+
+	var req householdRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+	role, err := validHouseholdRole(req.Role)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, _, err := authorizeHousehold(ctx, c, householdUUID, models.HouseholdRoleOwner); err != nil {
+		return err
+	}
+
+	target, err := inventory.HouseholdMembership(ctx, householdUUID, memberUUID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch membership")
+	}
+	if target == nil {
+		return fiber.NewError(fiber.StatusNotFound, "member not found")
+	}
+	if target.Role == models.HouseholdRoleOwner && role != models.HouseholdRoleOwner {
+		if err := ensureOtherHouseholdOwner(ctx, householdUUID, memberUUID); err != nil {
+			return err
+		}
+	}
+
+	collection, err := db.HouseholdMembersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var updated models.HouseholdMembers
+	err = collection.FindOneAndUpdate(ctx,
+		bson.M{"HouseholdID": householdUUID, "UserID": memberUUID},
+		bson.M{"$set": bson.M{"Role": role}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusNotFound, "member not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to update member")
+	}
+	recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntityHouseholdMember, householdMemberEntityID(householdUUID, memberUUID), uuid.Nil, target, updated)
+
+	return c.JSON(updated)
+}
+
+// RemoveHouseholdMember godoc
+// @Summary      Remove a household member
+// @Description  Removes a member from the household. Owners can remove anyone; every member can remove themselves to leave. The last owner cannot leave. Stocks the member owns are taken out of the household.
+// @Tags         households
+// @Produce      json
+// @Security     BearerAuth
+// @Param        householdId  path  string  true  "Household ID (UUID)"
+// @Param        userId       path  string  true  "Member user ID (UUID)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/households/{householdId}/members/{userId} [delete]
+func RemoveHouseholdMember(c *fiber.Ctx) error {
+	householdUUID, err := householdIDParam(c)
+	if err != nil {
+		return err
+	}
+	memberUUID, err := uuid.Parse(strings.TrimSpace(c.Params("userId")))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "userId must be a valid UUID")
+	}
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	required := models.HouseholdRoleOwner
+	if memberUUID == userUUID {
+		required = models.HouseholdRoleViewer
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, _, err := authorizeHousehold(ctx, c, householdUUID, required); err != nil {
+		return err
+	}
+
+	target, err := inventory.HouseholdMembership(ctx, householdUUID, memberUUID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch membership")
+	}
+	if target == nil {
+		return fiber.NewError(fiber.StatusNotFound, "member not found")
+	}
+	if target.Role == models.HouseholdRoleOwner {
+		if err := ensureOtherHouseholdOwner(ctx, householdUUID, memberUUID); err != nil {
+			return err
+		}
+	}
+
+	collection, err := db.HouseholdMembersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	res, err := collection.DeleteOne(ctx, bson.M{"HouseholdID": householdUUID, "UserID": memberUUID})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to remove member")
+	}
+	if res.DeletedCount > 0 {
+		recordAudit(ctx, c, models.AuditActionDelete, models.AuditEntityHouseholdMember, householdMemberEntityID(householdUUID, memberUUID), uuid.Nil, target, nil)
+	}
+
+	detached, err := detachMemberStocks(ctx, c, householdUUID, memberUUID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to detach the member's stocks")
+	}
+
+	return c.JSON(fiber.Map{
+		"removed_members": res.DeletedCount,
+		"detached_stocks": detached,
+	})
+}
+
+// AssignStockHousehold godoc
+// @Summary      Move a stock into a household
+// @Description  Makes a stock available to a household's members, or takes it out of its household when HouseholdID is null. Only the stock owner can do this, and they must be at least an editor of the target household.
+// @Tags         warehouse
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        stockId  path      string                  true  "Stock ID (UUID)"
+// @Param        payload  body      assignHouseholdRequest  true  "Target household, or null"
+// @Success      200  {object}  models.Warehouse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/warehouse/{stockId}/household [put]
+func AssignStockHousehold(c *fiber.Ctx) error {
+	stockUUID, err := uuid.Parse(strings.TrimSpace(c.Params("stockId")))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "stockId must be a valid UUID")
+	}
+
+	var req assignHouseholdRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stock, err := authorizeStock(ctx, c, stockUUID, inventory.AccessOwner)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$unset": bson.M{"HouseholdID": ""}}
+	if req.HouseholdID != nil {
+		householdUUID, err := uuid.Parse(strings.TrimSpace(*req.HouseholdID))
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "HouseholdID must be a valid UUID")
+		}
+		if _, _, err := authorizeHousehold(ctx, c, householdUUID, models.HouseholdRoleEditor); err != nil {
+			return err
+		}
+		update = bson.M{"$set": bson.M{"HouseholdID": householdUUID}}
+	}
+
+	collection, err := db.WarehouseCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var updated models.Warehouse
+	err = collection.FindOneAndUpdate(ctx,
+		bson.M{"StockID": stockUUID},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusNotFound, "stock not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to update stock")
+	}
+	recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntityStock, stockUUID.String(), stockUUID, stock, updated)
+
+	return c.JSON(updated)
+}
+
+// authorizeHousehold loads a household and ensures the authenticated user is
+// a member with at least the required role. Non-members get 404 so household
+// IDs cannot be probed.
+func authorizeHousehold(ctx context.Context, c *fiber.Ctx, householdID uuid.UUID, required string) (models.Households, models.HouseholdMembers, error) {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return models.Households{}, models.HouseholdMembers{}, err
+	}
+
+	member, err := inventory.HouseholdMembership(ctx, householdID, userUUID)
+	if err != nil {
+		return models.Households{}, models.HouseholdMembers{}, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch membership")
+	}
+	if member == nil {
+		return models.Households{}, models.HouseholdMembers{}, fiber.NewError(fiber.StatusNotFound, "household not found")
+	}
+	if householdRoleRank[member.Role] < householdRoleRank[required] {
+		return models.Households{}, models.HouseholdMembers{}, fiber.NewError(fiber.StatusForbidden, "your household role does not allow this")
+	}
+
+	collection, err := db.HouseholdsCollection(ctx)
+	if err != nil {
+		return models.Households{}, models.HouseholdMembers{}, fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var household models.Households
+	if err := collection.FindOne(ctx, bson.M{"HouseholdID": householdID}).Decode(&household); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Households{}, models.HouseholdMembers{}, fiber.NewError(fiber.StatusNotFound, "household not found")
+		}
+		return models.Households{}, models.HouseholdMembers{}, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch household")
+	}
+
+	return household, *member, nil
+}
+
+// ensureOtherHouseholdOwner returns 409 unless someone besides userID owns
+// the household, so it is never left without an owner.
+func ensureOtherHouseholdOwner(ctx context.Context, householdID, userID uuid.UUID) error {
+	collection, err := db.HouseholdMembersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	owners, err := collection.CountDocuments(ctx, bson.M{
+		"HouseholdID": householdID,
+		"Role":        models.HouseholdRoleOwner,
+		"UserID":      bson.M{"$ne": userID},
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to count owners")
+	}
+	if owners == 0 {
+		return fiber.NewError(fiber.StatusConflict, "a household needs at least one owner; promote another member first")
+	}
+	return nil
+}
+
+// deleteHousehold removes a household with its memberships and invites and
+// detaches its stocks.
+func deleteHousehold(ctx context.Context, householdID uuid.UUID) (fiber.Map, error) {
+	warehouseCol, err := db.WarehouseCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	membersCol, err := db.HouseholdMembersCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	invitesCol, err := db.HouseholdInvitesCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	householdsCol, err := db.HouseholdsCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stockRes, err := warehouseCol.UpdateMany(ctx, bson.M{"HouseholdID": householdID}, bson.M{"$unset": bson.M{"HouseholdID": ""}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := invitesCol.DeleteMany(ctx, bson.M{"HouseholdID": householdID}); err != nil {
+		return nil, err
+	}
+	memberRes, err := membersCol.DeleteMany(ctx, bson.M{"HouseholdID": householdID})
+	if err != nil {
+		return nil, err
+	}
+	householdRes, err := householdsCol.DeleteOne(ctx, bson.M{"HouseholdID": householdID})
+	if err != nil {
+		return nil, err
+	}
+
+	return fiber.Map{
+		"deleted_household": householdRes.DeletedCount,
+		"removed_members":   memberRes.DeletedCount,
+		"detached_stocks":   stockRes.ModifiedCount,
+	}, nil
+}
+
+// detachMemberStocks takes the stocks userID owns out of a household they no
+// longer belong to, so the remaining members lose access to them.
+func detachMemberStocks(ctx context.Context, c *fiber.Ctx, householdID, userID uuid.UUID) (int64, error) {
+	filter := bson.M{"UserID": userID, "HouseholdID": householdID}
+	stocks, err := findAll[models.Warehouse](ctx, db.WarehouseCollection, filter)
+	if err != nil {
+		return 0, err
+	}
+	if len(stocks) == 0 {
+		return 0, nil
+	}
+
+	collection, err := db.WarehouseCollection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	res, err := collection.UpdateMany(ctx, filter, bson.M{"$unset": bson.M{"HouseholdID": ""}})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, stock := range stocks {
+		updated := stock
+		updated.HouseholdID = nil
+		recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntityStock, stock.StockID.String(), stock.StockID, stock, updated)
+	}
+	return res.ModifiedCount, nil
+}
+
+// leaveAllHouseholds removes the user from every household before their
+// account is deleted. Households they are the only member of are deleted;
+// if they are the last owner of a household with other members nothing is
+// changed and 409 is returned. The user's stocks are taken out of the
+// households they leave.
+func leaveAllHouseholds(ctx context.Context, c *fiber.Ctx, userID uuid.UUID) error {
+	memberships, err := findAll[models.HouseholdMembers](ctx, db.HouseholdMembersCollection, bson.M{"UserID": userID})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch memberships")
+	}
+
+	collection, err := db.HouseholdMembersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var emptied []uuid.UUID
+	for _, member := range memberships {
+		others, err := collection.CountDocuments(ctx, bson.M{"HouseholdID": member.HouseholdID, "UserID": bson.M{"$ne": userID}})
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to count members")
+		}
+		if others == 0 {
+			emptied = append(emptied, member.HouseholdID)
+			continue
+		}
+		if member.Role == models.HouseholdRoleOwner {
+			if err := ensureOtherHouseholdOwner(ctx, member.HouseholdID, userID); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, householdID := range emptied {
+		if _, err := deleteHousehold(ctx, householdID); err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to delete household")
+		}
+	}
+	if _, err := collection.DeleteMany(ctx, bson.M{"UserID": userID}); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to leave households")
+	}
+	for _, member := range memberships {
+		if _, err := detachMemberStocks(ctx, c, member.HouseholdID, userID); err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to detach stocks from households")
+		}
+	}
+	return nil
+}
+
+func householdIDParam(c *fiber.Ctx) (uuid.UUID, error) {
+	householdIDParam := strings.TrimSpace(c.Params("householdId"))
+	if householdIDParam == "" {
+		return uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "householdId is required")
+	}
+
+	householdUUID, err := uuid.Parse(householdIDParam)
+	if err != nil {
+		return uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "householdId must be a valid UUID")
+	}
+	return householdUUID, nil
+}
+
+func validHouseholdRole(raw string) (string, error) {
+	role := strings.ToLower(strings.TrimSpace(raw))
+	if _, ok := householdRoleRank[role]; !ok {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Role must be owner, editor or viewer")
+	}
+	return role, nil
+}
+
+// householdMemberEntityID identifies a membership in the audit log.
+func householdMemberEntityID(householdID, userID uuid.UUID) string {
+	return householdID.String() + ":" + userID.String()
+}
+
+// inviteFailed counts a rejected invite code towards the caller's lockout and
+// returns err.
+func inviteFailed(ctx context.Context, keys []loginThrottleKey, err error) error {
+	if recordErr := recordLoginFailure(ctx, keys); recordErr != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to record invite attempt")
+	}
+	return err
+}
@@ -0,0 +1,263 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// householdFixture is a household with an editor, a viewer and a bystander
+// viewer as members, and one invite that is still open.
+type householdFixture struct {
+	HouseholdID string
+	InviteID    string
+}
+
+func inviteToHousehold(t *testing.T, app *fiber.App, householdID, ownerToken, email, role string) string {
+	t.Helper()
+	var invite struct {
+		Code   string                  `json:"Code"`
+		Invite models.HouseholdInvites `json:"Invite"`
+	}
+	path := "/api/households/" + householdID + "/invites"
+	if status := doJSON(t, app, http.MethodPost, path, ownerToken, fiber.Map{"Email": email, "Role": role}, &invite); status != http.StatusCreated {
+		t.Fatalf("invite %s as %s: status %d", email, role, status)
+	}
+	return invite.Code
+}
+
+func newHouseholdFixture(t *testing.T, app *fiber.App, users roleUsers) householdFixture {
+	t.Helper()
+	owner := users.owner.Token
+
+	var household struct {
+		models.Households
+		Role string `json:"Role"`
+	}
+	if status := doJSON(t, app, http.MethodPost, "/api/households", owner, fiber.Map{"Name": "Home"}, &household); status != http.StatusCreated {
+		t.Fatalf("create household: status %d", status)
+	}
+	f := householdFixture{HouseholdID: household.HouseholdID.String()}
+
+	members := []struct {
+		user testUser
+		role string
+	}{
+		{users.editor, models.HouseholdRoleEditor},
+		{users.viewer, models.HouseholdRoleViewer},
+		{users.bystander, models.HouseholdRoleViewer},
+	}
+	for _, m := range members {
+		code := inviteToHousehold(t, app, f.HouseholdID, owner, m.user.Email, m.role)
+		if status := doJSON(t, app, http.MethodPost, "/api/households/join", m.user.Token, fiber.Map{"Code": code}, nil); status != http.StatusOK {
+			t.Fatalf("join as %s: status %d", m.role, status)
+		}
+	}
+
+	inviteToHousehold(t, app, f.HouseholdID, owner, "pending-"+users.stranger.Email, models.HouseholdRoleViewer)
+	var invites []models.HouseholdInvites
+	if status := doJSON(t, app, http.MethodGet, "/api/households/"+f.HouseholdID+"/invites", owner, nil, &invites); status != http.StatusOK || len(invites) == 0 {
+		t.Fatalf("list invites: status %d", status)
+	}
+	for _, invite := range invites {
+		if invite.AcceptedAt == nil {
+			f.InviteID = invite.InviteID.String()
+		}
+	}
+	return f
+}
+
+func TestHouseholdRoutesEnforceRoles(t *testing.T) {
+	app := newApp(t)
+	users := newRoleUsers(t, app)
+
+	tests := []struct {
+		name   string
+		method string
+		path   func(f householdFixture) string
+		body   interface{}
+		want   map[string]int
+	}{
+		{
+			name:   "get household",
+			method: http.MethodGet,
+			path:   func(f householdFixture) string { return "/api/households/" + f.HouseholdID },
+			want:   map[string]int{"owner": 200, "editor": 200, "viewer": 200, "stranger": 404},
+		},
+		{
+			name:   "delete household",
+			method: http.MethodDelete,
+			path:   func(f householdFixture) string { return "/api/households/" + f.HouseholdID },
+			want:   map[string]int{"owner": 200, "editor": 403, "viewer": 403, "stranger": 404},
+		},
+		{
+			name:   "invite an owner",
+			method: http.MethodPost,
+			path:   func(f householdFixture) string { return "/api/households/" + f.HouseholdID + "/invites" },
+			body:   fiber.Map{"Email": "new-owner@example.com", "Role": models.HouseholdRoleOwner},
+			want:   map[string]int{"owner": 201, "editor": 403, "viewer": 403, "stranger": 404},
+		},
+		{
+			name:   "list invites",
+			method: http.MethodGet,
+			path:   func(f householdFixture) string { return "/api/households/" + f.HouseholdID + "/invites" },
+			want:   map[string]int{"owner": 200, "editor": 403, "viewer": 403, "stranger": 404},
+		},
+		{
+			name:   "revoke invite",
+			method: http.MethodDelete,
+			path: func(f householdFixture) string {
+				return "/api/households/" + f.HouseholdID + "/invites/" + f.InviteID
+			},
+			want: map[string]int{"owner": 200, "editor": 403, "viewer": 403, "stranger": 404},
+		},
+		{
+			name:   "promote the editor to owner",
+			method: http.MethodPut,
+			path: func(f householdFixture) string {
+				return "/api/households/" + f.HouseholdID + "/members/" + users.editor.ID.String()
+			},
+			body: fiber.Map{"Role": models.HouseholdRoleOwner},
+			want: map[string]int{"owner": 200, "editor": 403, "viewer": 403, "stranger": 404},
+		},
+		{
+			name:   "remove another member",
+			method: http.MethodDelete,
+			path: func(f householdFixture) string {
+				return "/api/households/" + f.HouseholdID + "/members/" + users.bystander.ID.String()
+			},
+			want: map[string]int{"owner": 200, "editor": 403, "viewer": 403, "stranger": 404},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, role := range []string{"owner", "editor", "viewer", "stranger"} {
+			t.Run(tt.name+"/"+role, func(t *testing.T) {
+				f := newHouseholdFixture(t, app, users)
+				resp, raw := do(t, app, tt.method, tt.path(f), users.byRole(role).Token, tt.body)
+				if resp.StatusCode != tt.want[role] {
+					t.Errorf("%s %s: status %d, want %d: %s", tt.method, tt.path(f), resp.StatusCode, tt.want[role], raw)
+				}
+			})
+		}
+	}
+}
+
+func TestHouseholdMembersCanLeave(t *testing.T) {
+	app := newApp(t)
+	users := newRoleUsers(t, app)
+
+	for _, role := range []string{"editor", "viewer"} {
+		t.Run(role, func(t *testing.T) {
+			f := newHouseholdFixture(t, app, users)
+			user := users.byRole(role)
+			path := "/api/households/" + f.HouseholdID + "/members/" + user.ID.String()
+			if resp, raw := do(t, app, http.MethodDelete, path, user.Token, nil); resp.StatusCode != http.StatusOK {
+				t.Errorf("leaving as %s: status %d: %s", role, resp.StatusCode, raw)
+			}
+		})
+	}
+}
+
+func TestHouseholdOwnerCannotDeleteMembersStocks(t *testing.T) {
+	app := newApp(t)
+	users := newRoleUsers(t, app)
+	f := newHouseholdFixture(t, app, users)
+
+	var stock models.Warehouse
+	body := fiber.Map{"StockName": "Editor's shelf", "HouseholdID": f.HouseholdID}
+	if status := doJSON(t, app, http.MethodPost, "/api/warehouse", users.editor.Token, body, &stock); status != http.StatusCreated {
+		t.Fatalf("create household stock: status %d", status)
+	}
+
+	path := "/api/warehouse/" + stock.StockID.String()
+	if resp, raw := do(t, app, http.MethodDelete, path, users.owner.Token, nil); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("household owner deleting a member's stock: status %d, want 403: %s", resp.StatusCode, raw)
+	}
+	product := fiber.Map{"StockID": stock.StockID.String(), "ProductName": "Flour", "ProductQty": 1}
+	if status := doJSON(t, app, http.MethodPost, "/api/products", users.owner.Token, product, nil); status != http.StatusCreated {
+		t.Errorf("household owner adding a product: status %d, want 201", status)
+	}
+}
+
+func TestTransferredStockLeavesHousehold(t *testing.T) {
+	app := newApp(t)
+	users := newRoleUsers(t, app)
+	f := newHouseholdFixture(t, app, users)
+
+	var stock models.Warehouse
+	body := fiber.Map{"StockName": "Editor's shelf", "HouseholdID": f.HouseholdID}
+	if status := doJSON(t, app, http.MethodPost, "/api/warehouse", users.editor.Token, body, &stock); status != http.StatusCreated {
+		t.Fatalf("create household stock: status %d", status)
+	}
+	products := "/api/products?stockId=" + stock.StockID.String()
+	if resp, raw := do(t, app, http.MethodGet, products, users.viewer.Token, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("housemate before the transfer: status %d: %s", resp.StatusCode, raw)
+	}
+
+	path := "/api/warehouse/" + stock.StockID.String() + "/transfer"
+	var transferred models.Warehouse
+	if status := doJSON(t, app, http.MethodPost, path, users.editor.Token, fiber.Map{"Email": users.stranger.Email}, &transferred); status != http.StatusOK {
+		t.Fatalf("transfer: status %d", status)
+	}
+	if transferred.HouseholdID != nil {
+		t.Errorf("transferred stock still in household %s", transferred.HouseholdID)
+	}
+
+	for _, role := range []string{"owner", "viewer"} {
+		if resp, raw := do(t, app, http.MethodGet, products, users.byRole(role).Token, nil); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("former housemate (%s) after the transfer: status %d, want 403: %s", role, resp.StatusCode, raw)
+		}
+	}
+	if resp, raw := do(t, app, http.MethodGet, products, users.editor.Token, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("previous owner after the transfer: status %d, want 200: %s", resp.StatusCode, raw)
+	}
+}
+
+func TestDepartingMemberTakesTheirStocks(t *testing.T) {
+	app := newApp(t)
+	users := newRoleUsers(t, app)
+
+	tests := []struct {
+		name  string
+		actor func() testUser
+	}{
+		{"removed by the owner", func() testUser { return users.owner }},
+		{"leaving", func() testUser { return users.editor }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newHouseholdFixture(t, app, users)
+
+			var stock models.Warehouse
+			body := fiber.Map{"StockName": "Editor's shelf", "HouseholdID": f.HouseholdID}
+			if status := doJSON(t, app, http.MethodPost, "/api/warehouse", users.editor.Token, body, &stock); status != http.StatusCreated {
+				t.Fatalf("create household stock: status %d", status)
+			}
+
+			path := "/api/households/" + f.HouseholdID + "/members/" + users.editor.ID.String()
+			var result struct {
+				DetachedStocks int64 `json:"detached_stocks"`
+			}
+			if status := doJSON(t, app, http.MethodDelete, path, tt.actor().Token, nil, &result); status != http.StatusOK {
+				t.Fatalf("remove member: status %d", status)
+			}
+			if result.DetachedStocks != 1 {
+				t.Errorf("detached_stocks = %d, want 1", result.DetachedStocks)
+			}
+
+			products := "/api/products?stockId=" + stock.StockID.String()
+			for _, role := range []string{"owner", "viewer"} {
+				if resp, raw := do(t, app, http.MethodGet, products, users.byRole(role).Token, nil); resp.StatusCode != http.StatusForbidden {
+					t.Errorf("remaining member (%s): status %d, want 403: %s", role, resp.StatusCode, raw)
+				}
+			}
+			if resp, raw := do(t, app, http.MethodGet, products, users.editor.Token, nil); resp.StatusCode != http.StatusOK {
+				t.Errorf("departed member: status %d, want 200: %s", resp.StatusCode, raw)
+			}
+		})
+	}
+}
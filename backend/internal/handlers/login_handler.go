@@ -3,13 +3,15 @@ package handlers
 import (
 	"context"
 	"errors"
-	"strings"
 	"time"
 
+	"my-backend/internal/auth"
 	"my-backend/internal/db"
+	"my-backend/internal/middleware"
 	"my-backend/internal/models"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
@@ -20,16 +22,28 @@ type loginRequest struct {
 	Password string `json:"Password"`
 }
 
+type loginResponse struct {
+	AccessToken      string       `json:"AccessToken"`
+	TokenType        string       `json:"TokenType"`
+	ExpiresAt        time.Time    `json:"ExpiresAt"`
+	RefreshToken     string       `json:"RefreshToken"`
+	RefreshExpiresAt time.Time    `json:"RefreshExpiresAt"`
+	SessionID        uuid.UUID    `json:"SessionID"`
+	User             models.Users `json:"User"`
+}
+
 // LoginUser godoc
 // @Summary      Login user
-// @Description  Authenticate user by email and password.
+// @Description  Authenticate user by email and password and start a session with an access and refresh token. Repeated failures lock the account and client IP with exponential backoff (429 with Retry-After). When two-factor authentication is enabled the response is a twoFactorChallengeResponse to be completed at /api/login/2fa.
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Param        payload  body      loginRequest  true  "Login credentials"
-// @Success      200  {object}  models.Users
+// @Success      200  {object}  loginResponse
 // @Failure      400  {object}  map[string]string
 // @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /api/login [post]
 func LoginUser(c *fiber.Ctx) error {
@@ -38,7 +52,7 @@ func LoginUser(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
 	}
 
-	req.Email = strings.TrimSpace(req.Email)
+	req.Email = canonicalEmail(req.Email)
 
 	if req.Email == "" || req.Password == "" {
 		return fiber.NewError(fiber.StatusBadRequest, "email and password are required")
@@ -47,6 +61,11 @@ func LoginUser(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	throttleKeys := loginThrottleKeys(c, req.Email)
+	if err := checkLoginLockout(ctx, c, throttleKeys); err != nil {
+		return err
+	}
+
 	collection, err := db.UsersCollection(ctx)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
@@ -54,15 +73,56 @@ func LoginUser(c *fiber.Ctx) error {
 
 	var user models.Users
 	if err := collection.FindOne(ctx, bson.M{"Email": req.Email}).Decode(&user); err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return fiber.NewError(fiber.StatusUnauthorized, "invalid credentials")
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch user")
 		}
-		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch user")
+		compareDummyPassword(req.Password)
+		return loginFailed(ctx, throttleKeys)
 	}
 
+	// Accounts created through OIDC have no password; they still pay for a
+	// comparison so they cannot be told apart by response time.
+	if user.PasswordHash == "" {
+		compareDummyPassword(req.Password)
+		return loginFailed(ctx, throttleKeys)
+	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return fiber.NewError(fiber.StatusUnauthorized, "invalid credentials")
+		return loginFailed(ctx, throttleKeys)
+	}
+
+	if err := middleware.AccountStatusError(user); err != nil {
+		return err
+	}
+
+	if user.TOTPEnabled {
+		challenge, expiresAt, err := auth.IssueChallengeToken(user.UserID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to issue challenge token")
+		}
+		return c.JSON(twoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+			ExpiresAt:         expiresAt,
+		})
+	}
+
+	// With two-factor enabled the counters are only reset once the second
+	// factor succeeds, otherwise re-entering the password would reset them.
+	if err := clearLoginFailures(ctx, throttleKeys); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to reset login attempts")
 	}
 
-	return c.JSON(user)
+	resp, err := startSession(ctx, c, user)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(resp)
+}
+
+func loginFailed(ctx context.Context, keys []loginThrottleKey) error {
+	if err := recordLoginFailure(ctx, keys); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to record login attempt")
+	}
+	return fiber.NewError(fiber.StatusUnauthorized, "invalid credentials")
 }
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"my-backend/internal/config"
+	"my-backend/internal/db"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	defaultAccountMaxAttempts = 5
+	defaultIPMaxAttempts      = 20
+	defaultLockoutBase        = 30 * time.Second
+	defaultLockoutMax         = time.Hour
+)
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// loginThrottleKey identifies one failed-attempt counter and the number of
+// failures it tolerates before locking.
+type loginThrottleKey struct {
+	key         string
+	maxAttempts int
+}
+
+func loginThrottleKeys(c *fiber.Ctx, email string) []loginThrottleKey {
+	return []loginThrottleKey{
+		{key: "account:" + strings.ToLower(email), maxAttempts: config.Int("LOGIN_MAX_ATTEMPTS", defaultAccountMaxAttempts)},
+		{key: "ip:" + c.IP(), maxAttempts: config.Int("LOGIN_IP_MAX_ATTEMPTS", defaultIPMaxAttempts)},
+	}
+}
+
+// inviteThrottleKeys limits guessing of household invite codes per user and
+// per client IP, with the same limits and lockouts as logins.
+func inviteThrottleKeys(c *fiber.Ctx, userID uuid.UUID) []loginThrottleKey {
+	return []loginThrottleKey{
+		{key: "invite:user:" + userID.String(), maxAttempts: config.Int("LOGIN_MAX_ATTEMPTS", defaultAccountMaxAttempts)},
+		{key: "invite:ip:" + c.IP(), maxAttempts: config.Int("LOGIN_IP_MAX_ATTEMPTS", defaultIPMaxAttempts)},
+	}
+}
+
+// checkLoginLockout returns a 429 error with Retry-After set when any of the
+// keys is currently locked.
+func checkLoginLockout(ctx context.Context, c *fiber.Ctx, keys []loginThrottleKey) error {
+	collection, err := db.LoginAttemptsCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	names := make(bson.A, 0, len(keys))
+	for _, k := range keys {
+		names = append(names, k.key)
+	}
+
+	now := time.Now().UTC()
+	cursor, err := collection.Find(ctx, bson.M{
+		"Key":         bson.M{"$in": names},
+		"LockedUntil": bson.M{"$gt": now},
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to check login attempts")
+	}
+	var locked []models.LoginAttempts
+	if err := cursor.All(ctx, &locked); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to decode login attempts")
+	}
+
+	var until time.Time
+	for _, attempt := range locked {
+		if attempt.LockedUntil != nil && attempt.LockedUntil.After(until) {
+			until = *attempt.LockedUntil
+		}
+	}
+	if until.IsZero() {
+		return nil
+	}
+
+	retryAfter := int(math.Ceil(until.Sub(now).Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return fiber.NewError(fiber.StatusTooManyRequests, "too many failed attempts; try again later")
+}
+
+// recordLoginFailure bumps every counter and locks those past their limit.
+// The lock doubles with each further failure, up to LOGIN_LOCKOUT_MAX.
+func recordLoginFailure(ctx context.Context, keys []loginThrottleKey) error {
+	collection, err := db.LoginAttemptsCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	base := config.Duration("LOGIN_LOCKOUT_BASE", defaultLockoutBase)
+	maxLock := config.Duration("LOGIN_LOCKOUT_MAX", defaultLockoutMax)
+	now := time.Now().UTC()
+
+	for _, k := range keys {
+		var attempt models.LoginAttempts
+		err := collection.FindOneAndUpdate(ctx,
+			bson.M{"Key": k.key},
+			bson.M{
+				"$inc": bson.M{"Failures": 1},
+				"$set": bson.M{"LastFailureAt": now, "UpdatedAt": now},
+			},
+			options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+		).Decode(&attempt)
+		if err != nil {
+			return err
+		}
+
+		if attempt.Failures < k.maxAttempts {
+			continue
+		}
+
+		lock := maxLock
+		if exp := attempt.Failures - k.maxAttempts; exp < 32 {
+			if d := base * time.Duration(1<<exp); d > 0 && d < maxLock {
+				lock = d
+			}
+		}
+		if _, err := collection.UpdateOne(ctx,
+			bson.M{"Key": k.key},
+			bson.M{"$set": bson.M{"LockedUntil": now.Add(lock)}},
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// clearLoginFailures resets the account (or user) counter after a success.
+// The IP counter is left to expire so one valid account cannot be used to
+// reset it while guessing others.
+func clearLoginFailures(ctx context.Context, keys []loginThrottleKey) error {
+	collection, err := db.LoginAttemptsCollection(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = collection.DeleteOne(ctx, bson.M{"Key": keys[0].key})
+	return err
+}
+
+// compareDummyPassword spends the same bcrypt work as a real comparison so
+// unknown emails cannot be told apart by response time.
+func compareDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/inventory"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	defaultExpiringDays = 7
+	maxExpiringDays     = 365
+)
+
+// expiringLot is one lot that expires within the requested window.
+type expiringLot struct {
+	ProductID   uuid.UUID `json:"ProductID"`
+	ProductName string    `json:"ProductName"`
+	Category    string    `json:"Category,omitempty"`
+	Unit        string    `json:"Unit,omitempty"`
+	LotID       uuid.UUID `json:"LotID"`
+	LotNumber   string    `json:"LotNumber,omitempty"`
+	Quantity    int       `json:"Quantity"`
+	ExpiresAt   time.Time `json:"ExpiresAt"`
+	// DaysLeft is negative for lots that have already expired.
+	DaysLeft int `json:"DaysLeft"`
+}
+
+// ListExpiring godoc
+// @Summary      List expiring lots
+// @Description  Returns the stock's lots that expire within the given number of days, including those already expired, soonest first.
+// @Tags         warehouse
+// @Produce      json
+// @Security     BearerAuth
+// @Param        stockId  path   string  true   "Stock ID (UUID)"
+// @Param        days     query  int     false  "Window in days (default 7, max 365)"
+// @Success      200  {array}   expiringLot
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/warehouse/{stockId}/expiring [get]
+func ListExpiring(c *fiber.Ctx) error {
+	stockUUID, err := stockIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	days := defaultExpiringDays
+	if raw := strings.TrimSpace(c.Query("days")); raw != "" {
+		days, err = strconv.Atoi(raw)
+		if err != nil || days < 0 || days > maxExpiringDays {
+			return fiber.NewError(fiber.StatusBadRequest, "days must be between 0 and 365")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := authorizeStock(ctx, c, stockUUID, inventory.AccessViewer); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	until := inventory.StartOfDay(now).AddDate(0, 0, days+1)
+	products, err := findAll[models.Products](ctx, db.ProductsCollection, bson.M{
+		"StockID":        stockUUID,
+		"Lots.ExpiresAt": bson.M{"$lt": until},
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch products")
+	}
+
+	return c.JSON(expiringLots(products, until, now))
+}
+
+// expiringLots flattens the lots of products that expire before until,
+// soonest first.
+func expiringLots(products []models.Products, until, now time.Time) []expiringLot {
+	today := inventory.StartOfDay(now)
+	lots := []expiringLot{}
+	for _, product := range products {
+		for _, lot := range product.Lots {
+			if lot.ExpiresAt == nil || !lot.ExpiresAt.Before(until) {
+				continue
+			}
+			lots = append(lots, expiringLot{
+				ProductID:   product.ProductID,
+				ProductName: product.ProductName,
+				Category:    product.Category,
+				Unit:        product.Unit,
+				LotID:       lot.LotID,
+				LotNumber:   lot.LotNumber,
+				Quantity:    lot.Quantity,
+				ExpiresAt:   *lot.ExpiresAt,
+				DaysLeft:    int(inventory.StartOfDay(*lot.ExpiresAt).Sub(today).Hours() / 24),
+			})
+		}
+	}
+	sort.SliceStable(lots, func(i, j int) bool {
+		return lots[i].ExpiresAt.Before(lots[j].ExpiresAt)
+	})
+	return lots
+}
+
+// planLots works out the product's lots after the movement and the change to
+// each affected lot. Incoming stock with a LotNumber or ExpiresAt goes into a
+// lot, merged with an existing one of the same number and date; other
+// incoming stock is untracked. Outgoing stock comes from in.LotID when given,
+// otherwise first-expiry-first-out: dated lots by expiry, then lots without a
+// date, then the untracked remainder.
+func planLots(product models.Products, in movementInput) ([]models.ProductLots, []models.MovementLots, error) {
+	lots := make([]models.ProductLots, len(product.Lots))
+	copy(lots, product.Lots)
+
+	if in.Delta > 0 {
+		if in.LotNumber == "" && in.ExpiresAt == nil {
+			return lots, nil, nil
+		}
+		i := -1
+		for j, lot := range lots {
+			if lot.LotNumber == in.LotNumber && sameExpiry(lot.ExpiresAt, in.ExpiresAt) {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			lots = append(lots, models.ProductLots{
+				LotID:      uuid.New(),
+				LotNumber:  in.LotNumber,
+				ExpiresAt:  in.ExpiresAt,
+				ReceivedAt: time.Now().UTC(),
+			})
+			i = len(lots) - 1
+		}
+		lots[i].Quantity += in.Delta
+		return lots, []models.MovementLots{lotChange(lots[i], in.Delta)}, nil
+	}
+
+	remaining := -in.Delta
+	if in.LotID != nil {
+		for i := range lots {
+			if lots[i].LotID != *in.LotID {
+				continue
+			}
+			if lots[i].Quantity < remaining {
+				return nil, nil, fiber.NewError(fiber.StatusConflict, "not enough quantity in this lot")
+			}
+			lots[i].Quantity -= remaining
+			change := lotChange(lots[i], -remaining)
+			return withoutEmptyLots(lots), []models.MovementLots{change}, nil
+		}
+		return nil, nil, fiber.NewError(fiber.StatusNotFound, "lot not found")
+	}
+
+	order := make([]int, len(lots))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		la, lb := lots[order[a]], lots[order[b]]
+		if (la.ExpiresAt == nil) != (lb.ExpiresAt == nil) {
+			return la.ExpiresAt != nil
+		}
+		if la.ExpiresAt != nil && !la.ExpiresAt.Equal(*lb.ExpiresAt) {
+			return la.ExpiresAt.Before(*lb.ExpiresAt)
+		}
+		return la.ReceivedAt.Before(lb.ReceivedAt)
+	})
+
+	var changes []models.MovementLots
+	for _, i := range order {
+		if remaining == 0 {
+			break
+		}
+		take := min(lots[i].Quantity, remaining)
+		if take == 0 {
+			continue
+		}
+		lots[i].Quantity -= take
+		remaining -= take
+		changes = append(changes, lotChange(lots[i], -take))
+	}
+	// Whatever is left comes out of the untracked quantity; the caller has
+	// already checked that ProductQty covers the whole movement.
+	return withoutEmptyLots(lots), changes, nil
+}
+
+func lotChange(lot models.ProductLots, delta int) models.MovementLots {
+	return models.MovementLots{LotID: lot.LotID, LotNumber: lot.LotNumber, ExpiresAt: lot.ExpiresAt, Delta: delta}
+}
+
+func withoutEmptyLots(lots []models.ProductLots) []models.ProductLots {
+	kept := lots[:0]
+	for _, lot := range lots {
+		if lot.Quantity > 0 {
+			kept = append(kept, lot)
+		}
+	}
+	return kept
+}
+
+func sameExpiry(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// parseExpiry accepts a date (2006-01-02) or an RFC 3339 timestamp.
+func parseExpiry(raw string) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "ExpiresAt must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+	}
+	t = t.UTC()
+	return &t, nil
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestPlanLotsOutgoing(t *testing.T) {
+	day := func(d int) *time.Time {
+		at := time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC)
+		return &at
+	}
+	received := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	early := models.ProductLots{LotID: uuid.New(), LotNumber: "A", Quantity: 3, ExpiresAt: day(1), ReceivedAt: received}
+	late := models.ProductLots{LotID: uuid.New(), LotNumber: "B", Quantity: 4, ExpiresAt: day(20), ReceivedAt: received}
+	undated := models.ProductLots{LotID: uuid.New(), LotNumber: "C", Quantity: 2, ReceivedAt: received}
+
+	withQty := func(lot models.ProductLots, qty int) models.ProductLots {
+		lot.Quantity = qty
+		return lot
+	}
+	change := func(lot models.ProductLots, delta int) models.MovementLots {
+		return models.MovementLots{LotID: lot.LotID, LotNumber: lot.LotNumber, ExpiresAt: lot.ExpiresAt, Delta: delta}
+	}
+
+	tests := []struct {
+		name        string
+		lots        []models.ProductLots
+		in          movementInput
+		wantLots    []models.ProductLots
+		wantChanges []models.MovementLots
+		wantStatus  int
+	}{
+		{
+			name:        "partial lot, earliest expiry first",
+			lots:        []models.ProductLots{late, undated, early},
+			in:          movementInput{Delta: -2},
+			wantLots:    []models.ProductLots{late, undated, withQty(early, 1)},
+			wantChanges: []models.MovementLots{change(early, -2)},
+		},
+		{
+			name:        "draining a lot removes it",
+			lots:        []models.ProductLots{late, early},
+			in:          movementInput{Delta: -3},
+			wantLots:    []models.ProductLots{late},
+			wantChanges: []models.MovementLots{change(early, -3)},
+		},
+		{
+			name:        "spanning lots, undated last",
+			lots:        []models.ProductLots{undated, late, early},
+			in:          movementInput{Delta: -8},
+			wantLots:    []models.ProductLots{withQty(undated, 1)},
+			wantChanges: []models.MovementLots{change(early, -3), change(late, -4), change(undated, -1)},
+		},
+		{
+			name:        "more than the lots hold comes from untracked stock",
+			lots:        []models.ProductLots{early},
+			in:          movementInput{Delta: -5},
+			wantLots:    []models.ProductLots{},
+			wantChanges: []models.MovementLots{change(early, -3)},
+		},
+		{
+			name:     "stock without lots",
+			lots:     nil,
+			in:       movementInput{Delta: -1},
+			wantLots: []models.ProductLots{},
+		},
+		{
+			name:        "explicit lot",
+			lots:        []models.ProductLots{early, late},
+			in:          movementInput{Delta: -4, LotID: &late.LotID},
+			wantLots:    []models.ProductLots{early},
+			wantChanges: []models.MovementLots{change(late, -4)},
+		},
+		{
+			name:       "explicit lot with too little quantity",
+			lots:       []models.ProductLots{early, late},
+			in:         movementInput{Delta: -5, LotID: &late.LotID},
+			wantStatus: fiber.StatusConflict,
+		},
+		{
+			name:       "unknown explicit lot",
+			lots:       []models.ProductLots{early},
+			in:         movementInput{Delta: -1, LotID: &undated.LotID},
+			wantStatus: fiber.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := models.Products{Lots: tt.lots}
+			original := append([]models.ProductLots(nil), tt.lots...)
+
+			lots, changes, err := planLots(product, tt.in)
+			if tt.wantStatus != 0 {
+				var fe *fiber.Error
+				if !errors.As(err, &fe) || fe.Code != tt.wantStatus {
+					t.Fatalf("err = %v, want status %d", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(lots, tt.wantLots) {
+				t.Errorf("lots = %+v, want %+v", lots, tt.wantLots)
+			}
+			if !reflect.DeepEqual(changes, tt.wantChanges) {
+				t.Errorf("changes = %+v, want %+v", changes, tt.wantChanges)
+			}
+			if !reflect.DeepEqual(product.Lots, original) {
+				t.Errorf("product lots were modified: %+v", product.Lots)
+			}
+		})
+	}
+}
+
+func TestPlanLotsIncoming(t *testing.T) {
+	expiry := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	sameDay := expiry
+	existing := models.ProductLots{LotID: uuid.New(), LotNumber: "A", Quantity: 3, ExpiresAt: &expiry}
+
+	t.Run("untracked", func(t *testing.T) {
+		lots, changes, err := planLots(models.Products{Lots: []models.ProductLots{existing}}, movementInput{Delta: 2})
+		if err != nil || len(changes) != 0 || len(lots) != 1 || lots[0].Quantity != 3 {
+			t.Errorf("got lots %+v, changes %+v, err %v", lots, changes, err)
+		}
+	})
+
+	t.Run("matching lot", func(t *testing.T) {
+		lots, changes, err := planLots(models.Products{Lots: []models.ProductLots{existing}}, movementInput{Delta: 2, LotNumber: "A", ExpiresAt: &sameDay})
+		if err != nil || len(lots) != 1 || lots[0].Quantity != 5 {
+			t.Fatalf("got lots %+v, err %v", lots, err)
+		}
+		if len(changes) != 1 || changes[0].LotID != existing.LotID || changes[0].Delta != 2 {
+			t.Errorf("changes = %+v", changes)
+		}
+	})
+
+	t.Run("new lot", func(t *testing.T) {
+		lots, changes, err := planLots(models.Products{Lots: []models.ProductLots{existing}}, movementInput{Delta: 2, LotNumber: "B"})
+		if err != nil || len(lots) != 2 {
+			t.Fatalf("got lots %+v, err %v", lots, err)
+		}
+		added := lots[1]
+		if added.LotID == uuid.Nil || added.LotNumber != "B" || added.Quantity != 2 || added.ExpiresAt != nil {
+			t.Errorf("new lot = %+v", added)
+		}
+		if len(changes) != 1 || changes[0].LotID != added.LotID || changes[0].Delta != 2 {
+			t.Errorf("changes = %+v", changes)
+		}
+	})
+}
@@ -0,0 +1,197 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/models"
+	"my-backend/internal/routes"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "correct horse battery staple"
+
+// The handler tests run the real routes against the MongoDB server in
+// MONGO_URL, in a scratch database that is dropped afterwards. Without
+// MONGO_URL they are skipped.
+func TestMain(m *testing.M) {
+	if os.Getenv("MONGO_URL") == "" {
+		os.Exit(m.Run())
+	}
+
+	name := "warehouse_test_" + uuid.NewString()[:8]
+	os.Setenv("MONGO_DB_NAME", name)
+	os.Setenv("MAIL_DRIVER", "log")
+	if os.Getenv("JWT_SECRET") == "" {
+		os.Setenv("JWT_SECRET", "handler-tests")
+	}
+
+	code := m.Run()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if client, err := db.Client(ctx); err == nil {
+		if err := client.Database(name).Drop(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "dropping %s: %v\n", name, err)
+		}
+	}
+	os.Exit(code)
+}
+
+// newApp returns the API with every route registered, or skips the test when
+// there is no database to run it against.
+func newApp(t *testing.T) *fiber.App {
+	t.Helper()
+	if os.Getenv("MONGO_URL") == "" {
+		t.Skip("MONGO_URL is not set; handler tests need a MongoDB server")
+	}
+	app := fiber.New()
+	routes.RegisterRoutes(app)
+	return app
+}
+
+type testUser struct {
+	ID    uuid.UUID
+	Email string
+	Token string
+}
+
+// insertUser stores an account with testPassword directly, bypassing
+// registration and email verification.
+func insertUser(t *testing.T, user models.Users) models.Users {
+	t.Helper()
+	ctx := context.Background()
+
+	if user.UserID == "" {
+		user.UserID = uuid.NewString()
+	}
+	if user.Email == "" {
+		user.Email = "user-" + user.UserID[:8] + "@example.com"
+	}
+	if user.Status == "" {
+		user.Status = models.UserStatusActive
+	}
+	if user.PasswordHash == "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		user.PasswordHash = string(hash)
+	}
+	user.CreatedAt = time.Now().UTC()
+
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := collection.InsertOne(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// newUser creates an active account and signs it in.
+func newUser(t *testing.T, app *fiber.App) testUser {
+	t.Helper()
+	user := insertUser(t, models.Users{})
+
+	var login struct {
+		AccessToken string `json:"AccessToken"`
+	}
+	status := doJSON(t, app, http.MethodPost, "/api/login", "", fiber.Map{"Email": user.Email, "Password": testPassword}, &login)
+	if status != http.StatusOK || login.AccessToken == "" {
+		t.Fatalf("login as %s: status %d", user.Email, status)
+	}
+	return testUser{ID: uuid.MustParse(user.UserID), Email: user.Email, Token: login.AccessToken}
+}
+
+// roleUsers are the signed-in accounts of the access tests: an owner, an
+// editor and a viewer, a bystander who holds the same access as the viewer
+// and a stranger who holds none.
+type roleUsers struct {
+	owner, editor, viewer, bystander, stranger testUser
+}
+
+func newRoleUsers(t *testing.T, app *fiber.App) roleUsers {
+	t.Helper()
+	return roleUsers{
+		owner:     newUser(t, app),
+		editor:    newUser(t, app),
+		viewer:    newUser(t, app),
+		bystander: newUser(t, app),
+		stranger:  newUser(t, app),
+	}
+}
+
+// byRole returns the user for role, and the stranger for any other name.
+func (u roleUsers) byRole(role string) testUser {
+	switch role {
+	case "owner":
+		return u.owner
+	case "editor":
+		return u.editor
+	case "viewer":
+		return u.viewer
+	case "bystander":
+		return u.bystander
+	default:
+		return u.stranger
+	}
+}
+
+// do sends a request to app, with body encoded as JSON when it is not nil.
+func do(t *testing.T, app *fiber.App, method, path, token string, body interface{}) (*http.Response, []byte) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(raw)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, raw
+}
+
+// doJSON is do that decodes a successful response into out, when given, and
+// returns the status code.
+func doJSON(t *testing.T, app *fiber.App, method, path, token string, body, out interface{}) int {
+	t.Helper()
+	resp, raw := do(t, app, method, path, token, body)
+	if out != nil && resp.StatusCode < 300 {
+		if err := json.Unmarshal(raw, out); err != nil {
+			t.Fatalf("%s %s: decoding %s: %v", method, path, raw, err)
+		}
+	}
+	return resp.StatusCode
+}
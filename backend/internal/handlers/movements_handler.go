@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"my-backend/internal/db"
+	"my-backend/internal/inventory"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	maxMovementReasonLength = 100
+	maxMovementNoteLength   = 1000
+)
+
+type createMovementRequest struct {
+	Type      string `json:"Type"`
+	Quantity  int    `json:"Quantity"`
+	Reason    string `json:"Reason"`
+	Note      string `json:"Note"`
+	LotNumber string `json:"LotNumber"`
+	ExpiresAt string `json:"ExpiresAt"`
+	LotID     string `json:"LotID"`
+}
+
+type movementResponse struct {
+	Movement models.StockMovements `json:"Movement"`
+	Product  models.Products       `json:"Product"`
+}
+
+// CreateMovement godoc
+// @Summary      Record a stock movement
+// @Description  Changes a product's quantity by a movement. IN, OUT and WASTE take a positive Quantity; ADJUST takes a signed one. WASTE records stock that was thrown away and counts towards the waste report. Incoming stock with a LotNumber or ExpiresAt is kept as a lot. Outgoing stock is taken from LotID when given, otherwise from the earliest-expiring lots first. The change is applied atomically, so concurrent movements never lose updates, and a movement that would take the quantity below zero is rejected.
+// @Tags         products
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        productId  path      string                 true  "Product ID (UUID)"
+// @Param        payload    body      createMovementRequest  true  "Movement"
+// @Success      201  {object}  movementResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/products/{productId}/movements [post]
+func CreateMovement(c *fiber.Ctx) error {
+	productUUID, err := productIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	var req createMovementRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	movementType := strings.ToUpper(strings.TrimSpace(req.Type))
+	delta, err := movementDelta(movementType, req.Quantity)
+	if err != nil {
+		return err
+	}
+
+	in := movementInput{
+		Type:      movementType,
+		Delta:     delta,
+		Reason:    strings.TrimSpace(req.Reason),
+		Note:      strings.TrimSpace(req.Note),
+		LotNumber: strings.TrimSpace(req.LotNumber),
+	}
+	if utf8.RuneCountInString(in.Reason) > maxMovementReasonLength {
+		return fiber.NewError(fiber.StatusBadRequest, "Reason must be at most 100 characters")
+	}
+	if utf8.RuneCountInString(in.Note) > maxMovementNoteLength {
+		return fiber.NewError(fiber.StatusBadRequest, "Note must be at most 1000 characters")
+	}
+	if in.ExpiresAt, err = parseExpiry(req.ExpiresAt); err != nil {
+		return err
+	}
+	if lotIDRaw := strings.TrimSpace(req.LotID); lotIDRaw != "" {
+		lotUUID, err := uuid.Parse(lotIDRaw)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "LotID must be a valid UUID")
+		}
+		in.LotID = &lotUUID
+	}
+	if delta > 0 && in.LotID != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "LotID only applies to outgoing movements")
+	}
+	if delta < 0 && (in.LotNumber != "" || in.ExpiresAt != nil) {
+		return fiber.NewError(fiber.StatusBadRequest, "LotNumber and ExpiresAt only apply to incoming movements")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := authorizeProduct(ctx, c, productUUID, inventory.AccessEditor); err != nil {
+		return err
+	}
+
+	movement, product, err := applyMovement(ctx, c, productUUID, in)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(movementResponse{Movement: movement, Product: product})
+}
+
+// ListMovements godoc
+// @Summary      List a product's movements
+// @Description  Returns the product's quantity ledger, newest first. The total number of movements is sent in X-Total-Count.
+// @Tags         products
+// @Produce      json
+// @Security     BearerAuth
+// @Param        productId  path   string  true   "Product ID (UUID)"
+// @Param        page       query  int     false  "Page number, starting at 1"
+// @Param        limit      query  int     false  "Entries per page (default 50, max 200)"
+// @Success      200  {array}   models.StockMovements
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/products/{productId}/movements [get]
+func ListMovements(c *fiber.Ctx) error {
+	productUUID, err := productIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	page, limit, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := authorizeProduct(ctx, c, productUUID, inventory.AccessViewer); err != nil {
+		return err
+	}
+
+	collection, err := db.StockMovementsCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	filter := bson.M{"ProductID": productUUID}
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to count movements")
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "CreatedAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip((page - 1) * limit).
+		SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch movements")
+	}
+	defer cursor.Close(ctx)
+
+	movements := []models.StockMovements{}
+	if err := cursor.All(ctx, &movements); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to decode movements")
+	}
+
+	c.Set("X-Total-Count", strconv.FormatInt(total, 10))
+	return c.JSON(movements)
+}
+
+// movementDelta validates a movement and returns the signed change it makes.
+func movementDelta(movementType string, quantity int) (int, error) {
+	switch movementType {
+	case models.MovementTypeIn, models.MovementTypeOut, models.MovementTypeWaste:
+		if quantity <= 0 {
+			return 0, fiber.NewError(fiber.StatusBadRequest, "Quantity must be positive for IN, OUT and WASTE")
+		}
+		if movementType == models.MovementTypeIn {
+			return quantity, nil
+		}
+		return -quantity, nil
+	case models.MovementTypeAdjust:
+		if quantity == 0 {
+			return 0, fiber.NewError(fiber.StatusBadRequest, "Quantity must not be zero")
+		}
+		return quantity, nil
+	default:
+		return 0, fiber.NewError(fiber.StatusBadRequest, "Type must be IN, OUT, WASTE or ADJUST")
+	}
+}
+
+// maxMovementAttempts bounds the retries of a movement that keeps losing the
+// race against concurrent changes to the same product.
+const maxMovementAttempts = 5
+
+// movementInput is a quantity change before it is applied.
+type movementInput struct {
+	Type   string
+	Delta  int
+	Reason string
+	Note   string
+	// LotNumber and ExpiresAt put incoming stock into a lot; LotID takes
+	// outgoing stock from that lot instead of first-expiry-first-out.
+	LotNumber string
+	ExpiresAt *time.Time
+	LotID     *uuid.UUID
+}
+
+// applyMovement changes the product's quantity and lots by the movement and
+// records it. The update only applies if the product's Revision is still the
+// one the lots were planned from, so concurrent movements are retried rather
+// than lost, and the quantity can never go below zero.
+func applyMovement(ctx context.Context, c *fiber.Ctx, productID uuid.UUID, in movementInput) (models.StockMovements, models.Products, error) {
+	collection, err := db.ProductsCollection(ctx)
+	if err != nil {
+		return models.StockMovements{}, models.Products{}, fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	for attempt := 0; attempt < maxMovementAttempts; attempt++ {
+		var current models.Products
+		if err := collection.FindOne(ctx, bson.M{"ProductID": productID}).Decode(&current); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return models.StockMovements{}, models.Products{}, fiber.NewError(fiber.StatusNotFound, "product not found")
+			}
+			return models.StockMovements{}, models.Products{}, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch product")
+		}
+		if current.ProductQty+in.Delta < 0 {
+			return models.StockMovements{}, models.Products{}, fiber.NewError(fiber.StatusConflict, "not enough quantity in stock")
+		}
+
+		lots, lotChanges, err := planLots(current, in)
+		if err != nil {
+			return models.StockMovements{}, models.Products{}, err
+		}
+
+		set := bson.M{"UpdatedAt": time.Now().UTC()}
+		update := bson.M{"$inc": bson.M{"ProductQty": in.Delta, "Revision": 1}, "$set": set}
+		if lotChanges != nil {
+			if len(lots) == 0 {
+				update["$unset"] = bson.M{"Lots": ""}
+			} else {
+				set["Lots"] = lots
+			}
+		}
+
+		var product models.Products
+		err = collection.FindOneAndUpdate(ctx,
+			bson.M{"ProductID": productID, "Revision": inventory.RevisionFilter(current.Revision)},
+			update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&product)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return models.StockMovements{}, models.Products{}, fiber.NewError(fiber.StatusInternalServerError, "failed to update quantity")
+		}
+
+		movement, err := recordMovement(ctx, c, product, in, lotChanges)
+		if err != nil {
+			return models.StockMovements{}, models.Products{}, err
+		}
+		checkLowStock(ctx, product)
+		return movement, product, nil
+	}
+
+	return models.StockMovements{}, models.Products{}, fiber.NewError(fiber.StatusConflict, "the product is being changed concurrently; retry")
+}
+
+// recordMovement appends a movement for a quantity change that has already
+// been applied to product.
+func recordMovement(ctx context.Context, c *fiber.Ctx, product models.Products, in movementInput, lots []models.MovementLots) (models.StockMovements, error) {
+	actorID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return models.StockMovements{}, err
+	}
+
+	movement := models.StockMovements{
+		MovementID: uuid.New(),
+		ProductID:  product.ProductID,
+		StockID:    product.StockID,
+		Type:       in.Type,
+		Delta:      in.Delta,
+		QtyAfter:   product.ProductQty,
+		Reason:     in.Reason,
+		Note:       in.Note,
+		ActorID:    actorID,
+		Lots:       lots,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if key, ok := middleware.CurrentAPIKey(c); ok {
+		movement.APIKeyID = &key.KeyID
+	}
+
+	collection, err := db.StockMovementsCollection(ctx)
+	if err != nil {
+		return models.StockMovements{}, fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	if _, err := collection.InsertOne(ctx, movement); err != nil {
+		return models.StockMovements{}, fiber.NewError(fiber.StatusInternalServerError, "failed to record movement")
+	}
+	recordAudit(ctx, c, models.AuditActionCreate, models.AuditEntityStockMovement, movement.MovementID.String(), movement.StockID, nil, movement)
+
+	return movement, nil
+}
+
+func productIDParam(c *fiber.Ctx) (uuid.UUID, error) {
+	productIDParam := strings.TrimSpace(c.Params("productId"))
+	if productIDParam == "" {
+		return uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "productId is required")
+	}
+
+	productUUID, err := uuid.Parse(productIDParam)
+	if err != nil {
+		return uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "productId must be a valid UUID")
+	}
+	return productUUID, nil
+}
@@ -0,0 +1,118 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"my-backend/internal/db"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDeletingKeepsTheLedger(t *testing.T) {
+	app := newApp(t)
+	owner := newUser(t, app)
+
+	var stock models.Warehouse
+	if status := doJSON(t, app, http.MethodPost, "/api/warehouse", owner.Token, fiber.Map{"StockName": "Pantry"}, &stock); status != http.StatusCreated {
+		t.Fatalf("create stock: status %d", status)
+	}
+	var product models.Products
+	body := fiber.Map{"StockID": stock.StockID.String(), "ProductName": "Milk", "ProductQty": 5}
+	if status := doJSON(t, app, http.MethodPost, "/api/products", owner.Token, body, &product); status != http.StatusCreated {
+		t.Fatalf("create product: status %d", status)
+	}
+	movements := "/api/products/" + product.ProductID.String() + "/movements"
+	if status := doJSON(t, app, http.MethodPost, movements, owner.Token, fiber.Map{"Type": models.MovementTypeWaste, "Quantity": 2}, nil); status != http.StatusCreated {
+		t.Fatalf("record waste: status %d", status)
+	}
+
+	if status := doJSON(t, app, http.MethodDelete, "/api/products/"+product.ProductID.String(), owner.Token, nil, nil); status != http.StatusOK {
+		t.Fatalf("delete product: status %d", status)
+	}
+	var report struct {
+		TotalQty int `json:"TotalQty"`
+	}
+	path := "/api/warehouse/" + stock.StockID.String() + "/waste-report"
+	if status := doJSON(t, app, http.MethodGet, path, owner.Token, nil, &report); status != http.StatusOK {
+		t.Fatalf("waste report: status %d", status)
+	}
+	if report.TotalQty != 2 {
+		t.Errorf("waste report after deleting the product: TotalQty %d, want 2", report.TotalQty)
+	}
+
+	if status := doJSON(t, app, http.MethodDelete, "/api/warehouse/"+stock.StockID.String(), owner.Token, nil, nil); status != http.StatusOK {
+		t.Fatalf("delete stock: status %d", status)
+	}
+	ctx := context.Background()
+	collection, err := db.StockMovementsCollection(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, err := collection.CountDocuments(ctx, bson.M{"StockID": stock.StockID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept == 0 {
+		t.Error("deleting the stock removed its movements")
+	}
+
+	if status := doJSON(t, app, http.MethodDelete, "/api/me", owner.Token, fiber.Map{"Password": testPassword}, nil); status != http.StatusOK {
+		t.Fatalf("delete account: status %d", status)
+	}
+	if left, err := collection.CountDocuments(ctx, bson.M{"StockID": stock.StockID}); err != nil || left != 0 {
+		t.Errorf("after deleting the account: %d movements left (%v), want 0", left, err)
+	}
+}
+
+func TestDeletedAccountLeavesPseudonymousHistory(t *testing.T) {
+	app := newApp(t)
+	owner := newUser(t, app)
+	editor := newUser(t, app)
+
+	var stock models.Warehouse
+	if status := doJSON(t, app, http.MethodPost, "/api/warehouse", owner.Token, fiber.Map{"StockName": "Pantry"}, &stock); status != http.StatusCreated {
+		t.Fatalf("create stock: status %d", status)
+	}
+	shares := "/api/warehouse/" + stock.StockID.String() + "/shares"
+	if status := doJSON(t, app, http.MethodPost, shares, owner.Token, fiber.Map{"Email": editor.Email, "Role": "editor"}, nil); status != http.StatusOK {
+		t.Fatalf("share stock: status %d", status)
+	}
+	var product models.Products
+	body := fiber.Map{"StockID": stock.StockID.String(), "ProductName": "Milk", "ProductQty": 5}
+	if status := doJSON(t, app, http.MethodPost, "/api/products", owner.Token, body, &product); status != http.StatusCreated {
+		t.Fatalf("create product: status %d", status)
+	}
+	movements := "/api/products/" + product.ProductID.String() + "/movements"
+	if status := doJSON(t, app, http.MethodPost, movements, editor.Token, fiber.Map{"Type": models.MovementTypeOut, "Quantity": 1}, nil); status != http.StatusCreated {
+		t.Fatalf("record movement: status %d", status)
+	}
+
+	if status := doJSON(t, app, http.MethodDelete, "/api/me", editor.Token, fiber.Map{"Password": testPassword}, nil); status != http.StatusOK {
+		t.Fatalf("delete account: status %d", status)
+	}
+
+	ctx := context.Background()
+	movementsCol, err := db.StockMovementsCollection(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := movementsCol.CountDocuments(ctx, bson.M{"StockID": stock.StockID, "ActorID": editor.ID}); err != nil || n != 0 {
+		t.Errorf("%d movements still name the deleted editor (%v)", n, err)
+	}
+	if n, err := movementsCol.CountDocuments(ctx, bson.M{"StockID": stock.StockID, "Type": models.MovementTypeOut}); err != nil || n != 1 {
+		t.Errorf("%d of the editor's movements kept in the owner's stock (%v), want 1", n, err)
+	}
+
+	auditCol, err := db.AuditLogCollection(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared := bson.M{"EntityType": models.AuditEntityStockShare, "StockID": stock.StockID, "After.UserID": editor.ID.String()}
+	if n, err := auditCol.CountDocuments(ctx, shared); err != nil || n != 0 {
+		t.Errorf("%d share snapshots still name the deleted editor (%v)", n, err)
+	}
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/inventory"
+	mailer "my-backend/internal/mail"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+	"my-backend/internal/notify"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const maxNotificationChannels = 5
+
+type notificationSettingsRequest struct {
+	Channels   []notificationChannelRequest `json:"Channels"`
+	QuietHours *models.QuietHours           `json:"QuietHours"`
+}
+
+type notificationChannelRequest struct {
+	Type   string `json:"Type"`
+	URL    string `json:"URL"`
+	Secret string `json:"Secret"`
+}
+
+// GetNotificationSettings godoc
+// @Summary      Get notification settings
+// @Description  Returns how the authenticated user is notified about the stock. Channel secrets are never returned.
+// @Tags         notifications
+// @Produce      json
+// @Security     BearerAuth
+// @Param        stockId  path  string  true  "Stock ID (UUID)"
+// @Success      200  {object}  models.NotificationSettings
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/warehouse/{stockId}/notifications [get]
+func GetNotificationSettings(c *fiber.Ctx) error {
+	stockUUID, err := stockIDParam(c)
+	if err != nil {
+		return err
+	}
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := authorizeStock(ctx, c, stockUUID, inventory.AccessViewer); err != nil {
+		return err
+	}
+
+	settings, err := notificationSettings(ctx, stockUUID, userUUID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch notification settings")
+	}
+	return c.JSON(settings)
+}
+
+// UpdateNotificationSettings godoc
+// @Summary      Update notification settings
+// @Description  Replaces the authenticated user's notification channels and quiet hours for the stock. Channels are email (to the account's address), webhook (JSON POST, signed with Secret when given) and ntfy (topic URL, Secret is an access token). A channel sent without Secret keeps the secret stored for the same Type and URL. Notifications due during quiet hours are delivered when they end. Anyone who can view the stock can subscribe; webhook and ntfy URLs must point to public hosts.
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        stockId  path      string                       true  "Stock ID (UUID)"
+// @Param        payload  body      notificationSettingsRequest  true  "Channels and quiet hours"
+// @Success      200  {object}  models.NotificationSettings
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/warehouse/{stockId}/notifications [put]
+func UpdateNotificationSettings(c *fiber.Ctx) error {
+	stockUUID, err := stockIDParam(c)
+	if err != nil {
+		return err
+	}
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req notificationSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+	if len(req.Channels) > maxNotificationChannels {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("at most %d channels are allowed", maxNotificationChannels))
+	}
+	if req.QuietHours != nil {
+		if err := notify.ValidateQuietHours(*req.QuietHours); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "QuietHours."+err.Error())
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := authorizeStock(ctx, c, stockUUID, inventory.AccessViewer); err != nil {
+		return err
+	}
+
+	previous, err := notificationSettings(ctx, stockUUID, userUUID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch notification settings")
+	}
+
+	channels := make([]models.NotificationChannel, 0, len(req.Channels))
+	for i, raw := range req.Channels {
+		ch := models.NotificationChannel{Type: raw.Type, URL: raw.URL, Secret: raw.Secret}
+		if ch.Type == models.NotificationChannelEmail {
+			ch.URL, ch.Secret = "", ""
+		}
+		if err := notify.ValidateChannel(ctx, ch); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Channels[%d]: %v", i, err))
+		}
+		if ch.Secret == "" {
+			for _, old := range previous.Channels {
+				if old.Type == ch.Type && old.URL == ch.URL {
+					ch.Secret = old.Secret
+				}
+			}
+		}
+		channels = append(channels, ch)
+	}
+
+	collection, err := db.NotificationSettingsCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	set := bson.M{"Channels": channels, "UpdatedAt": time.Now().UTC()}
+	update := bson.M{"$set": set}
+	if req.QuietHours != nil {
+		set["QuietHours"] = req.QuietHours
+	} else {
+		update["$unset"] = bson.M{"QuietHours": ""}
+	}
+
+	var settings models.NotificationSettings
+	err = collection.FindOneAndUpdate(ctx,
+		bson.M{"StockID": stockUUID, "UserID": userUUID},
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&settings)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to update notification settings")
+	}
+
+	recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntityNotificationSettings, userUUID.String(), stockUUID,
+		auditNotificationSettings(previous), auditNotificationSettings(settings))
+
+	return c.JSON(settings)
+}
+
+// notificationSettings returns the user's settings for the stock, or empty
+// settings when none are stored.
+func notificationSettings(ctx context.Context, stockID, userID uuid.UUID) (models.NotificationSettings, error) {
+	collection, err := db.NotificationSettingsCollection(ctx)
+	if err != nil {
+		return models.NotificationSettings{}, err
+	}
+
+	var settings models.NotificationSettings
+	err = collection.FindOne(ctx, bson.M{"StockID": stockID, "UserID": userID}).Decode(&settings)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.NotificationSettings{StockID: stockID, UserID: userID, Channels: []models.NotificationChannel{}}, nil
+	}
+	return settings, err
+}
+
+// auditNotificationSettings is what the audit log keeps of notification
+// settings. Everyone with access to the stock can read its audit log, so
+// channel URLs, which often act as secrets themselves, are reduced to their
+// host.
+func auditNotificationSettings(settings models.NotificationSettings) fiber.Map {
+	channels := make([]fiber.Map, 0, len(settings.Channels))
+	for _, ch := range settings.Channels {
+		entry := fiber.Map{"Type": ch.Type}
+		if u, err := url.Parse(ch.URL); err == nil && u.Host != "" {
+			entry["Host"] = u.Host
+		}
+		channels = append(channels, entry)
+	}
+	return fiber.Map{"Channels": channels, "QuietHours": settings.QuietHours}
+}
+
+// checkLowStock notifies the stock's subscribers when product has just
+// dropped to or below its MinQty, and re-arms the alert once it is back
+// above. LowStockNotifiedAt is flipped with a conditional update, so each
+// shortage is reported exactly once even under concurrent movements. Errors
+// are logged: the quantity change that triggered the check has already
+// happened.
+func checkLowStock(ctx context.Context, product models.Products) {
+	collection, err := db.ProductsCollection(ctx)
+	if err != nil {
+		log.Printf("low stock: %v", err)
+		return
+	}
+
+	if !product.IsLowStock() {
+		if product.LowStockNotifiedAt == nil {
+			return
+		}
+		_, err := collection.UpdateOne(ctx,
+			bson.M{
+				"ProductID":          product.ProductID,
+				"LowStockNotifiedAt": bson.M{"$ne": nil},
+				"$or": bson.A{
+					bson.M{"MinQty": nil},
+					bson.M{"$expr": bson.M{"$gt": bson.A{"$ProductQty", "$MinQty"}}},
+				},
+			},
+			bson.M{"$unset": bson.M{"LowStockNotifiedAt": ""}},
+		)
+		if err != nil {
+			log.Printf("low stock: failed to re-arm alert for %s: %v", product.ProductID, err)
+		}
+		return
+	}
+
+	res, err := collection.UpdateOne(ctx,
+		bson.M{
+			"ProductID":          product.ProductID,
+			"LowStockNotifiedAt": nil,
+			"MinQty":             bson.M{"$ne": nil},
+			"$expr":              bson.M{"$lte": bson.A{"$ProductQty", "$MinQty"}},
+		},
+		bson.M{"$set": bson.M{"LowStockNotifiedAt": time.Now().UTC()}},
+	)
+	if err != nil {
+		log.Printf("low stock: failed to flag %s: %v", product.ProductID, err)
+		return
+	}
+	if res.ModifiedCount == 0 {
+		return
+	}
+
+	if err := inventory.NotifySubscribers(ctx, product.StockID, func(stock models.Warehouse) notify.Notification {
+		return notify.Notification{
+			Kind:  models.NotificationKindLowStock,
+			Title: "Low stock: " + product.ProductName,
+			Message: fmt.Sprintf("%s in %s is down to %s (minimum %d). Suggested reorder: %d.",
+				product.ProductName, stock.StockName, inventory.FormatQty(product.ProductQty, product.Unit), *product.MinQty, product.SuggestedReorderQty()),
+			Link: mailer.AppURL("/warehouse/" + stock.StockID.String()),
+			Data: map[string]string{
+				"stockId":   stock.StockID.String(),
+				"productId": product.ProductID.String(),
+			},
+		}
+	}); err != nil {
+		log.Printf("low stock: failed to queue notifications for %s: %v", product.ProductID, err)
+	}
+}
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"my-backend/internal/auth"
+	"my-backend/internal/db"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+	"my-backend/internal/oidc"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// oidcStateTTL bounds how long a user may spend at the provider before the
+// callback is rejected.
+const oidcStateTTL = 10 * time.Minute
+
+// OIDCLogin godoc
+// @Summary      Start OpenID Connect login
+// @Description  Redirects the browser to the configured identity provider using the authorization code flow with PKCE.
+// @Tags         users
+// @Success      302
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Failure      502  {object}  map[string]string
+// @Router       /api/oidc/login [get]
+func OIDCLogin(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	provider, err := oidcProvider(ctx)
+	if err != nil {
+		return err
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to generate state")
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to generate nonce")
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to generate code verifier")
+	}
+
+	collection, err := db.OIDCStatesCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	now := time.Now().UTC()
+	pending := models.OIDCStates{
+		StateHash:    auth.HashOpaqueToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(oidcStateTTL),
+	}
+	if _, err := collection.InsertOne(ctx, pending); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to store login state")
+	}
+
+	return c.Redirect(provider.AuthCodeURL(state, nonce, challenge), fiber.StatusFound)
+}
+
+// OIDCCallback godoc
+// @Summary      Finish OpenID Connect login
+// @Description  Redirect target of the identity provider. Exchanges the code, verifies the ID token and signs in the account with the same verified email, linking it on first use or creating it when none exists. Responds like /api/login, or redirects to OIDC_POST_LOGIN_REDIRECT with the tokens in the URL fragment when that is set.
+// @Tags         users
+// @Produce      json
+// @Param        code   query  string  true  "Authorization code"
+// @Param        state  query  string  true  "State from /api/oidc/login"
+// @Success      200  {object}  loginResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Failure      502  {object}  map[string]string
+// @Router       /api/oidc/callback [get]
+func OIDCCallback(c *fiber.Ctx) error {
+	if providerErr := c.Query("error"); providerErr != "" {
+		msg := "identity provider returned " + providerErr
+		if desc := c.Query("error_description"); desc != "" {
+			msg += ": " + desc
+		}
+		return fiber.NewError(fiber.StatusBadRequest, msg)
+	}
+
+	code := strings.TrimSpace(c.Query("code"))
+	state := strings.TrimSpace(c.Query("state"))
+	if code == "" || state == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "code and state are required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	provider, err := oidcProvider(ctx)
+	if err != nil {
+		return err
+	}
+
+	statesCol, err := db.OIDCStatesCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	// Deleting the state as it is read makes every login attempt single-use.
+	var pending models.OIDCStates
+	err = statesCol.FindOneAndDelete(ctx, bson.M{
+		"StateHash": auth.HashOpaqueToken(state),
+		"ExpiresAt": bson.M{"$gt": time.Now().UTC()},
+	}).Decode(&pending)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusBadRequest, "login state is invalid or has expired")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch login state")
+	}
+
+	idToken, err := provider.Exchange(ctx, code, pending.CodeVerifier)
+	if err != nil {
+		log.Printf("oidc code exchange failed: %v", err)
+		return fiber.NewError(fiber.StatusBadGateway, "failed to exchange authorization code")
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, idToken, pending.Nonce)
+	if err != nil {
+		log.Printf("oidc id token rejected: %v", err)
+		if errors.Is(err, oidc.ErrInvalidIDToken) {
+			return fiber.NewError(fiber.StatusBadRequest, "identity provider returned an invalid ID token")
+		}
+		return fiber.NewError(fiber.StatusBadGateway, "failed to verify ID token")
+	}
+
+	user, err := oidcUser(ctx, c, provider.Issuer(), claims)
+	if err != nil {
+		return err
+	}
+
+	if err := middleware.AccountStatusError(user); err != nil {
+		return err
+	}
+
+	if user.TOTPEnabled {
+		challenge, expiresAt, err := auth.IssueChallengeToken(user.UserID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to issue challenge token")
+		}
+		return oidcRespond(c, twoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+			ExpiresAt:         expiresAt,
+		}, url.Values{
+			"TwoFactorRequired": {"true"},
+			"ChallengeToken":    {challenge},
+			"ExpiresAt":         {expiresAt.Format(time.RFC3339)},
+		})
+	}
+
+	resp, err := startSession(ctx, c, user)
+	if err != nil {
+		return err
+	}
+
+	return oidcRespond(c, resp, url.Values{
+		"AccessToken":      {resp.AccessToken},
+		"TokenType":        {resp.TokenType},
+		"ExpiresAt":        {resp.ExpiresAt.Format(time.RFC3339)},
+		"RefreshToken":     {resp.RefreshToken},
+		"RefreshExpiresAt": {resp.RefreshExpiresAt.Format(time.RFC3339)},
+		"SessionID":        {resp.SessionID.String()},
+	})
+}
+
+// oidcUser finds the account linked to the provider identity. Failing that,
+// an account with the same verified email is linked, and otherwise a new
+// active account without a password is created. Linking an account that is
+// still pending verification resets its credentials.
+func oidcUser(ctx context.Context, c *fiber.Ctx, issuer string, claims oidc.IDTokenClaims) (models.Users, error) {
+	if claims.Email == "" || !claims.EmailVerified {
+		return models.Users{}, fiber.NewError(fiber.StatusForbidden, "identity provider did not return a verified email")
+	}
+	email, err := normalizeEmail(claims.Email)
+	if err != nil {
+		return models.Users{}, err
+	}
+
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		return models.Users{}, fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var user models.Users
+	err = collection.FindOne(ctx, bson.M{"OIDCIssuer": issuer, "OIDCSubject": claims.Subject}).Decode(&user)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Users{}, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch user")
+	}
+
+	now := time.Now().UTC()
+	err = collection.FindOne(ctx, bson.M{"Email": email}).Decode(&user)
+	if err == nil {
+		if user.OIDCSubject != "" {
+			return models.Users{}, fiber.NewError(fiber.StatusConflict, "account is linked to a different identity")
+		}
+
+		// The provider has verified the address, which also completes a
+		// pending email verification. Whoever registered a pending account
+		// never proved they own the address, so its password, second factor
+		// and sessions are dropped instead of being handed the identity.
+		pending := user.Status == models.UserStatusPendingVerification
+		set := bson.M{"OIDCIssuer": issuer, "OIDCSubject": claims.Subject}
+		update := bson.M{"$set": set}
+		if pending {
+			set["Status"] = models.UserStatusActive
+			update["$unset"] = bson.M{
+				"PasswordHash":       "",
+				"TOTPEnabled":        "",
+				"TOTPSecret":         "",
+				"TOTPPendingSecret":  "",
+				"TOTPLastStep":       "",
+				"RecoveryCodeHashes": "",
+			}
+		}
+		if user.EmailVerifiedAt == nil {
+			set["EmailVerifiedAt"] = now
+		}
+
+		var linked models.Users
+		err := collection.FindOneAndUpdate(ctx,
+			bson.M{"UserId": user.UserID, "Status": user.Status, "OIDCSubject": bson.M{"$exists": false}},
+			update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&linked)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) || mongo.IsDuplicateKeyError(err) {
+				return models.Users{}, fiber.NewError(fiber.StatusConflict, "account is linked to a different identity")
+			}
+			return models.Users{}, fiber.NewError(fiber.StatusInternalServerError, "failed to link account")
+		}
+
+		userUUID, err := uuid.Parse(linked.UserID)
+		if err != nil {
+			return models.Users{}, fiber.NewError(fiber.StatusInternalServerError, "stored user ID is not a valid UUID")
+		}
+		recordAuditAs(ctx, c, userUUID, models.AuditActionUpdate, models.AuditEntityUser, linked.UserID, uuid.Nil, user, linked)
+		if pending {
+			if _, err := revokeSessions(ctx, bson.M{"UserID": userUUID}, "linked to identity provider"); err != nil {
+				return models.Users{}, fiber.NewError(fiber.StatusInternalServerError, "failed to revoke sessions")
+			}
+		}
+		return linked, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return models.Users{}, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch user")
+	}
+
+	userUUID := uuid.New()
+	user = models.Users{
+		UserID:          userUUID.String(),
+		Email:           email,
+		DisplayName:     strings.TrimSpace(claims.Name),
+		Status:          models.UserStatusActive,
+		Role:            roleForEmail(email),
+		CreatedAt:       now,
+		EmailVerifiedAt: &now,
+		OIDCIssuer:      issuer,
+		OIDCSubject:     claims.Subject,
+	}
+	if picture := strings.TrimSpace(claims.Picture); validateAvatarURL(picture) == nil {
+		user.AvatarURL = picture
+	}
+
+	if _, err := collection.InsertOne(ctx, user); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return models.Users{}, fiber.NewError(fiber.StatusConflict, "account was created concurrently; try again")
+		}
+		return models.Users{}, fiber.NewError(fiber.StatusInternalServerError, "failed to create user")
+	}
+	recordAuditAs(ctx, c, userUUID, models.AuditActionCreate, models.AuditEntityUser, user.UserID, uuid.Nil, nil, user)
+
+	return user, nil
+}
+
+func oidcProvider(ctx context.Context) (*oidc.Provider, error) {
+	provider, err := oidc.DefaultProvider(ctx)
+	if err != nil {
+		if errors.Is(err, oidc.ErrNotConfigured) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "OIDC login is not configured")
+		}
+		log.Printf("oidc discovery failed: %v", err)
+		return nil, fiber.NewError(fiber.StatusBadGateway, "identity provider unavailable")
+	}
+	return provider, nil
+}
+
+// oidcRespond returns body as JSON, or when OIDC_POST_LOGIN_REDIRECT is set
+// sends the browser back to the frontend with fragment in the URL fragment,
+// which is never sent to servers or written to access logs.
+func oidcRespond(c *fiber.Ctx, body interface{}, fragment url.Values) error {
+	target := strings.TrimSpace(os.Getenv("OIDC_POST_LOGIN_REDIRECT"))
+	if target == "" {
+		return c.JSON(body)
+	}
+	return c.Redirect(target+"#"+fragment.Encode(), fiber.StatusFound)
+}
@@ -0,0 +1,353 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/models"
+	"my-backend/internal/oidc/oidctest"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const oidcTestClientID = "warehouse"
+
+// The backend discovers its provider once per process, so every test shares
+// one mock provider.
+var oidcMock = sync.OnceValue(func() *oidctest.Provider {
+	mock := oidctest.NewProvider(oidcTestClientID)
+	os.Setenv("OIDC_ISSUER", mock.Issuer())
+	os.Setenv("OIDC_CLIENT_ID", oidcTestClientID)
+	os.Setenv("OIDC_CLIENT_SECRET", "")
+	os.Setenv("OIDC_POST_LOGIN_REDIRECT", "")
+	return mock
+})
+
+type oidcLoginResult struct {
+	AccessToken string       `json:"AccessToken"`
+	SessionID   uuid.UUID    `json:"SessionID"`
+	User        models.Users `json:"User"`
+}
+
+// startOIDCLogin calls /api/oidc/login and returns where it sends the browser.
+func startOIDCLogin(t *testing.T, app *fiber.App) string {
+	t.Helper()
+	mock := oidcMock()
+	resp, body := do(t, app, http.MethodGet, "/api/oidc/login", "", nil)
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("oidc login: status %d: %s", resp.StatusCode, body)
+	}
+	location := resp.Header.Get(fiber.HeaderLocation)
+	if !strings.HasPrefix(location, mock.Issuer()+"/authorize?") {
+		t.Fatalf("oidc login redirects to %q", location)
+	}
+	return location
+}
+
+func oidcCallback(t *testing.T, app *fiber.App, code, state string, out interface{}) int {
+	t.Helper()
+	query := url.Values{"code": {code}, "state": {state}}
+	return doJSON(t, app, http.MethodGet, "/api/oidc/callback?"+query.Encode(), "", nil, out)
+}
+
+// oidcLogin runs a full login as the identity in claims.
+func oidcLogin(t *testing.T, app *fiber.App, claims map[string]interface{}, out interface{}) int {
+	t.Helper()
+	code, state, err := oidcMock().Authorize(startOIDCLogin(t, app), claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return oidcCallback(t, app, code, state, out)
+}
+
+func oidcIdentity(email string) map[string]interface{} {
+	return map[string]interface{}{"sub": uuid.NewString(), "email": email, "email_verified": true}
+}
+
+func findUser(t *testing.T, userID string) models.Users {
+	t.Helper()
+	ctx := context.Background()
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var user models.Users
+	if err := collection.FindOne(ctx, bson.M{"UserId": userID}).Decode(&user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestOIDCLoginCreatesAccount(t *testing.T) {
+	app := newApp(t)
+	email := "oidc-" + uuid.NewString()[:8] + "@example.com"
+
+	var result oidcLoginResult
+	if status := oidcLogin(t, app, oidcIdentity(email), &result); status != http.StatusOK {
+		t.Fatalf("callback: status %d", status)
+	}
+	if result.AccessToken == "" || result.User.Email != email || result.User.Status != models.UserStatusActive {
+		t.Fatalf("unexpected login result %+v", result)
+	}
+
+	user := findUser(t, result.User.UserID)
+	if user.OIDCIssuer != oidcMock().Issuer() || user.OIDCSubject == "" || user.PasswordHash != "" {
+		t.Errorf("stored user %+v", user)
+	}
+}
+
+func TestOIDCCallbackState(t *testing.T) {
+	app := newApp(t)
+
+	t.Run("unknown state", func(t *testing.T) {
+		code, _, err := oidcMock().Authorize(startOIDCLogin(t, app), oidcIdentity("state-"+uuid.NewString()[:8]+"@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status := oidcCallback(t, app, code, "made-up-state", nil); status != http.StatusBadRequest {
+			t.Errorf("status %d, want 400", status)
+		}
+	})
+
+	t.Run("state is single use", func(t *testing.T) {
+		code, state, err := oidcMock().Authorize(startOIDCLogin(t, app), oidcIdentity("state-"+uuid.NewString()[:8]+"@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status := oidcCallback(t, app, code, state, nil); status != http.StatusOK {
+			t.Fatalf("first callback: status %d", status)
+		}
+		if status := oidcCallback(t, app, code, state, nil); status != http.StatusBadRequest {
+			t.Errorf("replayed callback: status %d, want 400", status)
+		}
+	})
+
+	t.Run("expired state", func(t *testing.T) {
+		location := startOIDCLogin(t, app)
+		code, state, err := oidcMock().Authorize(location, oidcIdentity("state-"+uuid.NewString()[:8]+"@example.com"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx := context.Background()
+		collection, err := db.OIDCStatesCollection(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := collection.UpdateMany(ctx, bson.M{}, bson.M{"$set": bson.M{"ExpiresAt": time.Now().Add(-time.Minute)}}); err != nil {
+			t.Fatal(err)
+		}
+		if status := oidcCallback(t, app, code, state, nil); status != http.StatusBadRequest {
+			t.Errorf("status %d, want 400", status)
+		}
+	})
+}
+
+func TestOIDCCallbackValidatesIDToken(t *testing.T) {
+	app := newApp(t)
+	email := "token-" + uuid.NewString()[:8] + "@example.com"
+
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		status int
+	}{
+		{"nonce from another login", map[string]interface{}{"nonce": "replayed"}, http.StatusBadRequest},
+		{"other audience", map[string]interface{}{"aud": "someone-else"}, http.StatusBadRequest},
+		{"other issuer", map[string]interface{}{"iss": "https://evil.example"}, http.StatusBadRequest},
+		{"expired", map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}, http.StatusBadRequest},
+		{"unverified email", map[string]interface{}{"email_verified": false}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := oidcIdentity(email)
+			for k, v := range tt.claims {
+				claims[k] = v
+			}
+			if status := oidcLogin(t, app, claims, nil); status != tt.status {
+				t.Errorf("status %d, want %d", status, tt.status)
+			}
+		})
+	}
+}
+
+func TestOIDCLinkingPendingAccountResetsCredentials(t *testing.T) {
+	app := newApp(t)
+	ctx := context.Background()
+
+	// Someone registered the victim's address but never verified it, set up
+	// a second factor on the side and holds a session.
+	squatted := insertUser(t, models.Users{
+		Email:              "victim-" + uuid.NewString()[:8] + "@example.com",
+		Status:             models.UserStatusPendingVerification,
+		TOTPEnabled:        true,
+		TOTPSecret:         "JBSWY3DPEHPK3PXP",
+		RecoveryCodeHashes: []string{"hash"},
+	})
+	sessionsCol, err := db.SessionsCollection(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	session := models.Sessions{
+		SessionID:        uuid.New(),
+		UserID:           uuid.MustParse(squatted.UserID),
+		RefreshTokenHash: uuid.NewString(),
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(time.Hour),
+	}
+	if _, err := sessionsCol.InsertOne(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+
+	var result oidcLoginResult
+	if status := oidcLogin(t, app, oidcIdentity(strings.ToUpper(squatted.Email)), &result); status != http.StatusOK {
+		t.Fatalf("callback: status %d", status)
+	}
+	if result.AccessToken == "" || result.User.UserID != squatted.UserID {
+		t.Fatalf("expected a session for the linked account, got %+v", result)
+	}
+
+	linked := findUser(t, squatted.UserID)
+	if linked.Status != models.UserStatusActive || linked.OIDCSubject == "" {
+		t.Errorf("account was not activated and linked: %+v", linked)
+	}
+	if linked.PasswordHash != "" || linked.TOTPEnabled || linked.TOTPSecret != "" || len(linked.RecoveryCodeHashes) != 0 {
+		t.Errorf("credentials of the pending account survived linking: %+v", linked)
+	}
+
+	var revoked models.Sessions
+	if err := sessionsCol.FindOne(ctx, bson.M{"SessionID": session.SessionID}).Decode(&revoked); err != nil {
+		t.Fatal(err)
+	}
+	if revoked.RevokedAt == nil {
+		t.Error("the pending account's session was not revoked")
+	}
+
+	status := doJSON(t, app, http.MethodPost, "/api/login", "", fiber.Map{"Email": squatted.Email, "Password": testPassword}, nil)
+	if status != http.StatusUnauthorized {
+		t.Errorf("login with the squatter's password: status %d, want 401", status)
+	}
+}
+
+func TestOIDCLinkingActiveAccountKeepsPassword(t *testing.T) {
+	app := newApp(t)
+	existing := insertUser(t, models.Users{})
+
+	var result oidcLoginResult
+	if status := oidcLogin(t, app, oidcIdentity(existing.Email), &result); status != http.StatusOK {
+		t.Fatalf("callback: status %d", status)
+	}
+	if result.User.UserID != existing.UserID {
+		t.Fatalf("signed in as %s, want the existing account %s", result.User.UserID, existing.UserID)
+	}
+	if linked := findUser(t, existing.UserID); linked.PasswordHash != existing.PasswordHash {
+		t.Error("linking replaced the password of a verified account")
+	}
+}
+
+func TestOIDCAccountReauthenticatesBySigningIn(t *testing.T) {
+	app := newApp(t)
+	ctx := context.Background()
+
+	signIn := func(t *testing.T, identity map[string]interface{}) oidcLoginResult {
+		t.Helper()
+		var result oidcLoginResult
+		if status := oidcLogin(t, app, identity, &result); status != http.StatusOK {
+			t.Fatalf("callback: status %d", status)
+		}
+		return result
+	}
+	age := func(t *testing.T, sessionID uuid.UUID) {
+		t.Helper()
+		collection, err := db.SessionsCollection(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		created := time.Now().Add(-time.Hour)
+		if _, err := collection.UpdateOne(ctx, bson.M{"SessionID": sessionID}, bson.M{"$set": bson.M{"CreatedAt": created}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("set a first password", func(t *testing.T) {
+		identity := oidcIdentity("reauth-" + uuid.NewString()[:8] + "@example.com")
+		result := signIn(t, identity)
+		body := fiber.Map{"NewPassword": testPassword}
+
+		age(t, result.SessionID)
+		if status := doJSON(t, app, http.MethodPut, "/api/me/password", result.AccessToken, body, nil); status != http.StatusUnauthorized {
+			t.Fatalf("with an old session: status %d, want 401", status)
+		}
+
+		fresh := signIn(t, identity)
+		if status := doJSON(t, app, http.MethodPut, "/api/me/password", fresh.AccessToken, body, nil); status != http.StatusOK {
+			t.Fatalf("after signing in again: status %d", status)
+		}
+		login := fiber.Map{"Email": result.User.Email, "Password": testPassword}
+		if status := doJSON(t, app, http.MethodPost, "/api/login", "", login, nil); status != http.StatusOK {
+			t.Errorf("login with the new password: status %d", status)
+		}
+	})
+
+	t.Run("delete the account", func(t *testing.T) {
+		identity := oidcIdentity("reauth-" + uuid.NewString()[:8] + "@example.com")
+		result := signIn(t, identity)
+
+		age(t, result.SessionID)
+		if status := doJSON(t, app, http.MethodDelete, "/api/me", result.AccessToken, fiber.Map{}, nil); status != http.StatusUnauthorized {
+			t.Fatalf("with an old session: status %d, want 401", status)
+		}
+
+		fresh := signIn(t, identity)
+		if status := doJSON(t, app, http.MethodDelete, "/api/me", fresh.AccessToken, fiber.Map{}, nil); status != http.StatusOK {
+			t.Fatalf("after signing in again: status %d", status)
+		}
+	})
+}
+
+func TestOIDCAccountChangesAreAudited(t *testing.T) {
+	app := newApp(t)
+	ctx := context.Background()
+	collection, err := db.AuditLogCollection(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending := insertUser(t, models.Users{Status: models.UserStatusPendingVerification})
+	tests := []struct {
+		name   string
+		email  string
+		action string
+	}{
+		{"created", "audit-" + uuid.NewString()[:8] + "@example.com", models.AuditActionCreate},
+		{"linked", pending.Email, models.AuditActionUpdate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result oidcLoginResult
+			if status := oidcLogin(t, app, oidcIdentity(tt.email), &result); status != http.StatusOK {
+				t.Fatalf("callback: status %d", status)
+			}
+			filter := bson.M{
+				"ActorID":    uuid.MustParse(result.User.UserID),
+				"Action":     tt.action,
+				"EntityType": models.AuditEntityUser,
+				"EntityID":   result.User.UserID,
+			}
+			if n, err := collection.CountDocuments(ctx, filter); err != nil || n != 1 {
+				t.Errorf("%d %s entries for the account (%v), want 1", n, tt.action, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"my-backend/internal/auth"
+	"my-backend/internal/db"
+	"my-backend/internal/mail"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength = 8
+
+type forgotPasswordRequest struct {
+	Email string `json:"Email"`
+}
+
+type resetPasswordRequest struct {
+	Token       string `json:"Token"`
+	NewPassword string `json:"NewPassword"`
+}
+
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("password must be at least %d characters", minPasswordLength))
+	}
+	return nil
+}
+
+// ForgotPassword godoc
+// @Summary      Request a password reset
+// @Description  Emails a single-use reset link when the address belongs to an active account. Always responds 202, before the account is looked up, so neither the response nor its timing reveals which emails are registered.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      forgotPasswordRequest  true  "Account email"
+// @Success      202  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Router       /api/password/forgot [post]
+func ForgotPassword(c *fiber.Ctx) error {
+	var req forgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	req.Email = canonicalEmail(req.Email)
+	if req.Email == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "email is required")
+	}
+
+	// Looking up the account and issuing the token happen after the response
+	// is sent, so its timing does not reveal whether the address is registered.
+	go func(email string) {
+		if err := sendPasswordReset(email); err != nil {
+			log.Printf("failed to send password reset email: %v", err)
+		}
+	}(req.Email)
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "if the account exists, a reset link has been sent"})
+}
+
+// sendPasswordReset emails a reset link to the active account with this
+// email, if there is one.
+func sendPasswordReset(email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	usersCol, err := db.UsersCollection(ctx)
+	if err != nil {
+		return err
+	}
+	resetsCol, err := db.PasswordResetsCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	var user models.Users
+	if err := usersCol.FindOne(ctx, bson.M{"Email": email}).Decode(&user); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+		return fmt.Errorf("fetching user: %w", err)
+	}
+	if !user.IsActive() {
+		return nil
+	}
+
+	userUUID, err := uuid.Parse(user.UserID)
+	if err != nil {
+		return fmt.Errorf("stored user ID is not a valid UUID: %w", err)
+	}
+
+	token, tokenHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return fmt.Errorf("generating reset token: %w", err)
+	}
+
+	// Only the most recent link works; requesting a new one invalidates older ones.
+	if _, err := resetsCol.DeleteMany(ctx, bson.M{"UserID": userUUID, "UsedAt": nil}); err != nil {
+		return fmt.Errorf("invalidating previous reset tokens: %w", err)
+	}
+
+	now := time.Now().UTC()
+	reset := models.PasswordResets{
+		TokenHash: tokenHash,
+		UserID:    userUUID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(auth.PasswordResetTTL()),
+	}
+	if _, err := resetsCol.InsertOne(ctx, reset); err != nil {
+		return fmt.Errorf("creating reset token: %w", err)
+	}
+
+	return mail.DefaultSender().Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password for your account.\n\n"+
+			"Open this link to choose a new password (valid for %s):\n%s\n\n"+
+			"If it wasn't you, you can ignore this email.",
+			auth.PasswordResetTTL(), mail.AppURL("/reset-password?token="+url.QueryEscape(token))),
+	})
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Sets a new password using a token from ForgotPassword. Tokens are single-use and expire; every existing session of the user is revoked.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      resetPasswordRequest  true  "Reset token and new password"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/password/reset [post]
+func ResetPassword(c *fiber.Ctx) error {
+	var req resetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	req.Token = strings.TrimSpace(req.Token)
+	if req.Token == "" || req.NewPassword == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Token and NewPassword are required")
+	}
+	if err := validatePassword(req.NewPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to hash password")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	usersCol, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	resetsCol, err := db.PasswordResetsCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	// Consuming the token atomically guarantees it can only be used once.
+	now := time.Now().UTC()
+	var reset models.PasswordResets
+	err = resetsCol.FindOneAndUpdate(ctx,
+		bson.M{
+			"TokenHash": auth.HashOpaqueToken(req.Token),
+			"UsedAt":    nil,
+			"ExpiresAt": bson.M{"$gt": now},
+		},
+		bson.M{"$set": bson.M{"UsedAt": now}},
+	).Decode(&reset)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusBadRequest, "reset token is invalid or has expired")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to verify reset token")
+	}
+
+	res, err := usersCol.UpdateOne(ctx,
+		bson.M{"UserId": reset.UserID.String()},
+		bson.M{"$set": bson.M{"PasswordHash": string(hashedPassword)}},
+	)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to update password")
+	}
+	if res.MatchedCount == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "reset token is invalid or has expired")
+	}
+
+	revoked, err := revokeSessions(ctx, bson.M{"UserID": reset.UserID}, "password reset")
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to revoke sessions")
+	}
+	recordAuditAs(ctx, c, reset.UserID, models.AuditActionUpdate, models.AuditEntityPassword, reset.UserID.String(), uuid.Nil, nil,
+		fiber.Map{"Method": "reset", "RevokedSessions": revoked})
+
+	return c.JSON(fiber.Map{"message": "password updated"})
+}
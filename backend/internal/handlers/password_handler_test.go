@@ -0,0 +1,46 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestForgotPasswordIssuesTokenAfterResponding(t *testing.T) {
+	app := newApp(t)
+	user := insertUser(t, models.Users{})
+
+	for _, email := range []string{user.Email, "nobody-" + user.UserID[:8] + "@example.com"} {
+		if status := doJSON(t, app, http.MethodPost, "/api/password/forgot", "", fiber.Map{"Email": email}, nil); status != http.StatusAccepted {
+			t.Fatalf("forgot password for %s: status %d, want 202", email, status)
+		}
+	}
+
+	ctx := context.Background()
+	collection, err := db.PasswordResetsCollection(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		n, err := collection.CountDocuments(ctx, bson.M{"UserID": uuid.MustParse(user.UserID)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n == 1 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("found %d reset tokens, want 1", n)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
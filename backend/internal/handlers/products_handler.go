@@ -2,11 +2,16 @@ package handlers
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 
+	"my-backend/internal/catalog"
 	"my-backend/internal/db"
+	"my-backend/internal/inventory"
 	"my-backend/internal/models"
 
 	"github.com/gofiber/fiber/v2"
@@ -17,28 +22,58 @@ import (
 )
 
 type createProductRequest struct {
-	StockID     string `json:"StockID"`
-	ProductName string `json:"ProductName"`
-	Category    string `json:"Category"`
-	Unit        string `json:"Unit"`
-	ProductQty  int    `json:"ProductQty"`
+	StockID     string   `json:"StockID"`
+	ProductName string   `json:"ProductName"`
+	Category    string   `json:"Category"`
+	Unit        string   `json:"Unit"`
+	ProductQty  int      `json:"ProductQty"`
+	MinQty      *int     `json:"MinQty"`
+	ReorderQty  *int     `json:"ReorderQty"`
+	Barcodes    []string `json:"Barcodes"`
+	SKU         string   `json:"SKU"`
+	// LotNumber and ExpiresAt put the opening quantity into a lot.
+	LotNumber string `json:"LotNumber"`
+	ExpiresAt string `json:"ExpiresAt"`
 }
 
 type updateProductRequest struct {
-	ProductName *string `json:"ProductName"`
-	Category    *string `json:"Category"`
-	Unit        *string `json:"Unit"`
-	ProductQty  *int    `json:"ProductQty"`
+	ProductName *string     `json:"ProductName"`
+	Category    *string     `json:"Category"`
+	Unit        *string     `json:"Unit"`
+	ProductQty  *int        `json:"ProductQty"`
+	MinQty      nullableInt `json:"MinQty" swaggertype:"integer"`
+	ReorderQty  nullableInt `json:"ReorderQty" swaggertype:"integer"`
+	Barcodes    *[]string   `json:"Barcodes"`
+	SKU         *string     `json:"SKU"`
+}
+
+// nullableInt tells an omitted field apart from an explicit null, which
+// clears the value.
+type nullableInt struct {
+	Set   bool
+	Value *int
+}
+
+func (n *nullableInt) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Value = nil
+		return nil
+	}
+	return json.Unmarshal(data, &n.Value)
 }
 
 // DeleteProduct godoc
 // @Summary      Delete a product
-// @Description  Deletes a product by ID.
+// @Description  Deletes a product by ID. Its movements stay in the ledger.
 // @Tags         products
 // @Produce      json
+// @Security     BearerAuth
 // @Param        productId  path  string  true  "Product ID (UUID)"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /api/products/{productId} [delete]
 func DeleteProduct(c *fiber.Ctx) error {
@@ -55,6 +90,11 @@ func DeleteProduct(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	product, err := authorizeProduct(ctx, c, productUUID, inventory.AccessEditor)
+	if err != nil {
+		return err
+	}
+
 	collection, err := db.ProductsCollection(ctx)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
@@ -64,19 +104,61 @@ func DeleteProduct(c *fiber.Ctx) error {
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to delete product")
 	}
+	if res.DeletedCount > 0 {
+		recordAudit(ctx, c, models.AuditActionDelete, models.AuditEntityProduct, productUUID.String(), product.StockID, product, nil)
+	}
+
+	shoppingCol, err := db.ShoppingListCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	if _, err := shoppingCol.DeleteMany(ctx, bson.M{"ProductID": productUUID}); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to delete shopping list items")
+	}
 
 	return c.JSON(fiber.Map{
 		"deleted_product": res.DeletedCount,
 	})
 }
 
+// Sort keys of ListProducts and the fields they sort by.
+var productSortFields = map[string]string{
+	"name":     "ProductName",
+	"quantity": "ProductQty",
+	"updated":  "UpdatedAt",
+}
+
+// productCursor marks where a page of ListProducts ended: the sort value and
+// ProductID of its last product, which breaks ties.
+type productCursor struct {
+	Sort      string     `json:"s"`
+	Desc      bool       `json:"d,omitempty"`
+	Name      string     `json:"n,omitempty"`
+	Qty       int        `json:"q,omitempty"`
+	UpdatedAt *time.Time `json:"u,omitempty"`
+	ProductID uuid.UUID  `json:"id"`
+}
+
 // ListProducts godoc
 // @Summary      List products
-// @Description  Returns products filtered by StockID.
+// @Description  Returns a page of the stock's products. Pages are linked by an opaque cursor: pass the X-Next-Cursor header of a response as cursor to get the next page, together with the same sort, order and filters; the header is absent on the last page. Page numbers are not supported and page is rejected. The number of products matching the filters is sent in X-Total-Count.
 // @Tags         products
 // @Produce      json
-// @Param        stockId  query  string  true  "Stock ID (UUID)"
+// @Security     BearerAuth
+// @Param        stockId   query  string  true   "Stock ID (UUID)"
+// @Param        sort      query  string  false  "name (default), quantity or updated"
+// @Param        order     query  string  false  "asc (default) or desc"
+// @Param        category  query  string  false  "Only products in this category"
+// @Param        unit      query  string  false  "Only products with this unit"
+// @Param        minQty    query  int     false  "Only products with at least this quantity"
+// @Param        maxQty    query  int     false  "Only products with at most this quantity"
+// @Param        lowStock  query  bool    false  "Only products at or below their MinQty (true) or not (false)"
+// @Param        limit     query  int     false  "Products per page (default 50, max 200)"
+// @Param        cursor    query  string  false  "X-Next-Cursor of the previous page"
 // @Success      200  {array}   models.Products
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /api/products [get]
 func ListProducts(c *fiber.Ctx) error {
@@ -90,37 +172,217 @@ func ListProducts(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "stockId must be a valid UUID")
 	}
 
+	sortKey := strings.ToLower(strings.TrimSpace(c.Query("sort", "name")))
+	sortField, ok := productSortFields[sortKey]
+	if !ok {
+		return fiber.NewError(fiber.StatusBadRequest, "sort must be name, quantity or updated")
+	}
+	var desc bool
+	switch strings.ToLower(strings.TrimSpace(c.Query("order", "asc"))) {
+	case "asc":
+	case "desc":
+		desc = true
+	default:
+		return fiber.NewError(fiber.StatusBadRequest, "order must be asc or desc")
+	}
+
+	filter, err := productListFilter(c, stockUUID)
+	if err != nil {
+		return err
+	}
+	// Pages are reached through cursors only; a page number would silently
+	// return the first page.
+	if c.Query("page") != "" {
+		return fiber.NewError(fiber.StatusBadRequest, "page is not supported; pass the X-Next-Cursor header as cursor instead")
+	}
+	limit, err := limitParam(c)
+	if err != nil {
+		return err
+	}
+
+	pageFilter := filter
+	if raw := strings.TrimSpace(c.Query("cursor")); raw != "" {
+		cursor, err := decodeProductCursor(raw)
+		if err != nil || cursor.Sort != sortKey || cursor.Desc != desc {
+			return fiber.NewError(fiber.StatusBadRequest, "cursor is invalid or was made for a different sort order")
+		}
+		pageFilter = bson.M{"$and": bson.A{filter, keysetFilter(sortField, cursor.value(), cursor.ProductID, desc)}}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if _, err := authorizeStock(ctx, c, stockUUID, inventory.AccessViewer); err != nil {
+		return err
+	}
+
 	collection, err := db.ProductsCollection(ctx)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
 	}
 
-	cursor, err := collection.Find(ctx, bson.M{"StockID": stockUUID})
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to count products")
+	}
+
+	direction := 1
+	if desc {
+		direction = -1
+	}
+	// One extra product tells whether there is a next page.
+	opts := options.Find().
+		SetSort(bson.D{{Key: sortField, Value: direction}, {Key: "ProductID", Value: direction}}).
+		SetLimit(limit + 1)
+	cursor, err := collection.Find(ctx, pageFilter, opts)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch products")
 	}
 	defer cursor.Close(ctx)
 
-	var products []models.Products
+	products := []models.Products{}
 	if err := cursor.All(ctx, &products); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to decode products")
 	}
 
+	if int64(len(products)) > limit {
+		products = products[:limit]
+		last := products[len(products)-1]
+		next := productCursor{
+			Sort:      sortKey,
+			Desc:      desc,
+			ProductID: last.ProductID,
+		}
+		switch sortKey {
+		case "name":
+			next.Name = last.ProductName
+		case "quantity":
+			next.Qty = last.ProductQty
+		case "updated":
+			next.UpdatedAt = last.UpdatedAt
+		}
+		c.Set("X-Next-Cursor", next.encode())
+	}
+	c.Set("X-Total-Count", strconv.FormatInt(total, 10))
+
 	return c.JSON(products)
 }
 
+// productListFilter builds the ListProducts filter from the query.
+func productListFilter(c *fiber.Ctx, stockID uuid.UUID) (bson.M, error) {
+	filter := bson.M{"StockID": stockID}
+
+	if category := strings.TrimSpace(c.Query("category")); category != "" {
+		filter["Category"] = category
+	}
+	if unit := strings.TrimSpace(c.Query("unit")); unit != "" {
+		filter["Unit"] = unit
+	}
+
+	qty := bson.M{}
+	for param, op := range map[string]string{"minQty": "$gte", "maxQty": "$lte"} {
+		raw := strings.TrimSpace(c.Query(param))
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, param+" must be an integer")
+		}
+		qty[op] = v
+	}
+	if len(qty) > 0 {
+		filter["ProductQty"] = qty
+	}
+
+	if raw := strings.TrimSpace(c.Query("lowStock")); raw != "" {
+		low, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "lowStock must be true or false")
+		}
+		if low {
+			filter["MinQty"] = bson.M{"$ne": nil}
+			filter["$expr"] = bson.M{"$lte": bson.A{"$ProductQty", "$MinQty"}}
+		} else {
+			filter["$or"] = bson.A{
+				bson.M{"MinQty": nil},
+				bson.M{"$expr": bson.M{"$gt": bson.A{"$ProductQty", "$MinQty"}}},
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+// keysetFilter matches the products after (value, productID) in the sort
+// order. Products without the sort field come first in ascending order and
+// last in descending order, as MongoDB sorts them.
+func keysetFilter(field string, value interface{}, productID uuid.UUID, desc bool) bson.M {
+	after, missing := "$gt", bson.M{field: bson.M{"$ne": nil}}
+	if desc {
+		after, missing = "$lt", bson.M{field: nil}
+	}
+	tie := bson.M{"ProductID": bson.M{after: productID}}
+
+	if value == nil {
+		tie[field] = nil
+		if desc {
+			return tie
+		}
+		return bson.M{"$or": bson.A{tie, missing}}
+	}
+
+	tie[field] = value
+	or := bson.A{bson.M{field: bson.M{after: value}}, tie}
+	if desc {
+		or = append(or, missing)
+	}
+	return bson.M{"$or": or}
+}
+
+// value returns the cursor's sort value, nil for a product without one.
+func (pc productCursor) value() interface{} {
+	switch pc.Sort {
+	case "name":
+		return pc.Name
+	case "quantity":
+		return pc.Qty
+	default:
+		if pc.UpdatedAt == nil {
+			return nil
+		}
+		return *pc.UpdatedAt
+	}
+}
+
+func (pc productCursor) encode() string {
+	data, _ := json.Marshal(pc)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeProductCursor(raw string) (productCursor, error) {
+	var pc productCursor
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return pc, err
+	}
+	err = json.Unmarshal(data, &pc)
+	return pc, err
+}
+
 // CreateProduct godoc
 // @Summary      Create a product
-// @Description  Creates a new product record.
+// @Description  Creates a new product record. Barcodes (EAN/UPC) and SKU are optional and must be unique within the stock. ProductName, Category and Unit left empty are prefilled from the offline product catalog entry of the first barcode; ProductName is required when there is none.
 // @Tags         products
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        payload  body      createProductRequest  true  "Product data"
 // @Success      201  {object}  models.Products
 // @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /api/products [post]
 func CreateProduct(c *fiber.Ctx) error {
@@ -134,12 +396,30 @@ func CreateProduct(c *fiber.Ctx) error {
 	req.Unit = strings.TrimSpace(req.Unit)
 	req.StockID = strings.TrimSpace(req.StockID)
 
-	if req.StockID == "" || req.ProductName == "" {
-		return fiber.NewError(fiber.StatusBadRequest, "StockID and ProductName are required")
+	if req.StockID == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "StockID is required")
 	}
 	if req.ProductQty == 0 {
 		return fiber.NewError(fiber.StatusBadRequest, "ProductQty must be provided")
 	}
+	if req.ProductQty < 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "ProductQty cannot be negative")
+	}
+	if err := validateThresholds(req.MinQty, req.ReorderQty); err != nil {
+		return err
+	}
+	expiresAt, err := parseExpiry(req.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	barcodes, err := normalizeBarcodes(req.Barcodes)
+	if err != nil {
+		return err
+	}
+	sku, err := normalizeSKU(req.SKU)
+	if err != nil {
+		return err
+	}
 
 	stockUUID, err := uuid.Parse(req.StockID)
 	if err != nil {
@@ -149,11 +429,38 @@ func CreateProduct(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if _, err := authorizeStock(ctx, c, stockUUID, inventory.AccessEditor); err != nil {
+		return err
+	}
+
+	// Whatever the client left out is prefilled from the offline catalog.
+	if len(barcodes) > 0 && (req.ProductName == "" || req.Category == "" || req.Unit == "") {
+		entry, err := catalog.Lookup(ctx, barcodes[0])
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to look up the product catalog")
+		}
+		if entry != nil {
+			if req.ProductName == "" {
+				req.ProductName = entry.ProductName
+			}
+			if req.Category == "" {
+				req.Category = entry.Category
+			}
+			if req.Unit == "" {
+				req.Unit = entry.Unit
+			}
+		}
+	}
+	if req.ProductName == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "ProductName is required unless the barcode is in the product catalog")
+	}
+
 	collection, err := db.ProductsCollection(ctx)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
 	}
 
+	now := time.Now().UTC()
 	product := models.Products{
 		ProductID:   uuid.New(),
 		StockID:     stockUUID,
@@ -161,26 +468,58 @@ func CreateProduct(c *fiber.Ctx) error {
 		Category:    req.Category,
 		Unit:        req.Unit,
 		ProductQty:  req.ProductQty,
+		MinQty:      req.MinQty,
+		ReorderQty:  req.ReorderQty,
+		Barcodes:    barcodes,
+		GTINs:       barcodeGTINs(barcodes),
+		SKU:         sku,
+		UpdatedAt:   &now,
 	}
 
+	// The opening quantity is the first entry of the product's ledger.
+	opening := movementInput{
+		Type:      models.MovementTypeIn,
+		Delta:     product.ProductQty,
+		Reason:    "initial stock",
+		LotNumber: strings.TrimSpace(req.LotNumber),
+		ExpiresAt: expiresAt,
+	}
+	lots, lotChanges, err := planLots(product, opening)
+	if err != nil {
+		return err
+	}
+	product.Lots = lots
+
 	if _, err := collection.InsertOne(ctx, product); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fiber.NewError(fiber.StatusConflict, "another product in this stock already has this barcode or SKU")
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to create product")
 	}
+	recordAudit(ctx, c, models.AuditActionCreate, models.AuditEntityProduct, product.ProductID.String(), product.StockID, nil, product)
+
+	if _, err := recordMovement(ctx, c, product, opening, lotChanges); err != nil {
+		return err
+	}
+	checkLowStock(ctx, product)
 
 	return c.Status(fiber.StatusCreated).JSON(product)
 }
 
 // UpdateProduct godoc
 // @Summary      Update a product
-// @Description  Updates mutable fields on an existing product.
+// @Description  Updates mutable fields on an existing product. MinQty and ReorderQty are cleared with null, Barcodes and SKU with an empty value. A changed ProductQty is recorded as an ADJUST movement and cannot go below the quantity held in lots; use the movements endpoint to change quantities safely under concurrent use.
 // @Tags         products
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        productId  path      string                 true  "Product ID (UUID)"
 // @Param        payload    body      updateProductRequest   true  "Fields to update"
 // @Success      200        {object}  models.Products
 // @Failure      400        {object}  map[string]string
+// @Failure      403        {object}  map[string]string
 // @Failure      404        {object}  map[string]string
+// @Failure      409        {object}  map[string]string
 // @Failure      500        {object}  map[string]string
 // @Router       /api/products/{productId} [put]
 func UpdateProduct(c *fiber.Ctx) error {
@@ -200,6 +539,7 @@ func UpdateProduct(c *fiber.Ctx) error {
 	}
 
 	updates := bson.M{}
+	unset := bson.M{}
 	if req.ProductName != nil {
 		trimmed := strings.TrimSpace(*req.ProductName)
 		if trimmed == "" {
@@ -224,31 +564,116 @@ func UpdateProduct(c *fiber.Ctx) error {
 		}
 		updates["ProductQty"] = *req.ProductQty
 	}
+	if err := validateThresholds(req.MinQty.Value, req.ReorderQty.Value); err != nil {
+		return err
+	}
+	if req.MinQty.Set {
+		updates["MinQty"] = req.MinQty.Value
+	}
+	if req.ReorderQty.Set {
+		updates["ReorderQty"] = req.ReorderQty.Value
+	}
+	// Cleared codes are removed rather than set to null, which the unique
+	// indexes would treat as a value.
+	if req.Barcodes != nil {
+		barcodes, err := normalizeBarcodes(*req.Barcodes)
+		if err != nil {
+			return err
+		}
+		if len(barcodes) == 0 {
+			unset["Barcodes"] = ""
+			unset["GTINs"] = ""
+		} else {
+			updates["Barcodes"] = barcodes
+			updates["GTINs"] = barcodeGTINs(barcodes)
+		}
+	}
+	if req.SKU != nil {
+		sku, err := normalizeSKU(*req.SKU)
+		if err != nil {
+			return err
+		}
+		if sku == "" {
+			unset["SKU"] = ""
+		} else {
+			updates["SKU"] = sku
+		}
+	}
 
-	if len(updates) == 0 {
+	if len(updates) == 0 && len(unset) == 0 {
 		return fiber.NewError(fiber.StatusBadRequest, "provide at least one field to update")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	before, err := authorizeProduct(ctx, c, productUUID, inventory.AccessEditor)
+	if err != nil {
+		return err
+	}
+
 	collection, err := db.ProductsCollection(ctx)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
 	}
 
+	updates["UpdatedAt"] = time.Now().UTC()
+	filter := bson.M{"ProductID": productUUID}
+	update := bson.M{"$set": updates}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	if req.ProductQty != nil {
+		// Lots are not adjusted here, so the new quantity has to cover them.
+		// The revision check makes sure no movement changed them since.
+		if *req.ProductQty < before.LotQty() {
+			return fiber.NewError(fiber.StatusConflict, "ProductQty cannot be less than the quantity held in lots; record an OUT movement instead")
+		}
+		filter["Revision"] = inventory.RevisionFilter(before.Revision)
+		update["$inc"] = bson.M{"Revision": 1}
+	}
+
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	res := collection.FindOneAndUpdate(ctx, bson.M{"ProductID": productUUID}, bson.M{"$set": updates}, opts)
+	res := collection.FindOneAndUpdate(ctx, filter, update, opts)
 	var updated models.Products
 	if err := res.Err(); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
+			if req.ProductQty != nil {
+				return fiber.NewError(fiber.StatusConflict, "the product is being changed concurrently; retry")
+			}
 			return fiber.NewError(fiber.StatusNotFound, "product not found")
 		}
+		if mongo.IsDuplicateKeyError(err) {
+			return fiber.NewError(fiber.StatusConflict, "another product in this stock already has this barcode or SKU")
+		}
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to update product")
 	}
 	if err := res.Decode(&updated); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to decode updated product")
 	}
+	recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntityProduct, productUUID.String(), updated.StockID, before, updated)
+
+	// Setting an absolute quantity is kept for compatibility; the ledger sees
+	// it as an adjustment. Only then was the update tied to the revision that
+	// before was read at, so the delta cannot include a concurrent movement.
+	if delta := updated.ProductQty - before.ProductQty; req.ProductQty != nil && delta != 0 {
+		in := movementInput{Type: models.MovementTypeAdjust, Delta: delta, Reason: "product update"}
+		if _, err := recordMovement(ctx, c, updated, in, nil); err != nil {
+			return err
+		}
+	}
+	checkLowStock(ctx, updated)
 
 	return c.JSON(updated)
 }
+
+// validateThresholds checks the low-stock settings of a product.
+func validateThresholds(minQty, reorderQty *int) error {
+	if minQty != nil && *minQty < 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "MinQty cannot be negative")
+	}
+	if reorderQty != nil && *reorderQty <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "ReorderQty must be positive")
+	}
+	return nil
+}
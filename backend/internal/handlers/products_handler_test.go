@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// compareSortValues orders two sort values the way MongoDB does for the
+// fields ListProducts sorts by, with a missing value before any other.
+func compareSortValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case int:
+		return a - b.(int)
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case uuid.UUID:
+		bu := b.(uuid.UUID)
+		return bytes.Compare(a[:], bu[:])
+	}
+	panic("unsupported sort value")
+}
+
+// matchesFilter evaluates the subset of the query language keysetFilter
+// produces against a document.
+func matchesFilter(doc map[string]interface{}, filter bson.M) bool {
+	for key, cond := range filter {
+		if key == "$or" {
+			matched := false
+			for _, sub := range cond.(bson.A) {
+				matched = matched || matchesFilter(doc, sub.(bson.M))
+			}
+			if !matched {
+				return false
+			}
+			continue
+		}
+		value := doc[key]
+		ops, ok := cond.(bson.M)
+		if !ok {
+			if compareSortValues(value, cond) != 0 || (value == nil) != (cond == nil) {
+				return false
+			}
+			continue
+		}
+		for op, operand := range ops {
+			var ok bool
+			switch op {
+			case "$ne":
+				ok = (value == nil) != (operand == nil) || (value != nil && compareSortValues(value, operand) != 0)
+			case "$gt":
+				ok = value != nil && compareSortValues(value, operand) > 0
+			case "$lt":
+				ok = value != nil && compareSortValues(value, operand) < 0
+			default:
+				panic("unsupported operator " + op)
+			}
+			if !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestKeysetFilterPagesThroughEveryProductOnce(t *testing.T) {
+	at := func(h int) *time.Time {
+		t := time.Date(2026, time.May, 1, h, 0, 0, 0, time.UTC)
+		return &t
+	}
+	// Names, quantities and update times repeat so pages have to break ties
+	// on ProductID, and two products were never updated.
+	var products []models.Products
+	for i, p := range []struct {
+		name    string
+		qty     int
+		updated *time.Time
+	}{
+		{"Beans", 2, at(1)}, {"Rice", 2, at(3)}, {"Beans", 0, nil}, {"Apples", 5, at(1)},
+		{"Rice", 7, at(2)}, {"Beans", 2, at(3)}, {"Oats", 0, nil}, {"Apples", 5, at(2)},
+	} {
+		products = append(products, models.Products{
+			ProductID:   uuid.MustParse("00000000-0000-4000-8000-00000000000" + string(rune('1'+i))),
+			ProductName: p.name,
+			ProductQty:  p.qty,
+			UpdatedAt:   p.updated,
+		})
+	}
+	sortValue := func(p models.Products, key string) interface{} {
+		switch key {
+		case "name":
+			return p.ProductName
+		case "quantity":
+			return p.ProductQty
+		default:
+			if p.UpdatedAt == nil {
+				return nil
+			}
+			return *p.UpdatedAt
+		}
+	}
+
+	for sortKey, field := range productSortFields {
+		for _, desc := range []bool{false, true} {
+			name := sortKey + "/asc"
+			if desc {
+				name = sortKey + "/desc"
+			}
+			t.Run(name, func(t *testing.T) {
+				want := append([]models.Products(nil), products...)
+				sort.Slice(want, func(i, j int) bool {
+					c := compareSortValues(sortValue(want[i], sortKey), sortValue(want[j], sortKey))
+					if c == 0 {
+						c = compareSortValues(want[i].ProductID, want[j].ProductID)
+					}
+					if desc {
+						return c > 0
+					}
+					return c < 0
+				})
+
+				var got []uuid.UUID
+				var raw string
+				for page := 0; page < len(products); page++ {
+					remaining := want
+					if raw != "" {
+						cursor, err := decodeProductCursor(raw)
+						if err != nil {
+							t.Fatalf("decoding %q: %v", raw, err)
+						}
+						filter := keysetFilter(field, cursor.value(), cursor.ProductID, desc)
+						remaining = nil
+						for _, p := range want {
+							doc := map[string]interface{}{field: sortValue(p, sortKey), "ProductID": p.ProductID}
+							if doc[field] == nil {
+								delete(doc, field)
+							}
+							if matchesFilter(doc, filter) {
+								remaining = append(remaining, p)
+							}
+						}
+					}
+					n := min(3, len(remaining))
+					for _, p := range remaining[:n] {
+						got = append(got, p.ProductID)
+					}
+					if n == len(remaining) {
+						break
+					}
+					last := remaining[n-1]
+					next := productCursor{Sort: sortKey, Desc: desc, ProductID: last.ProductID, Name: last.ProductName, Qty: last.ProductQty, UpdatedAt: last.UpdatedAt}
+					raw = next.encode()
+				}
+
+				if len(got) != len(want) {
+					t.Fatalf("paged through %d products, want %d", len(got), len(want))
+				}
+				for i := range want {
+					if got[i] != want[i].ProductID {
+						t.Fatalf("product %d = %s, want %s", i, got[i], want[i].ProductID)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestProductCursorRoundTrip(t *testing.T) {
+	updated := time.Date(2026, time.May, 1, 12, 30, 0, 0, time.UTC)
+	for _, pc := range []productCursor{
+		{Sort: "name", Name: "Beans", ProductID: uuid.New()},
+		{Sort: "quantity", Desc: true, ProductID: uuid.New()},
+		{Sort: "updated", UpdatedAt: &updated, ProductID: uuid.New()},
+		{Sort: "updated", Desc: true, ProductID: uuid.New()},
+	} {
+		got, err := decodeProductCursor(pc.encode())
+		if err != nil {
+			t.Fatalf("decoding %+v: %v", pc, err)
+		}
+		if got.Sort != pc.Sort || got.Desc != pc.Desc || got.ProductID != pc.ProductID || got.value() != pc.value() {
+			t.Errorf("round trip of %+v = %+v", pc, got)
+		}
+	}
+}
+
+func TestListProductsRejectsBadCursors(t *testing.T) {
+	app := fiber.New()
+	app.Get("/api/products", ListProducts)
+
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	nameCursor := productCursor{Sort: "name", Name: "Beans", ProductID: uuid.New()}.encode()
+
+	tests := []struct {
+		name   string
+		cursor string
+		query  url.Values
+	}{
+		{"not base64", "%%%", nil},
+		{"not JSON", encode("Beans"), nil},
+		{"truncated", nameCursor[:len(nameCursor)-4], nil},
+		{"wrong value type", encode(`{"s":"quantity","q":"many","id":"` + uuid.NewString() + `"}`), url.Values{"sort": {"quantity"}}},
+		{"bad product ID", encode(`{"s":"name","n":"Beans","id":"not-a-uuid"}`), nil},
+		{"other sort key", nameCursor, url.Values{"sort": {"quantity"}}},
+		{"other order", nameCursor, url.Values{"order": {"desc"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := url.Values{"stockId": {uuid.NewString()}, "cursor": {tt.cursor}}
+			for k, v := range tt.query {
+				query[k] = v
+			}
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/products?"+query.Encode(), nil), -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("status %d, want 400", resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestListProductsRejectsPageNumbers(t *testing.T) {
+	app := fiber.New()
+	app.Get("/api/products", ListProducts)
+
+	for _, query := range []url.Values{
+		{"stockId": {uuid.NewString()}, "page": {"3"}},
+		{"stockId": {uuid.NewString()}, "page": {"2"}, "cursor": {productCursor{Sort: "name", ProductID: uuid.New()}.encode()}},
+	} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/products?"+query.Encode(), nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", query.Encode(), resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"my-backend/internal/db"
+	mailer "my-backend/internal/mail"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	maxDisplayNameLength = 100
+	maxAvatarURLLength   = 2048
+)
+
+// updateProfileRequest only changes the fields that are present; an empty
+// string clears the field.
+type updateProfileRequest struct {
+	DisplayName *string `json:"DisplayName"`
+	AvatarURL   *string `json:"AvatarURL"`
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"CurrentPassword"`
+	NewPassword     string `json:"NewPassword"`
+}
+
+type changeEmailRequest struct {
+	NewEmail string `json:"NewEmail"`
+	Password string `json:"Password"`
+}
+
+// GetProfile godoc
+// @Summary      Get current user
+// @Description  Returns the authenticated user's profile. Requires a signed-in session; API keys are rejected.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.Users
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Router       /api/me [get]
+func GetProfile(c *fiber.Ctx) error {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+	}
+	return c.JSON(user)
+}
+
+// UpdateProfile godoc
+// @Summary      Update current user
+// @Description  Updates DisplayName and/or AvatarURL of the authenticated user. Fields left out are unchanged; an empty string clears them.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        payload  body      updateProfileRequest  true  "Profile fields to change"
+// @Success      200  {object}  models.Users
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/me [patch]
+func UpdateProfile(c *fiber.Ctx) error {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+	}
+
+	var req updateProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	set := bson.M{}
+	unset := bson.M{}
+
+	if req.DisplayName != nil {
+		name := strings.TrimSpace(*req.DisplayName)
+		if utf8.RuneCountInString(name) > maxDisplayNameLength {
+			return fiber.NewError(fiber.StatusBadRequest, "DisplayName is too long")
+		}
+		if name == "" {
+			unset["DisplayName"] = ""
+		} else {
+			set["DisplayName"] = name
+		}
+	}
+
+	if req.AvatarURL != nil {
+		avatar := strings.TrimSpace(*req.AvatarURL)
+		if avatar == "" {
+			unset["AvatarURL"] = ""
+		} else {
+			if err := validateAvatarURL(avatar); err != nil {
+				return err
+			}
+			set["AvatarURL"] = avatar
+		}
+	}
+
+	if len(set) == 0 && len(unset) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "nothing to update")
+	}
+
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var updated models.Users
+	err = collection.FindOneAndUpdate(ctx,
+		bson.M{"UserId": user.UserID},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid credentials")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to update profile")
+	}
+	recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntityUser, user.UserID, uuid.Nil, user, updated)
+
+	return c.JSON(updated)
+}
+
+// ChangePassword godoc
+// @Summary      Change password
+// @Description  Sets a new password after checking the current one. Accounts created through OIDC have no password yet and set their first one without CurrentPassword after a sign-in within the last 10 minutes. Every other session of the user is revoked; the calling session stays signed in.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        payload  body      changePasswordRequest  true  "Current and new password"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/me/password [put]
+func ChangePassword(c *fiber.Ctx) error {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+	}
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req changePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+	// Accounts created through OIDC have no password yet and set their first
+	// one here after a fresh sign-in.
+	if req.NewPassword == "" || (user.PasswordHash != "" && req.CurrentPassword == "") {
+		return fiber.NewError(fiber.StatusBadRequest, "CurrentPassword and NewPassword are required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := confirmIdentity(ctx, c, user, req.CurrentPassword); err != nil {
+		return err
+	}
+	if err := validatePassword(req.NewPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to hash password")
+	}
+
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	if _, err := collection.UpdateOne(ctx,
+		bson.M{"UserId": user.UserID},
+		bson.M{"$set": bson.M{"PasswordHash": string(hashedPassword)}},
+	); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to update password")
+	}
+
+	filter := bson.M{"UserID": userUUID}
+	if sessionID, ok := middleware.CurrentSessionID(c); ok {
+		filter["SessionID"] = bson.M{"$ne": sessionID}
+	}
+	revoked, err := revokeSessions(ctx, filter, "password changed")
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to revoke sessions")
+	}
+	method := "change"
+	if user.PasswordHash == "" {
+		method = "set"
+	}
+	recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntityPassword, user.UserID, uuid.Nil, nil,
+		fiber.Map{"Method": method, "RevokedSessions": revoked})
+
+	return c.JSON(fiber.Map{
+		"message":          "password updated",
+		"revoked_sessions": revoked,
+	})
+}
+
+// ChangeEmail godoc
+// @Summary      Change email address
+// @Description  Sends a verification link to the new address. The account email only changes once the link is opened via /api/verify-email. Requires the password, or a sign-in within the last 10 minutes for accounts without one.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        payload  body      changeEmailRequest  true  "New email and current password"
+// @Success      202  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/me/email [post]
+func ChangeEmail(c *fiber.Ctx) error {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+	}
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req changeEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := confirmIdentity(ctx, c, user, req.Password); err != nil {
+		return err
+	}
+
+	email, err := normalizeEmail(req.NewEmail)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(email, user.Email) {
+		return fiber.NewError(fiber.StatusBadRequest, "NewEmail is the current address")
+	}
+
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	// VerifyEmail rejects duplicates through the unique index as well; checking
+	// here just gives a clear error before a link is sent.
+	taken, err := collection.CountDocuments(ctx, bson.M{"Email": email})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to check email")
+	}
+	if taken > 0 {
+		return fiber.NewError(fiber.StatusConflict, "email is already used by another account")
+	}
+
+	if err := sendEmailVerification(ctx, userUUID, email); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to create verification token")
+	}
+
+	// Let the current address know, so a hijacked session cannot move the
+	// account away silently.
+	notice := mailer.Message{
+		To:      user.Email,
+		Subject: "Email change requested",
+		Body: "Someone asked to change the email address of your warehouse account to " + email + ".\n\n" +
+			"If this was not you, change your password and sign out of all sessions.",
+	}
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := mailer.DefaultSender().Send(sendCtx, notice); err != nil {
+			log.Printf("failed to send email change notice: %v", err)
+		}
+	}()
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "verification link sent to the new address"})
+}
+
+func validateAvatarURL(raw string) error {
+	if len(raw) > maxAvatarURLLength {
+		return fiber.NewError(fiber.StatusBadRequest, "AvatarURL is too long")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "AvatarURL must be an http or https URL")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// reauthWindow is how recent the sign-in behind the current session must be
+// for an account without a password to confirm a sensitive change.
+const reauthWindow = 10 * time.Minute
+
+// errReauthRequired asks a passwordless user to sign in again before retrying.
+var errReauthRequired = fiber.NewError(fiber.StatusUnauthorized, "sign in again to confirm this change")
+
+// confirmIdentity re-authenticates the user before a sensitive account
+// change. Users with a password must enter it. Accounts created through OIDC
+// have none, so they confirm by a fresh sign-in: the session the request uses
+// must have been created within reauthWindow. Refreshing a session does not
+// count, only a new login does.
+func confirmIdentity(ctx context.Context, c *fiber.Ctx, user models.Users, password string) error {
+	if user.PasswordHash != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid credentials")
+		}
+		return nil
+	}
+
+	sessionID, ok := middleware.CurrentSessionID(c)
+	if !ok {
+		return errReauthRequired
+	}
+
+	collection, err := db.SessionsCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var session models.Sessions
+	if err := collection.FindOne(ctx, bson.M{"SessionID": sessionID}).Decode(&session); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return errReauthRequired
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch session")
+	}
+	if time.Since(session.CreatedAt) > reauthWindow {
+		return errReauthRequired
+	}
+	return nil
+}
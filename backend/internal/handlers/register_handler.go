@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"os"
 	"strings"
 	"time"
 
@@ -23,7 +24,7 @@ type registerRequest struct {
 
 // RegisterUser godoc
 // @Summary      Register a new user
-// @Description  Creates a new user document with a hashed password.
+// @Description  Creates a new user document with a hashed password in PENDING_VERIFICATION status and emails a verification link. The password must be at least 8 characters.
 // @Tags         users
 // @Accept       json
 // @Produce      json
@@ -46,6 +47,15 @@ func RegisterUser(c *fiber.Ctx) error {
 	if req.Email == "" || req.Password == "" {
 		return fiber.NewError(fiber.StatusBadRequest, "email and password are required")
 	}
+	if err := validatePassword(req.Password); err != nil {
+		return err
+	}
+
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return err
+	}
+	req.Email = email
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -60,21 +70,40 @@ func RegisterUser(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
 	}
 
+	userUUID := uuid.New()
 	user := models.Users{
-		UserID:       uuid.New().String(),
+		UserID:       userUUID.String(),
 		Email:        req.Email,
 		DisplayName:  req.DisplayName,
 		PasswordHash: string(hashedPassword),
 		AvatarURL:    req.AvatarURL,
-		Status:       "ACTIVE",
+		Status:       models.UserStatusPendingVerification,
+		Role:         roleForEmail(req.Email),
+		CreatedAt:    time.Now().UTC(),
 	}
 
 	if _, err := collection.InsertOne(ctx, user); err != nil {
 		if mongo.IsDuplicateKeyError(err) {
-			return fiber.NewError(fiber.StatusConflict, err.Error())
+			return fiber.NewError(fiber.StatusConflict, "email is already used by another account")
 		}
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to create user")
 	}
+	recordAuditAs(ctx, c, userUUID, models.AuditActionCreate, models.AuditEntityUser, user.UserID, uuid.Nil, nil, user)
+
+	if err := sendEmailVerification(ctx, userUUID, user.Email); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to create verification token")
+	}
 
 	return c.Status(fiber.StatusCreated).JSON(user)
 }
+
+// roleForEmail grants the admin role to addresses listed in ADMIN_EMAILS
+// (comma separated) so a fresh instance can bootstrap its first admin.
+func roleForEmail(email string) string {
+	for _, admin := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if admin = strings.TrimSpace(admin); admin != "" && strings.EqualFold(admin, email) {
+			return models.RoleAdmin
+		}
+	}
+	return models.RoleUser
+}
@@ -0,0 +1,36 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestRegisterRejectsShortPasswords(t *testing.T) {
+	app := newApp(t)
+
+	body := fiber.Map{"Email": "short-" + uuid.NewString()[:8] + "@example.com", "Password": "1234567"}
+	if resp, raw := do(t, app, http.MethodPost, "/api/register", "", body); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status %d, want 400: %s", resp.StatusCode, raw)
+	}
+}
+
+func TestRegisterDuplicateEmail(t *testing.T) {
+	app := newApp(t)
+	email := "dup-" + uuid.NewString()[:8] + "@example.com"
+
+	body := fiber.Map{"Email": email, "Password": testPassword}
+	if status := doJSON(t, app, http.MethodPost, "/api/register", "", body, nil); status != http.StatusCreated {
+		t.Fatalf("first registration: status %d", status)
+	}
+
+	resp, raw := do(t, app, http.MethodPost, "/api/register", "", body)
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("second registration: status %d, want 409: %s", resp.StatusCode, raw)
+	}
+	if got, want := string(raw), "email is already used by another account"; got != want {
+		t.Errorf("second registration: body %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"my-backend/internal/auth"
+	"my-backend/internal/db"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxPreviousTokenHashes bounds how many rotated refresh tokens are remembered
+// per session for reuse detection.
+const maxPreviousTokenHashes = 20
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"RefreshToken"`
+}
+
+// startSession creates a new session for the user and returns the tokens the
+// client needs to authenticate subsequent requests.
+func startSession(ctx context.Context, c *fiber.Ctx, user models.Users) (loginResponse, error) {
+	userUUID, err := uuid.Parse(user.UserID)
+	if err != nil {
+		return loginResponse{}, fiber.NewError(fiber.StatusInternalServerError, "stored user ID is not a valid UUID")
+	}
+
+	refreshToken, refreshHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return loginResponse{}, fiber.NewError(fiber.StatusInternalServerError, "failed to generate refresh token")
+	}
+
+	collection, err := db.SessionsCollection(ctx)
+	if err != nil {
+		return loginResponse{}, fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	now := time.Now().UTC()
+	session := models.Sessions{
+		SessionID:        uuid.New(),
+		UserID:           userUUID,
+		RefreshTokenHash: refreshHash,
+		UserAgent:        c.Get(fiber.HeaderUserAgent),
+		IPAddress:        c.IP(),
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(auth.RefreshTokenTTL()),
+	}
+
+	if _, err := collection.InsertOne(ctx, session); err != nil {
+		return loginResponse{}, fiber.NewError(fiber.StatusInternalServerError, "failed to create session")
+	}
+
+	return sessionResponse(user, session, refreshToken)
+}
+
+func sessionResponse(user models.Users, session models.Sessions, refreshToken string) (loginResponse, error) {
+	accessToken, expiresAt, err := auth.IssueAccessToken(user.UserID, session.SessionID.String())
+	if err != nil {
+		return loginResponse{}, fiber.NewError(fiber.StatusInternalServerError, "failed to issue access token")
+	}
+
+	return loginResponse{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+		SessionID:        session.SessionID,
+		User:             user,
+	}, nil
+}
+
+// revokeSessions marks every active session matching filter as revoked.
+func revokeSessions(ctx context.Context, filter bson.M, reason string) (int64, error) {
+	collection, err := db.SessionsCollection(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	filter["RevokedAt"] = nil
+	res, err := collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{
+		"RevokedAt":    time.Now().UTC(),
+		"RevokeReason": reason,
+	}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// RefreshToken godoc
+// @Summary      Refresh access token
+// @Description  Exchanges a refresh token for a new access token and a rotated refresh token. Presenting an already rotated refresh token revokes the whole session.
+// @Tags         sessions
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      refreshTokenRequest  true  "Refresh token"
+// @Success      200  {object}  loginResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/token/refresh [post]
+func RefreshToken(c *fiber.Ctx) error {
+	var req refreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	req.RefreshToken = strings.TrimSpace(req.RefreshToken)
+	if req.RefreshToken == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "RefreshToken is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sessionsCol, err := db.SessionsCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	usersCol, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	presentedHash := auth.HashOpaqueToken(req.RefreshToken)
+	newToken, newHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to generate refresh token")
+	}
+
+	now := time.Now().UTC()
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	res := sessionsCol.FindOneAndUpdate(ctx,
+		bson.M{
+			"RefreshTokenHash": presentedHash,
+			"RevokedAt":        nil,
+			"ExpiresAt":        bson.M{"$gt": now},
+		},
+		bson.M{
+			"$set": bson.M{
+				"RefreshTokenHash": newHash,
+				"LastUsedAt":       now,
+				"ExpiresAt":        now.Add(auth.RefreshTokenTTL()),
+				"UserAgent":        c.Get(fiber.HeaderUserAgent),
+				"IPAddress":        c.IP(),
+			},
+			"$push": bson.M{"PreviousTokenHashes": bson.M{
+				"$each":  bson.A{presentedHash},
+				"$slice": -maxPreviousTokenHashes,
+			}},
+		},
+		opts,
+	)
+
+	var session models.Sessions
+	if err := res.Decode(&session); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to refresh session")
+		}
+
+		// A token that was already rotated away is being replayed: assume it was
+		// stolen and revoke the session so neither copy can be used again.
+		revoked, err := revokeSessions(ctx, bson.M{"PreviousTokenHashes": presentedHash}, "refresh token reuse detected")
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to revoke session")
+		}
+		if revoked > 0 {
+			return fiber.NewError(fiber.StatusUnauthorized, "refresh token reuse detected; session revoked")
+		}
+		return fiber.NewError(fiber.StatusUnauthorized, "invalid refresh token")
+	}
+
+	var user models.Users
+	if err := usersCol.FindOne(ctx, bson.M{"UserId": session.UserID.String()}).Decode(&user); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid refresh token")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch user")
+	}
+
+	if err := middleware.AccountStatusError(user); err != nil {
+		return err
+	}
+
+	resp, err := sessionResponse(user, session, newToken)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(resp)
+}
+
+// Logout godoc
+// @Summary      Logout
+// @Description  Revokes the session the access token belongs to.
+// @Tags         sessions
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/logout [post]
+func Logout(c *fiber.Ctx) error {
+	sessionID, ok := middleware.CurrentSessionID(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	revoked, err := revokeSessions(ctx, bson.M{"SessionID": sessionID}, "logout")
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to revoke session")
+	}
+	if revoked > 0 {
+		recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntitySession, sessionID.String(), uuid.Nil,
+			nil, fiber.Map{"RevokeReason": "logout"})
+	}
+
+	return c.JSON(fiber.Map{
+		"revoked_sessions": revoked,
+	})
+}
+
+// LogoutAll godoc
+// @Summary      Logout everywhere
+// @Description  Revokes every session of the authenticated user, including the current one.
+// @Tags         sessions
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/logout-all [post]
+func LogoutAll(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sessions, err := findAll[models.Sessions](ctx, db.SessionsCollection, bson.M{"UserID": userUUID, "RevokedAt": nil})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch sessions")
+	}
+	revoked, err := revokeSessions(ctx, bson.M{"UserID": userUUID}, "logout all")
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to revoke sessions")
+	}
+	for _, session := range sessions {
+		recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntitySession, session.SessionID.String(), uuid.Nil,
+			nil, fiber.Map{"RevokeReason": "logout all"})
+	}
+
+	return c.JSON(fiber.Map{
+		"revoked_sessions": revoked,
+	})
+}
+
+// ListSessions godoc
+// @Summary      List sessions
+// @Description  Returns the active sessions (signed-in devices) of the authenticated user.
+// @Tags         sessions
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   models.Sessions
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/sessions [get]
+func ListSessions(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection, err := db.SessionsCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "LastUsedAt", Value: -1}})
+	cursor, err := collection.Find(ctx, bson.M{
+		"UserID":    userUUID,
+		"RevokedAt": nil,
+		"ExpiresAt": bson.M{"$gt": time.Now()},
+	}, opts)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch sessions")
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []models.Sessions
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to decode sessions")
+	}
+
+	return c.JSON(sessions)
+}
+
+// RevokeSession godoc
+// @Summary      Revoke a session
+// @Description  Signs out one device of the authenticated user, e.g. a lost phone.
+// @Tags         sessions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        sessionId  path  string  true  "Session ID (UUID)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/sessions/{sessionId} [delete]
+func RevokeSession(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	sessionIDParam := strings.TrimSpace(c.Params("sessionId"))
+	if sessionIDParam == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "sessionId is required")
+	}
+
+	sessionUUID, err := uuid.Parse(sessionIDParam)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "sessionId must be a valid UUID")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	revoked, err := revokeSessions(ctx, bson.M{"SessionID": sessionUUID, "UserID": userUUID}, "revoked by user")
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to revoke session")
+	}
+	if revoked == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "session not found")
+	}
+	recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntitySession, sessionUUID.String(), uuid.Nil,
+		nil, fiber.Map{"RevokeReason": "revoked by user"})
+
+	return c.JSON(fiber.Map{
+		"revoked_sessions": revoked,
+	})
+}
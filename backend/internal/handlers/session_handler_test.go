@@ -0,0 +1,49 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"my-backend/internal/db"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestLogoutRecordsSessionRevocation(t *testing.T) {
+	app := newApp(t)
+	user := insertUser(t, models.Users{})
+
+	var login struct {
+		AccessToken string    `json:"AccessToken"`
+		SessionID   uuid.UUID `json:"SessionID"`
+	}
+	body := fiber.Map{"Email": user.Email, "Password": testPassword}
+	if status := doJSON(t, app, http.MethodPost, "/api/login", "", body, &login); status != http.StatusOK {
+		t.Fatalf("login: status %d", status)
+	}
+	if status := doJSON(t, app, http.MethodPost, "/api/logout", login.AccessToken, nil, nil); status != http.StatusOK {
+		t.Fatalf("logout: status %d", status)
+	}
+
+	ctx := context.Background()
+	collection, err := db.AuditLogCollection(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry models.AuditLog
+	filter := bson.M{"EntityType": models.AuditEntitySession, "EntityID": login.SessionID.String()}
+	if err := collection.FindOne(ctx, filter).Decode(&entry); err != nil {
+		t.Fatalf("finding the audit entry: %v", err)
+	}
+	if entry.ActorID.String() != user.UserID || entry.Action != models.AuditActionUpdate || entry.After["RevokeReason"] != "logout" {
+		t.Errorf("audit entry = %+v", entry)
+	}
+
+	if resp, _ := do(t, app, http.MethodGet, "/api/me", login.AccessToken, nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET /api/me after logout: status %d, want 401", resp.StatusCode)
+	}
+}
@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/inventory"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type shareStockRequest struct {
+	Email string `json:"Email"`
+	Role  string `json:"Role"`
+}
+
+type transferStockRequest struct {
+	Email string `json:"Email"`
+}
+
+type stockShareView struct {
+	models.StockShares
+	Email       string `json:"Email"`
+	DisplayName string `json:"DisplayName,omitempty"`
+}
+
+// ListStockShares godoc
+// @Summary      List stock shares
+// @Description  Returns the users a stock is shared with. Stock owners only.
+// @Tags         warehouse
+// @Produce      json
+// @Security     BearerAuth
+// @Param        stockId  path  string  true  "Stock ID (UUID)"
+// @Success      200  {array}   stockShareView
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/warehouse/{stockId}/shares [get]
+func ListStockShares(c *fiber.Ctx) error {
+	stockUUID, err := stockIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := authorizeStock(ctx, c, stockUUID, inventory.AccessOwner); err != nil {
+		return err
+	}
+
+	shares, err := findAll[models.StockShares](ctx, db.StockSharesCollection, bson.M{"StockID": stockUUID})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch shares")
+	}
+
+	userIDs := make(bson.A, 0, len(shares))
+	for _, share := range shares {
+		userIDs = append(userIDs, share.UserID.String())
+	}
+	users, err := findAll[models.Users](ctx, db.UsersCollection, bson.M{"UserId": bson.M{"$in": userIDs}})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch users")
+	}
+	usersByID := make(map[string]models.Users, len(users))
+	for _, u := range users {
+		usersByID[u.UserID] = u
+	}
+
+	result := make([]stockShareView, 0, len(shares))
+	for _, share := range shares {
+		u := usersByID[share.UserID.String()]
+		result = append(result, stockShareView{StockShares: share, Email: u.Email, DisplayName: u.DisplayName})
+	}
+
+	return c.JSON(result)
+}
+
+// ShareStock godoc
+// @Summary      Share a stock
+// @Description  Gives the user with the given email viewer or editor access to the stock. Sharing again with the same user changes their role. Stock owners only.
+// @Tags         warehouse
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        stockId  path      string             true  "Stock ID (UUID)"
+// @Param        payload  body      shareStockRequest  true  "Email of the user and role"
+// @Success      200  {object}  models.StockShares
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/warehouse/{stockId}/shares [post]
+func ShareStock(c *fiber.Ctx) error {
+	stockUUID, err := stockIDParam(c)
+	if err != nil {
+		return err
+	}
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req shareStockRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	if _, ok := inventory.StockShareAccess[role]; !ok {
+		return fiber.NewError(fiber.StatusBadRequest, "Role must be viewer or editor")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stock, err := authorizeStock(ctx, c, stockUUID, inventory.AccessOwner)
+	if err != nil {
+		return err
+	}
+
+	target, err := shareTargetByEmail(ctx, req.Email)
+	if err != nil {
+		return err
+	}
+	targetUUID, err := uuid.Parse(target.UserID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "stored user ID is not a valid UUID")
+	}
+	if targetUUID == stock.UserID {
+		return fiber.NewError(fiber.StatusBadRequest, "the stock owner already has full access")
+	}
+
+	collection, err := db.StockSharesCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var previous *models.StockShares
+	if err := collection.FindOne(ctx, bson.M{"StockID": stockUUID, "UserID": targetUUID}).Decode(&previous); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch share")
+	}
+
+	var share models.StockShares
+	err = collection.FindOneAndUpdate(ctx,
+		bson.M{"StockID": stockUUID, "UserID": targetUUID},
+		bson.M{
+			"$set":         bson.M{"Role": role, "SharedBy": userUUID},
+			"$setOnInsert": bson.M{"CreatedAt": time.Now().UTC()},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&share)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to share stock")
+	}
+
+	if previous == nil {
+		recordAudit(ctx, c, models.AuditActionCreate, models.AuditEntityStockShare, targetUUID.String(), stockUUID, nil, share)
+	} else {
+		recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntityStockShare, targetUUID.String(), stockUUID, previous, share)
+	}
+
+	return c.JSON(share)
+}
+
+// RevokeStockShare godoc
+// @Summary      Revoke a stock share
+// @Description  Removes a user's access to the stock. Stock owners can revoke anyone; a user can also remove their own share.
+// @Tags         warehouse
+// @Produce      json
+// @Security     BearerAuth
+// @Param        stockId  path  string  true  "Stock ID (UUID)"
+// @Param        userId   path  string  true  "User ID (UUID)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/warehouse/{stockId}/shares/{userId} [delete]
+func RevokeStockShare(c *fiber.Ctx) error {
+	stockUUID, err := stockIDParam(c)
+	if err != nil {
+		return err
+	}
+	targetUUID, err := uuid.Parse(strings.TrimSpace(c.Params("userId")))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "userId must be a valid UUID")
+	}
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	required := inventory.AccessOwner
+	if targetUUID == userUUID {
+		required = inventory.AccessViewer
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := authorizeStock(ctx, c, stockUUID, required); err != nil {
+		return err
+	}
+
+	collection, err := db.StockSharesCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var share models.StockShares
+	if err := collection.FindOneAndDelete(ctx, bson.M{"StockID": stockUUID, "UserID": targetUUID}).Decode(&share); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusNotFound, "share not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to revoke share")
+	}
+	recordAudit(ctx, c, models.AuditActionDelete, models.AuditEntityStockShare, targetUUID.String(), stockUUID, share, nil)
+
+	return c.JSON(fiber.Map{
+		"revoked_shares": 1,
+	})
+}
+
+// TransferStock godoc
+// @Summary      Transfer stock ownership
+// @Description  Makes another user the owner of the stock. The previous owner keeps editor access through a share and the stock leaves its household. Only the current owner can transfer a stock.
+// @Tags         warehouse
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        stockId  path      string                true  "Stock ID (UUID)"
+// @Param        payload  body      transferStockRequest  true  "Email of the new owner"
+// @Success      200  {object}  models.Warehouse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/warehouse/{stockId}/transfer [post]
+func TransferStock(c *fiber.Ctx) error {
+	stockUUID, err := stockIDParam(c)
+	if err != nil {
+		return err
+	}
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req transferStockRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stock, err := authorizeStock(ctx, c, stockUUID, inventory.AccessOwner)
+	if err != nil {
+		return err
+	}
+
+	target, err := shareTargetByEmail(ctx, req.Email)
+	if err != nil {
+		return err
+	}
+	targetUUID, err := uuid.Parse(target.UserID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "stored user ID is not a valid UUID")
+	}
+	if targetUUID == userUUID {
+		return fiber.NewError(fiber.StatusBadRequest, "you already own this stock")
+	}
+
+	warehouseCol, err := db.WarehouseCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	sharesCol, err := db.StockSharesCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	// Matching on the current owner keeps two concurrent transfers from both
+	// succeeding. The stock leaves its household: that household belongs to
+	// the previous owner's circle, not the new owner's.
+	var updated models.Warehouse
+	err = warehouseCol.FindOneAndUpdate(ctx,
+		bson.M{"StockID": stockUUID, "UserID": userUUID},
+		bson.M{
+			"$set":   bson.M{"UserID": targetUUID},
+			"$unset": bson.M{"HouseholdID": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusConflict, "stock ownership changed concurrently")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to transfer stock")
+	}
+
+	if _, err := sharesCol.DeleteOne(ctx, bson.M{"StockID": stockUUID, "UserID": targetUUID}); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to update shares")
+	}
+	if _, err := sharesCol.UpdateOne(ctx,
+		bson.M{"StockID": stockUUID, "UserID": userUUID},
+		bson.M{
+			"$set":         bson.M{"Role": models.StockShareRoleEditor, "SharedBy": targetUUID},
+			"$setOnInsert": bson.M{"CreatedAt": time.Now().UTC()},
+		},
+		options.Update().SetUpsert(true),
+	); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to update shares")
+	}
+	recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntityStock, stockUUID.String(), stockUUID, stock, updated)
+
+	return c.JSON(updated)
+}
+
+// errShareTarget is returned for every email a stock cannot be shared with or
+// transferred to, so the endpoints do not reveal which emails are registered.
+var errShareTarget = fiber.NewError(fiber.StatusBadRequest, "cannot share with that user")
+
+// shareTargetByEmail loads the active account registered with email. Unknown
+// emails and accounts that cannot sign in both yield errShareTarget.
+func shareTargetByEmail(ctx context.Context, raw string) (models.Users, error) {
+	email, err := normalizeEmail(raw)
+	if err != nil {
+		return models.Users{}, err
+	}
+
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		return models.Users{}, fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var user models.Users
+	if err := collection.FindOne(ctx, bson.M{"Email": email}).Decode(&user); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Users{}, errShareTarget
+		}
+		return models.Users{}, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch user")
+	}
+	if !user.IsActive() {
+		return models.Users{}, errShareTarget
+	}
+	return user, nil
+}
+
+func stockIDParam(c *fiber.Ctx) (uuid.UUID, error) {
+	stockIDParam := strings.TrimSpace(c.Params("stockId"))
+	if stockIDParam == "" {
+		return uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "stockId is required")
+	}
+
+	stockUUID, err := uuid.Parse(stockIDParam)
+	if err != nil {
+		return uuid.Nil, fiber.NewError(fiber.StatusBadRequest, "stockId must be a valid UUID")
+	}
+	return stockUUID, nil
+}
@@ -0,0 +1,538 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"my-backend/internal/db"
+	"my-backend/internal/inventory"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const maxShoppingItemNameLength = 200
+
+type shoppingListItemRequest struct {
+	Name      string `json:"Name"`
+	Quantity  int    `json:"Quantity"`
+	Unit      string `json:"Unit"`
+	ProductID string `json:"ProductID"`
+}
+
+type updateShoppingListItemRequest struct {
+	Name     *string `json:"Name"`
+	Quantity *int    `json:"Quantity"`
+	Unit     *string `json:"Unit"`
+	Checked  *bool   `json:"Checked"`
+}
+
+// shoppingListEntry is an item as listed, with the name of its stock.
+type shoppingListEntry struct {
+	models.ShoppingListItems
+	StockName string `json:"StockName,omitempty"`
+}
+
+type receiveShoppingListResponse struct {
+	Received     []models.StockMovements `json:"Received"`
+	Skipped      []receiveSkippedItem    `json:"Skipped"`
+	RemovedItems int                     `json:"RemovedItems"`
+}
+
+type receiveSkippedItem struct {
+	ItemID uuid.UUID `json:"ItemID"`
+	Name   string    `json:"Name"`
+	Reason string    `json:"Reason"`
+}
+
+// GetShoppingList godoc
+// @Summary      Get shopping list
+// @Description  Returns the authenticated user's shopping list. Every low-stock product in the stocks the user can access is added automatically with its suggested reorder quantity and removed again once it has recovered, unless it was already checked off. API keys without the products:write scope get the list without this update. Unchecked items come first.
+// @Tags         shopping-list
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   shoppingListEntry
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/shopping-list [get]
+func GetShoppingList(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter, err := accessibleStocksFilter(ctx, userUUID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to resolve stock access")
+	}
+	stocks, err := findAll[models.Warehouse](ctx, db.WarehouseCollection, filter)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch warehouse")
+	}
+
+	stockNames := make(map[uuid.UUID]string, len(stocks))
+	stockIDs := make(bson.A, 0, len(stocks))
+	for _, stock := range stocks {
+		stockNames[stock.StockID] = stock.StockName
+		stockIDs = append(stockIDs, stock.StockID)
+	}
+
+	// Syncing writes to the list, which a read-only API key may not do; it
+	// gets the list as the last sync left it.
+	if middleware.HasScope(c, models.ScopeProductsWrite) {
+		low, err := lowStockProducts(ctx, bson.M{"StockID": bson.M{"$in": stockIDs}})
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch low-stock products")
+		}
+		if err := syncShoppingList(ctx, userUUID, low); err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to update shopping list")
+		}
+	}
+
+	items, err := findAll[models.ShoppingListItems](ctx, db.ShoppingListCollection, bson.M{
+		"UserID":    userUUID,
+		"Dismissed": bson.M{"$ne": true},
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch shopping list")
+	}
+
+	entries := make([]shoppingListEntry, 0, len(items))
+	for _, item := range items {
+		entry := shoppingListEntry{ShoppingListItems: item}
+		if item.StockID != nil {
+			entry.StockName = stockNames[*item.StockID]
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Checked != b.Checked {
+			return !a.Checked
+		}
+		if a.StockName != b.StockName {
+			return a.StockName < b.StockName
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+
+	return c.JSON(entries)
+}
+
+// AddShoppingListItem godoc
+// @Summary      Add a shopping list item
+// @Description  Adds an item to the authenticated user's shopping list. With a ProductID the item is restocked into that product when the list is received; Name and Unit then default to the product's.
+// @Tags         shopping-list
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        payload  body      shoppingListItemRequest  true  "Item"
+// @Success      201  {object}  models.ShoppingListItems
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/shopping-list/items [post]
+func AddShoppingListItem(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req shoppingListItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	item := models.ShoppingListItems{
+		ItemID:    uuid.New(),
+		UserID:    userUUID,
+		Name:      strings.TrimSpace(req.Name),
+		Unit:      strings.TrimSpace(req.Unit),
+		Quantity:  req.Quantity,
+		Source:    models.ShoppingListSourceManual,
+		CreatedAt: time.Now().UTC(),
+	}
+	if item.Quantity == 0 {
+		item.Quantity = 1
+	}
+	if item.Quantity < 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "Quantity must be positive")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if productIDRaw := strings.TrimSpace(req.ProductID); productIDRaw != "" {
+		productUUID, err := uuid.Parse(productIDRaw)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "ProductID must be a valid UUID")
+		}
+		product, err := authorizeProduct(ctx, c, productUUID, inventory.AccessViewer)
+		if err != nil {
+			return err
+		}
+		item.ProductID = &product.ProductID
+		item.StockID = &product.StockID
+		if item.Name == "" {
+			item.Name = product.ProductName
+		}
+		if item.Unit == "" {
+			item.Unit = product.Unit
+		}
+	}
+
+	if err := validateShoppingItemName(item.Name); err != nil {
+		return err
+	}
+
+	collection, err := db.ShoppingListCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	if _, err := collection.InsertOne(ctx, item); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return fiber.NewError(fiber.StatusConflict, "this product is already on your shopping list")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to add item")
+	}
+	recordAudit(ctx, c, models.AuditActionCreate, models.AuditEntityShoppingItem, item.ItemID.String(), uuid.Nil, nil, item)
+
+	return c.Status(fiber.StatusCreated).JSON(item)
+}
+
+// UpdateShoppingListItem godoc
+// @Summary      Update a shopping list item
+// @Description  Changes an item's name, quantity or unit, or checks it off.
+// @Tags         shopping-list
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        itemId   path      string                         true  "Item ID (UUID)"
+// @Param        payload  body      updateShoppingListItemRequest  true  "Fields to update"
+// @Success      200  {object}  models.ShoppingListItems
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/shopping-list/items/{itemId} [patch]
+func UpdateShoppingListItem(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+	itemUUID, err := uuid.Parse(strings.TrimSpace(c.Params("itemId")))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "itemId must be a valid UUID")
+	}
+
+	var req updateShoppingListItemRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	set := bson.M{}
+	unset := bson.M{}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if err := validateShoppingItemName(name); err != nil {
+			return err
+		}
+		set["Name"] = name
+	}
+	if req.Quantity != nil {
+		if *req.Quantity <= 0 {
+			return fiber.NewError(fiber.StatusBadRequest, "Quantity must be positive")
+		}
+		set["Quantity"] = *req.Quantity
+	}
+	if req.Unit != nil {
+		set["Unit"] = strings.TrimSpace(*req.Unit)
+	}
+	if req.Checked != nil {
+		set["Checked"] = *req.Checked
+		if *req.Checked {
+			set["CheckedAt"] = time.Now().UTC()
+		} else {
+			unset["CheckedAt"] = ""
+		}
+	}
+	if len(set) == 0 && len(unset) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "provide at least one field to update")
+	}
+
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection, err := db.ShoppingListCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var before models.ShoppingListItems
+	err = collection.FindOneAndUpdate(ctx,
+		bson.M{"ItemID": itemUUID, "UserID": userUUID, "Dismissed": bson.M{"$ne": true}},
+		update,
+	).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusNotFound, "item not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to update item")
+	}
+
+	var updated models.ShoppingListItems
+	if err := collection.FindOne(ctx, bson.M{"ItemID": itemUUID}).Decode(&updated); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch item")
+	}
+	recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntityShoppingItem, itemUUID.String(), uuid.Nil, before, updated)
+
+	return c.JSON(updated)
+}
+
+// DeleteShoppingListItem godoc
+// @Summary      Remove a shopping list item
+// @Description  Removes an item from the list. An automatically added item stays hidden until its product has recovered and runs low again.
+// @Tags         shopping-list
+// @Produce      json
+// @Security     BearerAuth
+// @Param        itemId  path  string  true  "Item ID (UUID)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/shopping-list/items/{itemId} [delete]
+func DeleteShoppingListItem(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+	itemUUID, err := uuid.Parse(strings.TrimSpace(c.Params("itemId")))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "itemId must be a valid UUID")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection, err := db.ShoppingListCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	filter := bson.M{"ItemID": itemUUID, "UserID": userUUID, "Dismissed": bson.M{"$ne": true}}
+	var item models.ShoppingListItems
+	if err := collection.FindOne(ctx, filter).Decode(&item); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusNotFound, "item not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch item")
+	}
+
+	// Deleting an auto item outright would bring it straight back on the next
+	// sync, so it is hidden instead until the product recovers.
+	if item.Source == models.ShoppingListSourceAuto {
+		_, err = collection.UpdateOne(ctx, filter, bson.M{
+			"$set":   bson.M{"Dismissed": true, "Checked": false},
+			"$unset": bson.M{"CheckedAt": ""},
+		})
+	} else {
+		_, err = collection.DeleteOne(ctx, filter)
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to remove item")
+	}
+	recordAudit(ctx, c, models.AuditActionDelete, models.AuditEntityShoppingItem, itemUUID.String(), uuid.Nil, item, nil)
+
+	return c.JSON(fiber.Map{
+		"removed_items": 1,
+	})
+}
+
+// ReceiveShoppingList godoc
+// @Summary      Receive the checked shopping list items
+// @Description  Books every checked item into stock: items linked to a product get an IN movement of their quantity and are removed from the list; items without a product are simply removed. Items whose product is gone or that the user may not edit are skipped and stay on the list. Each item is booked at most once, even when the request is repeated or sent twice at the same time.
+// @Tags         shopping-list
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  receiveShoppingListResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/shopping-list/receive [post]
+func ReceiveShoppingList(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	items, err := findAll[models.ShoppingListItems](ctx, db.ShoppingListCollection, bson.M{
+		"UserID":    userUUID,
+		"Checked":   true,
+		"Dismissed": bson.M{"$ne": true},
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch shopping list")
+	}
+
+	collection, err := db.ShoppingListCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	result := receiveShoppingListResponse{
+		Received: []models.StockMovements{},
+		Skipped:  []receiveSkippedItem{},
+	}
+	for _, item := range items {
+		if item.ProductID != nil {
+			if _, err := authorizeProduct(ctx, c, *item.ProductID, inventory.AccessEditor); err != nil {
+				result.Skipped = append(result.Skipped, receiveSkippedItem{ItemID: item.ItemID, Name: item.Name, Reason: errorMessage(err)})
+				continue
+			}
+		}
+
+		// Claiming the item by removing it first means a concurrent or
+		// retried receive cannot book the same item into stock twice.
+		err := collection.FindOneAndDelete(ctx, bson.M{
+			"ItemID":    item.ItemID,
+			"UserID":    userUUID,
+			"Checked":   true,
+			"Dismissed": bson.M{"$ne": true},
+		}).Decode(&item)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to remove received item")
+		}
+
+		if item.ProductID != nil {
+			movement, _, err := applyMovement(ctx, c, *item.ProductID, movementInput{
+				Type:   models.MovementTypeIn,
+				Delta:  item.Quantity,
+				Reason: "shopping list",
+			})
+			if err != nil {
+				if err := restoreShoppingItem(ctx, collection, item); err != nil {
+					return fiber.NewError(fiber.StatusInternalServerError, "failed to restore skipped item")
+				}
+				result.Skipped = append(result.Skipped, receiveSkippedItem{ItemID: item.ItemID, Name: item.Name, Reason: errorMessage(err)})
+				continue
+			}
+			result.Received = append(result.Received, movement)
+		}
+		result.RemovedItems++
+	}
+
+	return c.JSON(result)
+}
+
+// restoreShoppingItem puts back an item ReceiveShoppingList claimed but could
+// not book into stock. If a sync has re-added the product in the meantime,
+// that item stands in for it.
+func restoreShoppingItem(ctx context.Context, collection *mongo.Collection, item models.ShoppingListItems) error {
+	if _, err := collection.InsertOne(ctx, item); err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	return nil
+}
+
+// syncShoppingList adds an auto item for every low-stock product that is not
+// on the user's list yet and drops unchecked auto items whose product is no
+// longer low, which also re-arms dismissed ones.
+func syncShoppingList(ctx context.Context, userID uuid.UUID, low []models.Products) error {
+	collection, err := db.ShoppingListCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	lowIDs := make(bson.A, 0, len(low))
+	for _, product := range low {
+		lowIDs = append(lowIDs, product.ProductID)
+	}
+
+	if _, err := collection.DeleteMany(ctx, bson.M{
+		"UserID":    userID,
+		"Source":    models.ShoppingListSourceAuto,
+		"ProductID": bson.M{"$nin": lowIDs},
+		"$or": bson.A{
+			bson.M{"Checked": false},
+			bson.M{"Dismissed": true},
+		},
+	}); err != nil {
+		return err
+	}
+
+	if len(low) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	writes := make([]mongo.WriteModel, 0, len(low))
+	for _, product := range low {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"UserID": userID, "ProductID": product.ProductID}).
+			SetUpdate(bson.M{"$setOnInsert": models.ShoppingListItems{
+				ItemID:    uuid.New(),
+				UserID:    userID,
+				StockID:   &product.StockID,
+				ProductID: &product.ProductID,
+				Name:      product.ProductName,
+				Unit:      product.Unit,
+				Quantity:  product.SuggestedReorderQty(),
+				Source:    models.ShoppingListSourceAuto,
+				CreatedAt: now,
+			}}).
+			SetUpsert(true))
+	}
+
+	// Two concurrent syncs may race on the same upsert; the loser's duplicate
+	// key error is harmless.
+	_, err = collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	return nil
+}
+
+func validateShoppingItemName(name string) error {
+	if name == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Name is required")
+	}
+	if utf8.RuneCountInString(name) > maxShoppingItemNameLength {
+		return fiber.NewError(fiber.StatusBadRequest, "Name must be at most 200 characters")
+	}
+	return nil
+}
+
+// errorMessage returns the client-facing message of a handler error.
+func errorMessage(err error) string {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Message
+	}
+	return err.Error()
+}
@@ -0,0 +1,118 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestConcurrentReceivesBookItemsOnce(t *testing.T) {
+	app := newApp(t)
+	owner := newUser(t, app)
+
+	var stock models.Warehouse
+	if status := doJSON(t, app, http.MethodPost, "/api/warehouse", owner.Token, fiber.Map{"StockName": "Pantry"}, &stock); status != http.StatusCreated {
+		t.Fatalf("create stock: status %d", status)
+	}
+	var product models.Products
+	body := fiber.Map{"StockID": stock.StockID.String(), "ProductName": "Rice", "ProductQty": 1}
+	if status := doJSON(t, app, http.MethodPost, "/api/products", owner.Token, body, &product); status != http.StatusCreated {
+		t.Fatalf("create product: status %d", status)
+	}
+
+	var item models.ShoppingListItems
+	if status := doJSON(t, app, http.MethodPost, "/api/shopping-list/items", owner.Token, fiber.Map{"ProductID": product.ProductID.String(), "Quantity": 3}, &item); status != http.StatusCreated {
+		t.Fatalf("add item: status %d", status)
+	}
+	if status := doJSON(t, app, http.MethodPatch, "/api/shopping-list/items/"+item.ItemID.String(), owner.Token, fiber.Map{"Checked": true}, nil); status != http.StatusOK {
+		t.Fatalf("check item: status %d", status)
+	}
+
+	const receives = 4
+	var wg sync.WaitGroup
+	statuses := make(chan int, receives)
+	for i := 0; i < receives; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, "/api/shopping-list/receive", nil)
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+owner.Token)
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+	for status := range statuses {
+		if status != http.StatusOK {
+			t.Errorf("receive: status %d, want 200", status)
+		}
+	}
+
+	var movements []models.StockMovements
+	path := "/api/products/" + product.ProductID.String() + "/movements"
+	if status := doJSON(t, app, http.MethodGet, path, owner.Token, nil, &movements); status != http.StatusOK {
+		t.Fatalf("list movements: status %d", status)
+	}
+	in := 0
+	for _, m := range movements {
+		if m.Type == models.MovementTypeIn && m.Reason == "shopping list" {
+			in++
+		}
+	}
+	if in != 1 {
+		t.Errorf("%d shopping list IN movements after %d concurrent receives, want 1", in, receives)
+	}
+}
+
+func TestReadOnlyKeyDoesNotSyncShoppingList(t *testing.T) {
+	app := newApp(t)
+	owner := newUser(t, app)
+
+	var stock models.Warehouse
+	if status := doJSON(t, app, http.MethodPost, "/api/warehouse", owner.Token, fiber.Map{"StockName": "Pantry"}, &stock); status != http.StatusCreated {
+		t.Fatalf("create stock: status %d", status)
+	}
+	body := fiber.Map{"StockID": stock.StockID.String(), "ProductName": "Rice", "ProductQty": 1, "MinQty": 2}
+	if status := doJSON(t, app, http.MethodPost, "/api/products", owner.Token, body, nil); status != http.StatusCreated {
+		t.Fatalf("create product: status %d", status)
+	}
+
+	var created struct {
+		Key string `json:"Key"`
+	}
+	keyBody := fiber.Map{"Name": "display", "Scopes": []string{models.ScopeProductsRead}}
+	if status := doJSON(t, app, http.MethodPost, "/api/api-keys", owner.Token, keyBody, &created); status != http.StatusCreated {
+		t.Fatalf("create API key: status %d", status)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/shopping-list", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "ApiKey "+created.Key)
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items []models.ShoppingListItems
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(items) != 0 {
+		t.Errorf("read-only key: status %d with %d items, want 200 and an unsynced, empty list", resp.StatusCode, len(items))
+	}
+
+	if status := doJSON(t, app, http.MethodGet, "/api/shopping-list", owner.Token, nil, &items); status != http.StatusOK || len(items) != 1 {
+		t.Errorf("session: status %d with %d items, want the low-stock product added", status, len(items))
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"my-backend/internal/db"
+	"my-backend/internal/inventory"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// accessibleStocksFilter matches every stock the user can at least view.
+func accessibleStocksFilter(ctx context.Context, userID uuid.UUID) (bson.M, error) {
+	collection, err := db.HouseholdMembersCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	householdIDs, err := collection.Distinct(ctx, "HouseholdID", bson.M{"UserID": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	sharesCol, err := db.StockSharesCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sharedStockIDs, err := sharesCol.Distinct(ctx, "StockID", bson.M{"UserID": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	or := bson.A{bson.M{"UserID": userID}}
+	if len(householdIDs) > 0 {
+		or = append(or, bson.M{"HouseholdID": bson.M{"$in": householdIDs}})
+	}
+	if len(sharedStockIDs) > 0 {
+		or = append(or, bson.M{"StockID": bson.M{"$in": sharedStockIDs}})
+	}
+	return bson.M{"$or": or}, nil
+}
+
+// authorizeStock loads a stock and ensures the authenticated user holds at
+// least the required access level on it. It returns 404 when the stock does
+// not exist and 403 when the user may not perform the operation.
+func authorizeStock(ctx context.Context, c *fiber.Ctx, stockID uuid.UUID, required inventory.AccessLevel) (models.Warehouse, error) {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return models.Warehouse{}, err
+	}
+
+	collection, err := db.WarehouseCollection(ctx)
+	if err != nil {
+		return models.Warehouse{}, fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var stock models.Warehouse
+	if err := collection.FindOne(ctx, bson.M{"StockID": stockID}).Decode(&stock); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Warehouse{}, fiber.NewError(fiber.StatusNotFound, "stock not found")
+		}
+		return models.Warehouse{}, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch stock")
+	}
+
+	level, err := inventory.AccessFor(ctx, userUUID, stock)
+	if err != nil {
+		return models.Warehouse{}, fiber.NewError(fiber.StatusInternalServerError, "failed to resolve stock access")
+	}
+	if level < required {
+		return models.Warehouse{}, fiber.NewError(fiber.StatusForbidden, "you do not have access to this stock")
+	}
+
+	return stock, nil
+}
+
+// authorizeProduct loads a product and checks access on the stock it belongs to.
+func authorizeProduct(ctx context.Context, c *fiber.Ctx, productID uuid.UUID, required inventory.AccessLevel) (models.Products, error) {
+	collection, err := db.ProductsCollection(ctx)
+	if err != nil {
+		return models.Products{}, fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var product models.Products
+	if err := collection.FindOne(ctx, bson.M{"ProductID": productID}).Decode(&product); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Products{}, fiber.NewError(fiber.StatusNotFound, "product not found")
+		}
+		return models.Products{}, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch product")
+	}
+
+	if _, err := authorizeStock(ctx, c, product.StockID, required); err != nil {
+		return models.Products{}, err
+	}
+
+	return product, nil
+}
+
+// authorizeCategory loads a category and checks access on the stock it belongs to.
+func authorizeCategory(ctx context.Context, c *fiber.Ctx, categoryID uuid.UUID, required inventory.AccessLevel) (models.Categories, error) {
+	collection, err := db.CategoriesCollection(ctx)
+	if err != nil {
+		return models.Categories{}, fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var category models.Categories
+	if err := collection.FindOne(ctx, bson.M{"CategoryID": categoryID}).Decode(&category); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.Categories{}, fiber.NewError(fiber.StatusNotFound, "category not found")
+		}
+		return models.Categories{}, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch category")
+	}
+
+	if _, err := authorizeStock(ctx, c, category.StockID, required); err != nil {
+		return models.Categories{}, err
+	}
+
+	return category, nil
+}
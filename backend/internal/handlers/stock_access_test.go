@@ -0,0 +1,394 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// stockFixture is a stock owned by one user and shared with an editor and a
+// viewer, holding one product and one category.
+type stockFixture struct {
+	StockID    string
+	ProductID  string
+	CategoryID string
+}
+
+func newStockFixture(t *testing.T, app *fiber.App, users roleUsers) stockFixture {
+	t.Helper()
+	owner := users.owner.Token
+
+	var stock models.Warehouse
+	if status := doJSON(t, app, http.MethodPost, "/api/warehouse", owner, fiber.Map{"StockName": "Pantry"}, &stock); status != http.StatusCreated {
+		t.Fatalf("create stock: status %d", status)
+	}
+	f := stockFixture{StockID: stock.StockID.String()}
+
+	for role, user := range map[string]testUser{"editor": users.editor, "viewer": users.viewer} {
+		path := "/api/warehouse/" + f.StockID + "/shares"
+		if status := doJSON(t, app, http.MethodPost, path, owner, fiber.Map{"Email": user.Email, "Role": role}, nil); status != http.StatusOK {
+			t.Fatalf("share stock with %s: status %d", role, status)
+		}
+	}
+
+	var product models.Products
+	body := fiber.Map{"StockID": f.StockID, "ProductName": "Rice", "Unit": "kg", "ProductQty": 2}
+	if status := doJSON(t, app, http.MethodPost, "/api/products", owner, body, &product); status != http.StatusCreated {
+		t.Fatalf("create product: status %d", status)
+	}
+	f.ProductID = product.ProductID.String()
+
+	var categories []models.Categories
+	categoryBody := []fiber.Map{{"StockID": f.StockID, "CategoryName": "Dry goods"}}
+	if status := doJSON(t, app, http.MethodPost, "/api/categories", owner, categoryBody, &categories); status != http.StatusCreated || len(categories) != 1 {
+		t.Fatalf("create category: status %d", status)
+	}
+	f.CategoryID = categories[0].CategoryID.String()
+
+	return f
+}
+
+func TestStockRoutesEnforceAccess(t *testing.T) {
+	app := newApp(t)
+	users := newRoleUsers(t, app)
+
+	stockQuery := func(f stockFixture) string { return url.Values{"stockId": {f.StockID}}.Encode() }
+
+	tests := []struct {
+		name   string
+		method string
+		path   func(f stockFixture) string
+		body   func(f stockFixture) interface{}
+		want   map[string]int
+	}{
+		{
+			name:   "list products",
+			method: http.MethodGet,
+			path:   func(f stockFixture) string { return "/api/products?" + stockQuery(f) },
+			want:   map[string]int{"owner": 200, "editor": 200, "viewer": 200, "stranger": 403},
+		},
+		{
+			name:   "create product",
+			method: http.MethodPost,
+			path:   func(stockFixture) string { return "/api/products" },
+			body: func(f stockFixture) interface{} {
+				return fiber.Map{"StockID": f.StockID, "ProductName": "Beans", "ProductQty": 1}
+			},
+			want: map[string]int{"owner": 201, "editor": 201, "viewer": 403, "stranger": 403},
+		},
+		{
+			name:   "update product",
+			method: http.MethodPut,
+			path:   func(f stockFixture) string { return "/api/products/" + f.ProductID },
+			body:   func(stockFixture) interface{} { return fiber.Map{"ProductQty": 5} },
+			want:   map[string]int{"owner": 200, "editor": 200, "viewer": 403, "stranger": 403},
+		},
+		{
+			name:   "delete product",
+			method: http.MethodDelete,
+			path:   func(f stockFixture) string { return "/api/products/" + f.ProductID },
+			want:   map[string]int{"owner": 200, "editor": 200, "viewer": 403, "stranger": 403},
+		},
+		{
+			name:   "list movements",
+			method: http.MethodGet,
+			path:   func(f stockFixture) string { return "/api/products/" + f.ProductID + "/movements" },
+			want:   map[string]int{"owner": 200, "editor": 200, "viewer": 200, "stranger": 403},
+		},
+		{
+			name:   "list categories",
+			method: http.MethodGet,
+			path:   func(f stockFixture) string { return "/api/categories?" + stockQuery(f) },
+			want:   map[string]int{"owner": 200, "editor": 200, "viewer": 200, "stranger": 403},
+		},
+		{
+			name:   "create categories",
+			method: http.MethodPost,
+			path:   func(stockFixture) string { return "/api/categories" },
+			body: func(f stockFixture) interface{} {
+				return []fiber.Map{{"StockID": f.StockID, "CategoryName": "Canned"}}
+			},
+			want: map[string]int{"owner": 201, "editor": 201, "viewer": 403, "stranger": 403},
+		},
+		{
+			name:   "delete category",
+			method: http.MethodDelete,
+			path:   func(f stockFixture) string { return "/api/categories/" + f.CategoryID },
+			want:   map[string]int{"owner": 200, "editor": 200, "viewer": 403, "stranger": 403},
+		},
+		{
+			name:   "list low stock",
+			method: http.MethodGet,
+			path:   func(f stockFixture) string { return "/api/warehouse/" + f.StockID + "/low-stock" },
+			want:   map[string]int{"owner": 200, "editor": 200, "viewer": 200, "stranger": 403},
+		},
+		{
+			name:   "delete stock",
+			method: http.MethodDelete,
+			path:   func(f stockFixture) string { return "/api/warehouse/" + f.StockID },
+			want:   map[string]int{"owner": 200, "editor": 403, "viewer": 403, "stranger": 403},
+		},
+		{
+			name:   "look up a barcode in the stock",
+			method: http.MethodGet,
+			path: func(f stockFixture) string {
+				return "/api/products/lookup?" + url.Values{"barcode": {"4006381333931"}, "stockId": {f.StockID}}.Encode()
+			},
+			want: map[string]int{"owner": 200, "editor": 200, "viewer": 200, "stranger": 403},
+		},
+		{
+			name:   "record a movement",
+			method: http.MethodPost,
+			path:   func(f stockFixture) string { return "/api/products/" + f.ProductID + "/movements" },
+			body: func(stockFixture) interface{} {
+				return fiber.Map{"Type": models.MovementTypeIn, "Quantity": 1}
+			},
+			want: map[string]int{"owner": 201, "editor": 201, "viewer": 403, "stranger": 403},
+		},
+		{
+			name:   "list expiring lots",
+			method: http.MethodGet,
+			path:   func(f stockFixture) string { return "/api/warehouse/" + f.StockID + "/expiring" },
+			want:   map[string]int{"owner": 200, "editor": 200, "viewer": 200, "stranger": 403},
+		},
+		{
+			name:   "waste expired lots",
+			method: http.MethodPost,
+			path:   func(f stockFixture) string { return "/api/warehouse/" + f.StockID + "/waste-expired" },
+			want:   map[string]int{"owner": 200, "editor": 200, "viewer": 403, "stranger": 403},
+		},
+		{
+			name:   "waste report",
+			method: http.MethodGet,
+			path:   func(f stockFixture) string { return "/api/warehouse/" + f.StockID + "/waste-report" },
+			want:   map[string]int{"owner": 200, "editor": 200, "viewer": 200, "stranger": 403},
+		},
+		{
+			name:   "move the stock out of its household",
+			method: http.MethodPut,
+			path:   func(f stockFixture) string { return "/api/warehouse/" + f.StockID + "/household" },
+			body:   func(stockFixture) interface{} { return fiber.Map{"HouseholdID": nil} },
+			want:   map[string]int{"owner": 200, "editor": 403, "viewer": 403, "stranger": 403},
+		},
+		{
+			name:   "get notification settings",
+			method: http.MethodGet,
+			path:   func(f stockFixture) string { return "/api/warehouse/" + f.StockID + "/notifications" },
+			want:   map[string]int{"owner": 200, "editor": 200, "viewer": 200, "stranger": 403},
+		},
+		{
+			name:   "update notification settings",
+			method: http.MethodPut,
+			path:   func(f stockFixture) string { return "/api/warehouse/" + f.StockID + "/notifications" },
+			body:   func(stockFixture) interface{} { return fiber.Map{"Channels": []fiber.Map{}} },
+			want:   map[string]int{"owner": 200, "editor": 200, "viewer": 200, "stranger": 403},
+		},
+		{
+			name:   "list shares",
+			method: http.MethodGet,
+			path:   func(f stockFixture) string { return "/api/warehouse/" + f.StockID + "/shares" },
+			want:   map[string]int{"owner": 200, "editor": 403, "viewer": 403, "stranger": 403},
+		},
+		{
+			name:   "share the stock",
+			method: http.MethodPost,
+			path:   func(f stockFixture) string { return "/api/warehouse/" + f.StockID + "/shares" },
+			body: func(stockFixture) interface{} {
+				return fiber.Map{"Email": users.stranger.Email, "Role": "viewer"}
+			},
+			want: map[string]int{"owner": 200, "editor": 403, "viewer": 403, "stranger": 403},
+		},
+		{
+			name:   "revoke the viewer's share",
+			method: http.MethodDelete,
+			path: func(f stockFixture) string {
+				return "/api/warehouse/" + f.StockID + "/shares/" + users.viewer.ID.String()
+			},
+			// The viewer may leave a stock shared with them.
+			want: map[string]int{"owner": 200, "editor": 403, "viewer": 200, "stranger": 403},
+		},
+		{
+			name:   "transfer the stock",
+			method: http.MethodPost,
+			path:   func(f stockFixture) string { return "/api/warehouse/" + f.StockID + "/transfer" },
+			body:   func(stockFixture) interface{} { return fiber.Map{"Email": users.editor.Email} },
+			want:   map[string]int{"owner": 200, "editor": 403, "viewer": 403, "stranger": 403},
+		},
+		{
+			name:   "list the stock's audit log",
+			method: http.MethodGet,
+			path:   func(f stockFixture) string { return "/api/audit?" + stockQuery(f) },
+			want:   map[string]int{"owner": 200, "editor": 200, "viewer": 200, "stranger": 403},
+		},
+		{
+			name:   "put a product on the shopping list",
+			method: http.MethodPost,
+			path:   func(stockFixture) string { return "/api/shopping-list/items" },
+			body:   func(f stockFixture) interface{} { return fiber.Map{"ProductID": f.ProductID, "Quantity": 1} },
+			want:   map[string]int{"owner": 201, "editor": 201, "viewer": 201, "stranger": 403},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, role := range []string{"owner", "editor", "viewer", "stranger"} {
+			t.Run(tt.name+"/"+role, func(t *testing.T) {
+				f := newStockFixture(t, app, users)
+				var body interface{}
+				if tt.body != nil {
+					body = tt.body(f)
+				}
+				resp, raw := do(t, app, tt.method, tt.path(f), users.byRole(role).Token, body)
+				if resp.StatusCode != tt.want[role] {
+					t.Errorf("%s %s: status %d, want %d: %s", tt.method, tt.path(f), resp.StatusCode, tt.want[role], raw)
+				}
+			})
+		}
+	}
+}
+
+func TestStockRoutesUnknownIDs(t *testing.T) {
+	app := newApp(t)
+	owner := newUser(t, app)
+	unknown := uuid.NewString()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   interface{}
+	}{
+		{"list products", http.MethodGet, "/api/products?stockId=" + unknown, nil},
+		{"create product", http.MethodPost, "/api/products", fiber.Map{"StockID": unknown, "ProductName": "Beans", "ProductQty": 1}},
+		{"update product", http.MethodPut, "/api/products/" + unknown, fiber.Map{"ProductQty": 5}},
+		{"delete product", http.MethodDelete, "/api/products/" + unknown, nil},
+		{"list categories", http.MethodGet, "/api/categories?stockId=" + unknown, nil},
+		{"create categories", http.MethodPost, "/api/categories", []fiber.Map{{"StockID": unknown, "CategoryName": "Canned"}}},
+		{"delete category", http.MethodDelete, "/api/categories/" + unknown, nil},
+		{"list low stock", http.MethodGet, "/api/warehouse/" + unknown + "/low-stock", nil},
+		{"delete stock", http.MethodDelete, "/api/warehouse/" + unknown, nil},
+		{"look up a barcode in the stock", http.MethodGet, "/api/products/lookup?barcode=4006381333931&stockId=" + unknown, nil},
+		{"list movements", http.MethodGet, "/api/products/" + unknown + "/movements", nil},
+		{"record a movement", http.MethodPost, "/api/products/" + unknown + "/movements", fiber.Map{"Type": models.MovementTypeIn, "Quantity": 1}},
+		{"list expiring lots", http.MethodGet, "/api/warehouse/" + unknown + "/expiring", nil},
+		{"waste expired lots", http.MethodPost, "/api/warehouse/" + unknown + "/waste-expired", nil},
+		{"waste report", http.MethodGet, "/api/warehouse/" + unknown + "/waste-report", nil},
+		{"move the stock out of its household", http.MethodPut, "/api/warehouse/" + unknown + "/household", fiber.Map{"HouseholdID": nil}},
+		{"get notification settings", http.MethodGet, "/api/warehouse/" + unknown + "/notifications", nil},
+		{"update notification settings", http.MethodPut, "/api/warehouse/" + unknown + "/notifications", fiber.Map{"Channels": []fiber.Map{}}},
+		{"list shares", http.MethodGet, "/api/warehouse/" + unknown + "/shares", nil},
+		{"share the stock", http.MethodPost, "/api/warehouse/" + unknown + "/shares", fiber.Map{"Email": "someone@example.com", "Role": "viewer"}},
+		{"transfer the stock", http.MethodPost, "/api/warehouse/" + unknown + "/transfer", fiber.Map{"Email": "someone@example.com"}},
+		{"list the stock's audit log", http.MethodGet, "/api/audit?stockId=" + unknown, nil},
+		{"put a product on the shopping list", http.MethodPost, "/api/shopping-list/items", fiber.Map{"ProductID": unknown, "Quantity": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, raw := do(t, app, tt.method, tt.path, owner.Token, tt.body)
+			if resp.StatusCode != http.StatusNotFound {
+				t.Errorf("%s %s: status %d, want 404: %s", tt.method, tt.path, resp.StatusCode, raw)
+			}
+		})
+	}
+}
+
+func TestStockRoutesRequireAuth(t *testing.T) {
+	app := newApp(t)
+
+	for _, path := range []string{"/api/warehouse", "/api/products?stockId=" + uuid.NewString(), "/api/categories?stockId=" + uuid.NewString()} {
+		if resp, _ := do(t, app, http.MethodGet, path, "", nil); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("GET %s without a token: status %d, want 401", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestListWarehouseOnlyShowsAccessibleStocks(t *testing.T) {
+	app := newApp(t)
+	users := newRoleUsers(t, app)
+	f := newStockFixture(t, app, users)
+
+	for _, role := range []string{"owner", "editor", "viewer", "stranger"} {
+		var stocks []models.Warehouse
+		if status := doJSON(t, app, http.MethodGet, "/api/warehouse", users.byRole(role).Token, nil, &stocks); status != http.StatusOK {
+			t.Fatalf("%s: status %d", role, status)
+		}
+		listed := false
+		for _, stock := range stocks {
+			listed = listed || stock.StockID.String() == f.StockID
+		}
+		if want := role != "stranger"; listed != want {
+			t.Errorf("%s: stock listed = %v, want %v", role, listed, want)
+		}
+	}
+}
+
+func TestReceiveShoppingListNeedsEditAccess(t *testing.T) {
+	app := newApp(t)
+	users := newRoleUsers(t, app)
+
+	for _, role := range []string{"editor", "viewer"} {
+		t.Run(role, func(t *testing.T) {
+			f := newStockFixture(t, app, users)
+			user := users.byRole(role)
+
+			var item models.ShoppingListItems
+			body := fiber.Map{"ProductID": f.ProductID, "Quantity": 3}
+			if status := doJSON(t, app, http.MethodPost, "/api/shopping-list/items", user.Token, body, &item); status != http.StatusCreated {
+				t.Fatalf("add item: status %d", status)
+			}
+			path := "/api/shopping-list/items/" + item.ItemID.String()
+			if status := doJSON(t, app, http.MethodPatch, path, user.Token, fiber.Map{"Checked": true}, nil); status != http.StatusOK {
+				t.Fatalf("check item: status %d", status)
+			}
+
+			var result struct {
+				Received []models.StockMovements `json:"Received"`
+				Skipped  []struct {
+					ItemID string `json:"ItemID"`
+				} `json:"Skipped"`
+			}
+			if status := doJSON(t, app, http.MethodPost, "/api/shopping-list/receive", user.Token, nil, &result); status != http.StatusOK {
+				t.Fatalf("receive: status %d", status)
+			}
+			received, skipped := len(result.Received), len(result.Skipped)
+			if role == "editor" && (received != 1 || skipped != 0) {
+				t.Errorf("editor: %d received, %d skipped, want the item received", received, skipped)
+			}
+			if role == "viewer" && (received != 0 || skipped != 1) {
+				t.Errorf("viewer: %d received, %d skipped, want the item skipped", received, skipped)
+			}
+		})
+	}
+}
+
+func TestShareTargetsAreIndistinguishable(t *testing.T) {
+	app := newApp(t)
+	owner := newUser(t, app)
+	pending := insertUser(t, models.Users{Status: models.UserStatusPendingVerification})
+
+	var stock models.Warehouse
+	if status := doJSON(t, app, http.MethodPost, "/api/warehouse", owner.Token, fiber.Map{"StockName": "Pantry"}, &stock); status != http.StatusCreated {
+		t.Fatalf("create stock: status %d", status)
+	}
+
+	for _, email := range []string{"nobody-" + uuid.NewString()[:8] + "@example.com", pending.Email} {
+		requests := []struct {
+			path string
+			body fiber.Map
+		}{
+			{"/api/warehouse/" + stock.StockID.String() + "/shares", fiber.Map{"Email": email, "Role": "viewer"}},
+			{"/api/warehouse/" + stock.StockID.String() + "/transfer", fiber.Map{"Email": email}},
+		}
+		for _, r := range requests {
+			resp, raw := do(t, app, http.MethodPost, r.path, owner.Token, r.body)
+			if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(raw), "cannot share with that user") {
+				t.Errorf("POST %s for %s: status %d: %s, want the generic 400", r.path, email, resp.StatusCode, raw)
+			}
+		}
+	}
+}
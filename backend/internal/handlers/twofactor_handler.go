@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"my-backend/internal/auth"
+	"my-backend/internal/db"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
+	"my-backend/internal/totp"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	recoveryCodeCount = 10
+	// totpSkew accepts codes from one period before or after the current one.
+	totpSkew = 1
+)
+
+type twoFactorChallengeResponse struct {
+	TwoFactorRequired bool      `json:"TwoFactorRequired"`
+	ChallengeToken    string    `json:"ChallengeToken"`
+	ExpiresAt         time.Time `json:"ExpiresAt"`
+}
+
+type twoFactorEnrollResponse struct {
+	Secret     string `json:"Secret"`
+	OTPAuthURI string `json:"OTPAuthURI"`
+}
+
+type twoFactorConfirmRequest struct {
+	Code string `json:"Code"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"RecoveryCodes"`
+}
+
+type twoFactorDisableRequest struct {
+	Password     string `json:"Password"`
+	Code         string `json:"Code"`
+	RecoveryCode string `json:"RecoveryCode"`
+}
+
+type twoFactorLoginRequest struct {
+	ChallengeToken string `json:"ChallengeToken"`
+	Code           string `json:"Code"`
+	RecoveryCode   string `json:"RecoveryCode"`
+}
+
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Personal Warehouse"
+}
+
+// generateRecoveryCodes returns plain codes formatted as XXXXX-XXXXX and
+// the hashes to store in their place.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := newReadableCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashReadableCode(code)
+	}
+	return codes, hashes, nil
+}
+
+// newReadableCode returns a random XXXXX-XXXXX code without look-alike
+// characters, meant to be typed in by people.
+func newReadableCode() (string, error) {
+	const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for j, v := range buf {
+		if j == 5 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(alphabet[int(v)%len(alphabet)])
+	}
+	return b.String(), nil
+}
+
+// hashReadableCode hashes a code from newReadableCode, ignoring case,
+// spaces and dashes so it matches however the user typed it.
+func hashReadableCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	return auth.HashOpaqueToken(normalized)
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code and
+// consumes it so it cannot be replayed.
+func verifySecondFactor(ctx context.Context, user models.Users, code, recoveryCode string) (bool, error) {
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if recoveryCode = strings.TrimSpace(recoveryCode); recoveryCode != "" {
+		hash := hashReadableCode(recoveryCode)
+		res, err := collection.UpdateOne(ctx,
+			bson.M{"UserId": user.UserID, "RecoveryCodeHashes": hash},
+			bson.M{"$pull": bson.M{"RecoveryCodeHashes": hash}},
+		)
+		if err != nil {
+			return false, err
+		}
+		return res.ModifiedCount == 1, nil
+	}
+
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now(), totpSkew, user.TOTPLastStep)
+	if !ok {
+		return false, nil
+	}
+
+	// Recording the step only if it is newer than the last accepted one makes
+	// each code single-use, even under concurrent requests.
+	res, err := collection.UpdateOne(ctx,
+		bson.M{"UserId": user.UserID, "$or": bson.A{
+			bson.M{"TOTPLastStep": bson.M{"$lt": step}},
+			bson.M{"TOTPLastStep": bson.M{"$exists": false}},
+		}},
+		bson.M{"$set": bson.M{"TOTPLastStep": step}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+// EnrollTwoFactor godoc
+// @Summary      Start TOTP enrollment
+// @Description  Generates a new TOTP secret and otpauth URI. Two-factor login is only enabled once the secret is confirmed with a code.
+// @Tags         two-factor
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  twoFactorEnrollResponse
+// @Failure      401  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/2fa/enroll [post]
+func EnrollTwoFactor(c *fiber.Ctx) error {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+	}
+	if user.TOTPEnabled {
+		return fiber.NewError(fiber.StatusConflict, "two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to generate secret")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	if _, err := collection.UpdateOne(ctx,
+		bson.M{"UserId": user.UserID},
+		bson.M{"$set": bson.M{"TOTPPendingSecret": secret}},
+	); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to store secret")
+	}
+	recordAudit(ctx, c, models.AuditActionCreate, models.AuditEntityTwoFactor, user.UserID, uuid.Nil, nil, fiber.Map{"Enabled": false})
+
+	return c.JSON(twoFactorEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(totpIssuer(), user.Email, secret),
+	})
+}
+
+// ConfirmTwoFactor godoc
+// @Summary      Confirm TOTP enrollment
+// @Description  Enables two-factor login after checking a first code from the authenticator app. Returns one-time recovery codes, which are only shown once.
+// @Tags         two-factor
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        payload  body      twoFactorConfirmRequest  true  "Code from the authenticator app"
+// @Success      200  {object}  recoveryCodesResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/2fa/confirm [post]
+func ConfirmTwoFactor(c *fiber.Ctx) error {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+	}
+	if user.TOTPEnabled {
+		return fiber.NewError(fiber.StatusConflict, "two-factor authentication is already enabled")
+	}
+	if user.TOTPPendingSecret == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "start enrollment first")
+	}
+
+	var req twoFactorConfirmRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	step, valid := totp.Validate(user.TOTPPendingSecret, req.Code, time.Now(), totpSkew, 0)
+	if !valid {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid code")
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to generate recovery codes")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	res, err := collection.UpdateOne(ctx,
+		bson.M{"UserId": user.UserID, "TOTPPendingSecret": user.TOTPPendingSecret},
+		bson.M{
+			"$set": bson.M{
+				"TOTPEnabled":        true,
+				"TOTPSecret":         user.TOTPPendingSecret,
+				"TOTPLastStep":       step,
+				"RecoveryCodeHashes": hashes,
+			},
+			"$unset": bson.M{"TOTPPendingSecret": ""},
+		},
+	)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to enable two-factor authentication")
+	}
+	if res.MatchedCount == 0 {
+		return fiber.NewError(fiber.StatusConflict, "enrollment changed concurrently; start again")
+	}
+	recordAudit(ctx, c, models.AuditActionUpdate, models.AuditEntityTwoFactor, user.UserID, uuid.Nil, fiber.Map{"Enabled": false}, fiber.Map{"Enabled": true})
+
+	return c.JSON(recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTwoFactor godoc
+// @Summary      Disable TOTP
+// @Description  Turns two-factor login off. Requires the account password (or, for accounts without one, a sign-in within the last 10 minutes) and a current code or recovery code.
+// @Tags         two-factor
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        payload  body      twoFactorDisableRequest  true  "Password and second factor"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/2fa/disable [post]
+func DisableTwoFactor(c *fiber.Ctx) error {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+	}
+	if !user.TOTPEnabled {
+		return fiber.NewError(fiber.StatusBadRequest, "two-factor authentication is not enabled")
+	}
+
+	var req twoFactorDisableRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := confirmIdentity(ctx, c, user, req.Password); err != nil {
+		return err
+	}
+
+	valid, err := verifySecondFactor(ctx, user, req.Code, req.RecoveryCode)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to verify code")
+	}
+	if !valid {
+		return fiber.NewError(fiber.StatusUnauthorized, "invalid code")
+	}
+
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	if _, err := collection.UpdateOne(ctx,
+		bson.M{"UserId": user.UserID},
+		bson.M{"$unset": bson.M{
+			"TOTPEnabled":        "",
+			"TOTPSecret":         "",
+			"TOTPPendingSecret":  "",
+			"TOTPLastStep":       "",
+			"RecoveryCodeHashes": "",
+		}},
+	); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to disable two-factor authentication")
+	}
+	recordAudit(ctx, c, models.AuditActionDelete, models.AuditEntityTwoFactor, user.UserID, uuid.Nil, fiber.Map{"Enabled": true}, nil)
+
+	return c.JSON(fiber.Map{"message": "two-factor authentication disabled"})
+}
+
+// LoginTwoFactor godoc
+// @Summary      Complete two-factor login
+// @Description  Exchanges the challenge token returned by LoginUser plus a TOTP code or recovery code for a session.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      twoFactorLoginRequest  true  "Challenge token and second factor"
+// @Success      200  {object}  loginResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      429  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/login/2fa [post]
+func LoginTwoFactor(c *fiber.Ctx) error {
+	var req twoFactorLoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	req.ChallengeToken = strings.TrimSpace(req.ChallengeToken)
+	if req.ChallengeToken == "" || (strings.TrimSpace(req.Code) == "" && strings.TrimSpace(req.RecoveryCode) == "") {
+		return fiber.NewError(fiber.StatusBadRequest, "ChallengeToken and Code or RecoveryCode are required")
+	}
+
+	claims, err := auth.ParseChallengeToken(req.ChallengeToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrExpiredToken) {
+			return fiber.NewError(fiber.StatusUnauthorized, "challenge token is invalid or has expired")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "authentication unavailable")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var user models.Users
+	if err := collection.FindOne(ctx, bson.M{"UserId": claims.Subject}).Decode(&user); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusUnauthorized, "challenge token is invalid or has expired")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch user")
+	}
+	if err := middleware.AccountStatusError(user); err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return fiber.NewError(fiber.StatusBadRequest, "two-factor authentication is not enabled")
+	}
+
+	throttleKeys := loginThrottleKeys(c, user.Email)
+	if err := checkLoginLockout(ctx, c, throttleKeys); err != nil {
+		return err
+	}
+
+	valid, err := verifySecondFactor(ctx, user, req.Code, req.RecoveryCode)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to verify code")
+	}
+	if !valid {
+		if err := recordLoginFailure(ctx, throttleKeys); err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to record login attempt")
+		}
+		return fiber.NewError(fiber.StatusUnauthorized, "invalid code")
+	}
+
+	if err := clearLoginFailures(ctx, throttleKeys); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to reset login attempts")
+	}
+
+	resp, err := startSession(ctx, c, user)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(resp)
+}
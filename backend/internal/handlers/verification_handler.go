@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"my-backend/internal/auth"
+	"my-backend/internal/db"
+	mailer "my-backend/internal/mail"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type resendVerificationRequest struct {
+	Email string `json:"Email"`
+}
+
+// normalizeEmail trims and lowercases the address and rejects anything that
+// is not a bare RFC 5322 address such as "name@example.com".
+func normalizeEmail(raw string) (string, error) {
+	email := canonicalEmail(raw)
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || !strings.Contains(email[strings.LastIndex(email, "@")+1:], ".") {
+		return "", fiber.NewError(fiber.StatusBadRequest, "email is not a valid address")
+	}
+	return email, nil
+}
+
+// canonicalEmail is the form addresses are stored and looked up in, so that
+// differently cased spellings find the same account.
+func canonicalEmail(raw string) string {
+	return strings.ToLower(strings.TrimSpace(raw))
+}
+
+// sendEmailVerification replaces any outstanding verification token of the
+// user with a new one for email and mails the verification link.
+func sendEmailVerification(ctx context.Context, userID uuid.UUID, email string) error {
+	collection, err := db.EmailVerificationsCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	token, tokenHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if _, err := collection.DeleteMany(ctx, bson.M{"UserID": userID, "UsedAt": nil}); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	verification := models.EmailVerifications{
+		TokenHash: tokenHash,
+		UserID:    userID,
+		Email:     email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(auth.EmailVerificationTTL()),
+	}
+	if _, err := collection.InsertOne(ctx, verification); err != nil {
+		return err
+	}
+
+	msg := mailer.Message{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Confirm this email address for your warehouse account by opening this link (valid for %s):\n%s\n\n"+
+			"If you did not request this, you can ignore this email.",
+			auth.EmailVerificationTTL(), mailer.APIURL("/api/verify-email?token="+url.QueryEscape(token))),
+	}
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := mailer.DefaultSender().Send(sendCtx, msg); err != nil {
+			log.Printf("failed to send verification email: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// VerifyEmail godoc
+// @Summary      Verify email address
+// @Description  Consumes a verification token, confirms the address on the account (applying a pending email change) and activates accounts pending verification.
+// @Tags         users
+// @Produce      json
+// @Param        token  query  string  true  "Verification token"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/verify-email [get]
+func VerifyEmail(c *fiber.Ctx) error {
+	token := strings.TrimSpace(c.Query("token"))
+	if token == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "token is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	usersCol, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	verificationsCol, err := db.EmailVerificationsCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	now := time.Now().UTC()
+	var verification models.EmailVerifications
+	err = verificationsCol.FindOneAndUpdate(ctx,
+		bson.M{
+			"TokenHash": auth.HashOpaqueToken(token),
+			"UsedAt":    nil,
+			"ExpiresAt": bson.M{"$gt": now},
+		},
+		bson.M{"$set": bson.M{"UsedAt": now}},
+	).Decode(&verification)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusBadRequest, "verification token is invalid or has expired")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to verify token")
+	}
+
+	var before models.Users
+	err = usersCol.FindOneAndUpdate(ctx,
+		bson.M{"UserId": verification.UserID.String()},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"Email":           verification.Email,
+			"EmailVerifiedAt": now,
+			"Status": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$Status", models.UserStatusPendingVerification}},
+				models.UserStatusActive,
+				"$Status",
+			}},
+		}}}},
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusBadRequest, "verification token is invalid or has expired")
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return fiber.NewError(fiber.StatusConflict, "email is already used by another account")
+		}
+		// The failure may be transient, so hand the token back rather than
+		// burning a link the user cannot retry.
+		if _, err := verificationsCol.UpdateOne(ctx,
+			bson.M{"TokenHash": verification.TokenHash, "UsedAt": now},
+			bson.M{"$unset": bson.M{"UsedAt": ""}},
+		); err != nil {
+			log.Printf("failed to release verification token: %v", err)
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to update user")
+	}
+
+	after := before
+	after.Email = verification.Email
+	after.EmailVerifiedAt = &now
+	if after.Status == models.UserStatusPendingVerification {
+		after.Status = models.UserStatusActive
+	}
+	recordAuditAs(ctx, c, verification.UserID, models.AuditActionUpdate, models.AuditEntityUser, before.UserID, uuid.Nil, before, after)
+
+	return c.JSON(fiber.Map{"message": "email verified"})
+}
+
+// ResendVerification godoc
+// @Summary      Resend verification email
+// @Description  Sends a new verification link to an account that is pending verification. Always responds 202 so the response does not reveal which emails are registered.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      resendVerificationRequest  true  "Account email"
+// @Success      202  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/verify-email/resend [post]
+func ResendVerification(c *fiber.Ctx) error {
+	var req resendVerificationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
+	}
+
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return err
+	}
+
+	accepted := fiber.Map{"message": "if the account is awaiting verification, a new link has been sent"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	usersCol, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var user models.Users
+	err = usersCol.FindOne(ctx, bson.M{"Email": email, "Status": models.UserStatusPendingVerification}).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.Status(fiber.StatusAccepted).JSON(accepted)
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch user")
+	}
+
+	userUUID, err := uuid.Parse(user.UserID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "stored user ID is not a valid UUID")
+	}
+	if err := sendEmailVerification(ctx, userUUID, user.Email); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to create verification token")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(accepted)
+}
@@ -6,26 +6,32 @@ import (
 	"time"
 
 	"my-backend/internal/db"
+	"my-backend/internal/inventory"
+	"my-backend/internal/middleware"
 	"my-backend/internal/models"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type createStockRequest struct {
-	UserID    string `json:"UserID"`
-	StockName string `json:"StockName"`
+	StockName   string `json:"StockName"`
+	HouseholdID string `json:"HouseholdID"`
 }
 
 // DeleteStock godoc
 // @Summary      Delete a stock
-// @Description  Deletes a stock by ID and removes related products with the same StockID.
+// @Description  Deletes a stock by ID and removes related products with the same StockID and the stock's shares. The movement ledger is kept. Only the stock owner can delete it.
 // @Tags         warehouse
 // @Produce      json
+// @Security     BearerAuth
 // @Param        stockId  path  string  true  "Stock ID (UUID)"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /api/warehouse/{stockId} [delete]
 func DeleteStock(c *fiber.Ctx) error {
@@ -42,6 +48,11 @@ func DeleteStock(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	stock, err := authorizeStock(ctx, c, stockUUID, inventory.AccessOwner)
+	if err != nil {
+		return err
+	}
+
 	warehouseCol, err := db.WarehouseCollection(ctx)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
@@ -51,6 +62,12 @@ func DeleteStock(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
 	}
 
+	// Snapshot the products first so the audit log keeps what was erased.
+	products, err := findAll[models.Products](ctx, db.ProductsCollection, bson.M{"StockID": stockUUID})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch related products")
+	}
+
 	stockRes, err := warehouseCol.DeleteOne(ctx, bson.M{"StockID": stockUUID})
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to delete stock")
@@ -61,6 +78,29 @@ func DeleteStock(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to delete related products")
 	}
 
+	sharesCol, err := db.StockSharesCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+	if _, err := sharesCol.DeleteMany(ctx, bson.M{"StockID": stockUUID}); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to delete stock shares")
+	}
+
+	for _, collection := range []collectionFunc{db.NotificationSettingsCollection, db.NotificationOutboxCollection, db.ShoppingListCollection} {
+		col, err := collection(ctx)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+		}
+		if _, err := col.DeleteMany(ctx, bson.M{"StockID": stockUUID}); err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to delete stock data")
+		}
+	}
+
+	for _, product := range products {
+		recordAudit(ctx, c, models.AuditActionDelete, models.AuditEntityProduct, product.ProductID.String(), stockUUID, product, nil)
+	}
+	recordAudit(ctx, c, models.AuditActionDelete, models.AuditEntityStock, stockUUID.String(), stockUUID, stock, nil)
+
 	return c.JSON(fiber.Map{
 		"deleted_stock":           stockRes.DeletedCount,
 		"deleted_relatedProducts": productRes.DeletedCount,
@@ -69,22 +109,18 @@ func DeleteStock(c *fiber.Ctx) error {
 
 // ListWarehouse godoc
 // @Summary      List warehouse
-// @Description  Returns warehouse filtered by UserID.
+// @Description  Returns every stock the authenticated user can access: their own, those of their households and those shared with them.
 // @Tags         warehouse
 // @Produce      json
-// @Param        userId  query  string  true  "User ID (UUID)"
+// @Security     BearerAuth
 // @Success      200  {array}   models.Warehouse
+// @Failure      401  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /api/warehouse [get]
 func ListWarehouse(c *fiber.Ctx) error {
-	userIDParam := strings.TrimSpace(c.Query("userId"))
-	if userIDParam == "" {
-		return fiber.NewError(fiber.StatusBadRequest, "userId is required")
-	}
-
-	userUUID, err := uuid.Parse(userIDParam)
+	userUUID, err := middleware.CurrentUserID(c)
 	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, "userId must be a valid UUID")
+		return err
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -95,7 +131,12 @@ func ListWarehouse(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
 	}
 
-	cursor, err := collection.Find(ctx, bson.M{"UserID": userUUID})
+	filter, err := accessibleStocksFilter(ctx, userUUID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to resolve stock access")
+	}
+
+	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch warehouse")
 	}
@@ -111,50 +152,138 @@ func ListWarehouse(c *fiber.Ctx) error {
 
 // CreateStock godoc
 // @Summary      Create a stock
-// @Description  Creates a new stock record.
+// @Description  Creates a new stock record owned by the authenticated user, optionally inside a household the user can edit.
 // @Tags         warehouse
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        payload  body      createStockRequest  true  "Stock data"
 // @Success      201  {object}  models.Warehouse
 // @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
 // @Router       /api/warehouse [post]
 func CreateStock(c *fiber.Ctx) error {
+	userUUID, err := middleware.CurrentUserID(c)
+	if err != nil {
+		return err
+	}
+
 	var req createStockRequest
 	if err := c.BodyParser(&req); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid JSON payload")
 	}
 
 	req.StockName = strings.TrimSpace(req.StockName)
-	req.UserID = strings.TrimSpace(req.UserID)
+	req.HouseholdID = strings.TrimSpace(req.HouseholdID)
 
-	if req.StockName == "" || req.UserID == "" {
-		return fiber.NewError(fiber.StatusBadRequest, "UserID and StockName are required")
-	}
-
-	userUUID, err := uuid.Parse(req.UserID)
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, "UserID must be a valid UUID")
+	if req.StockName == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "StockName is required")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	var householdID *uuid.UUID
+	if req.HouseholdID != "" {
+		householdUUID, err := uuid.Parse(req.HouseholdID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "HouseholdID must be a valid UUID")
+		}
+		if _, _, err := authorizeHousehold(ctx, c, householdUUID, models.HouseholdRoleEditor); err != nil {
+			return err
+		}
+		householdID = &householdUUID
+	}
+
 	collection, err := db.WarehouseCollection(ctx)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
 	}
 
 	stock := models.Warehouse{
-		StockID:   uuid.New(),
-		UserID:    userUUID,
-		StockName: req.StockName,
+		StockID:     uuid.New(),
+		UserID:      userUUID,
+		StockName:   req.StockName,
+		HouseholdID: householdID,
 	}
 
 	if _, err := collection.InsertOne(ctx, stock); err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to create stock")
 	}
+	recordAudit(ctx, c, models.AuditActionCreate, models.AuditEntityStock, stock.StockID.String(), stock.StockID, nil, stock)
 
 	return c.Status(fiber.StatusCreated).JSON(stock)
 }
+
+// lowStockItem is a product at or below its MinQty with the amount to reorder.
+type lowStockItem struct {
+	models.Products
+	SuggestedQty int `json:"SuggestedQty"`
+}
+
+// ListLowStock godoc
+// @Summary      List low-stock products
+// @Description  Returns the stock's products whose ProductQty is at or below their MinQty, with a suggested reorder quantity (ReorderQty, or enough to get back above MinQty). Products without a MinQty are never listed.
+// @Tags         warehouse
+// @Produce      json
+// @Security     BearerAuth
+// @Param        stockId  path  string  true  "Stock ID (UUID)"
+// @Success      200  {array}   lowStockItem
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/warehouse/{stockId}/low-stock [get]
+func ListLowStock(c *fiber.Ctx) error {
+	stockUUID, err := stockIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := authorizeStock(ctx, c, stockUUID, inventory.AccessViewer); err != nil {
+		return err
+	}
+
+	products, err := lowStockProducts(ctx, bson.M{"StockID": stockUUID})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch low-stock products")
+	}
+
+	items := make([]lowStockItem, 0, len(products))
+	for _, product := range products {
+		items = append(items, lowStockItem{Products: product, SuggestedQty: product.SuggestedReorderQty()})
+	}
+
+	return c.JSON(items)
+}
+
+// lowStockProducts returns the products matching filter that are at or below
+// their MinQty, sorted by name.
+func lowStockProducts(ctx context.Context, filter bson.M) ([]models.Products, error) {
+	collection, err := db.ProductsCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lowFilter := bson.M{
+		"MinQty": bson.M{"$ne": nil},
+		"$expr":  bson.M{"$lte": bson.A{"$ProductQty", "$MinQty"}},
+	}
+	for key, value := range filter {
+		lowFilter[key] = value
+	}
+
+	cursor, err := collection.Find(ctx, lowFilter, options.Find().SetSort(bson.D{{Key: "ProductName", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	products := []models.Products{}
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
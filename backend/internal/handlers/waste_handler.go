@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/inventory"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// wasteReport is how much of a stock was thrown away in one month.
+type wasteReport struct {
+	StockID    uuid.UUID       `json:"StockID"`
+	Month      string          `json:"Month"`
+	TotalQty   int             `json:"TotalQty"`
+	Categories []wasteCategory `json:"Categories"`
+}
+
+type wasteCategory struct {
+	Category string         `json:"Category"`
+	Quantity int            `json:"Quantity"`
+	Products []wasteProduct `json:"Products"`
+}
+
+type wasteProduct struct {
+	ProductID   uuid.UUID `json:"ProductID"`
+	ProductName string    `json:"ProductName"`
+	Unit        string    `json:"Unit,omitempty"`
+	Quantity    int       `json:"Quantity"`
+	Movements   int       `json:"Movements"`
+}
+
+// wasteTotal is the waste of one product in the report's month.
+type wasteTotal struct {
+	ProductID uuid.UUID `bson:"_id"`
+	Quantity  int       `bson:"Quantity"`
+	Movements int       `bson:"Movements"`
+}
+
+type wasteExpiredResponse struct {
+	Wasted  []models.StockMovements `json:"Wasted"`
+	Skipped []wasteSkippedLot       `json:"Skipped"`
+}
+
+type wasteSkippedLot struct {
+	ProductID uuid.UUID `json:"ProductID"`
+	LotID     uuid.UUID `json:"LotID"`
+	Reason    string    `json:"Reason"`
+}
+
+// GetWasteReport godoc
+// @Summary      Monthly waste report
+// @Description  Sums the stock's WASTE movements in a month per category and product, largest first. Products without a category are reported under an empty Category.
+// @Tags         warehouse
+// @Produce      json
+// @Security     BearerAuth
+// @Param        stockId  path   string  true   "Stock ID (UUID)"
+// @Param        month    query  string  false  "Month as YYYY-MM (default: the current month, UTC)"
+// @Success      200  {object}  wasteReport
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/warehouse/{stockId}/waste-report [get]
+func GetWasteReport(c *fiber.Ctx) error {
+	stockUUID, err := stockIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	start, end, err := wasteMonth(c.Query("month"), time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := authorizeStock(ctx, c, stockUUID, inventory.AccessViewer); err != nil {
+		return err
+	}
+
+	movementsCol, err := db.StockMovementsCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	cursor, err := movementsCol.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"StockID":   stockUUID,
+			"Type":      models.MovementTypeWaste,
+			"CreatedAt": bson.M{"$gte": start, "$lt": end},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       "$ProductID",
+			"Quantity":  bson.M{"$sum": bson.M{"$multiply": bson.A{"$Delta", -1}}},
+			"Movements": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to aggregate waste")
+	}
+	var totals []wasteTotal
+	if err := cursor.All(ctx, &totals); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to decode waste totals")
+	}
+
+	productIDs := make(bson.A, 0, len(totals))
+	for _, total := range totals {
+		productIDs = append(productIDs, total.ProductID)
+	}
+	products, err := findAll[models.Products](ctx, db.ProductsCollection, bson.M{"ProductID": bson.M{"$in": productIDs}})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch products")
+	}
+	byID := make(map[uuid.UUID]models.Products, len(products))
+	for _, product := range products {
+		byID[product.ProductID] = product
+	}
+
+	return c.JSON(buildWasteReport(stockUUID, start, totals, byID))
+}
+
+// wasteMonth returns the bounds of the month raw names as YYYY-MM, or of the
+// current UTC month when raw is empty.
+func wasteMonth(raw string, now time.Time) (time.Time, time.Time, error) {
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if raw = strings.TrimSpace(raw); raw != "" {
+		var err error
+		start, err = time.Parse("2006-01", raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, fiber.NewError(fiber.StatusBadRequest, "month must be formatted as YYYY-MM")
+		}
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// buildWasteReport groups the month's per-product totals by the category of
+// each product, ordering categories and their products largest first.
+func buildWasteReport(stockID uuid.UUID, start time.Time, totals []wasteTotal, products map[uuid.UUID]models.Products) wasteReport {
+	report := wasteReport{StockID: stockID, Month: start.Format("2006-01"), Categories: []wasteCategory{}}
+	categories := map[string]int{}
+	for _, total := range totals {
+		product := products[total.ProductID]
+		i, ok := categories[product.Category]
+		if !ok {
+			i = len(report.Categories)
+			categories[product.Category] = i
+			report.Categories = append(report.Categories, wasteCategory{Category: product.Category})
+		}
+		report.Categories[i].Quantity += total.Quantity
+		report.Categories[i].Products = append(report.Categories[i].Products, wasteProduct{
+			ProductID:   total.ProductID,
+			ProductName: product.ProductName,
+			Unit:        product.Unit,
+			Quantity:    total.Quantity,
+			Movements:   total.Movements,
+		})
+		report.TotalQty += total.Quantity
+	}
+
+	sort.SliceStable(report.Categories, func(i, j int) bool {
+		a, b := report.Categories[i], report.Categories[j]
+		if a.Quantity != b.Quantity {
+			return a.Quantity > b.Quantity
+		}
+		return a.Category < b.Category
+	})
+	for _, category := range report.Categories {
+		sort.SliceStable(category.Products, func(i, j int) bool {
+			a, b := category.Products[i], category.Products[j]
+			if a.Quantity != b.Quantity {
+				return a.Quantity > b.Quantity
+			}
+			return strings.ToLower(a.ProductName) < strings.ToLower(b.ProductName)
+		})
+	}
+
+	return report
+}
+
+// WasteExpiredLots godoc
+// @Summary      Write off expired lots
+// @Description  Records a WASTE movement for every lot in the stock that has expired, taking its whole remaining quantity. Lots that changed in the meantime are reported as Skipped.
+// @Tags         warehouse
+// @Produce      json
+// @Security     BearerAuth
+// @Param        stockId  path  string  true  "Stock ID (UUID)"
+// @Success      200  {object}  wasteExpiredResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /api/warehouse/{stockId}/waste-expired [post]
+func WasteExpiredLots(c *fiber.Ctx) error {
+	stockUUID, err := stockIDParam(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := authorizeStock(ctx, c, stockUUID, inventory.AccessEditor); err != nil {
+		return err
+	}
+
+	today := inventory.StartOfDay(time.Now())
+	products, err := findAll[models.Products](ctx, db.ProductsCollection, bson.M{
+		"StockID":        stockUUID,
+		"Lots.ExpiresAt": bson.M{"$lt": today},
+	})
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch products")
+	}
+
+	resp := wasteExpiredResponse{Wasted: []models.StockMovements{}, Skipped: []wasteSkippedLot{}}
+	for _, product := range products {
+		for _, lot := range product.Lots {
+			if lot.ExpiresAt == nil || !lot.ExpiresAt.Before(today) {
+				continue
+			}
+			lotID := lot.LotID
+			movement, _, err := applyMovement(ctx, c, product.ProductID, movementInput{
+				Type:   models.MovementTypeWaste,
+				Delta:  -lot.Quantity,
+				Reason: "expired",
+				LotID:  &lotID,
+			})
+			if err != nil {
+				resp.Skipped = append(resp.Skipped, wasteSkippedLot{ProductID: product.ProductID, LotID: lot.LotID, Reason: errorMessage(err)})
+				continue
+			}
+			resp.Wasted = append(resp.Wasted, movement)
+		}
+	}
+
+	return c.JSON(resp)
+}
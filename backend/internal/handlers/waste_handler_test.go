@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestWasteMonth(t *testing.T) {
+	utc := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	now := utc(2026, time.March, 31, 23)
+
+	tests := []struct {
+		name       string
+		raw        string
+		now        time.Time
+		start, end time.Time
+	}{
+		{"default is the current month", "", now, utc(2026, time.March, 1, 0), utc(2026, time.April, 1, 0)},
+		{"current month in UTC", "", time.Date(2026, time.April, 1, 1, 0, 0, 0, time.FixedZone("CEST", 2*60*60)), utc(2026, time.March, 1, 0), utc(2026, time.April, 1, 0)},
+		{"named month", "2026-02", now, utc(2026, time.February, 1, 0), utc(2026, time.March, 1, 0)},
+		{"December runs into the next year", " 2025-12 ", now, utc(2025, time.December, 1, 0), utc(2026, time.January, 1, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := wasteMonth(tt.raw, tt.now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !start.Equal(tt.start) || !end.Equal(tt.end) {
+				t.Errorf("month [%s, %s), want [%s, %s)", start, end, tt.start, tt.end)
+			}
+		})
+	}
+
+	for _, raw := range []string{"2026-13", "2026-3", "03-2026", "2026-03-01"} {
+		var fiberErr *fiber.Error
+		if _, _, err := wasteMonth(raw, now); !errors.As(err, &fiberErr) || fiberErr.Code != fiber.StatusBadRequest {
+			t.Errorf("wasteMonth(%q): %v, want a 400", raw, err)
+		}
+	}
+}
+
+func TestBuildWasteReport(t *testing.T) {
+	stockID := uuid.New()
+	product := func(name, category string) models.Products {
+		return models.Products{ProductID: uuid.New(), StockID: stockID, ProductName: name, Category: category, Unit: "pcs"}
+	}
+	milk := product("milk", "Dairy")
+	yoghurt := product("Yoghurt", "Dairy")
+	bread := product("Bread", "Bakery")
+	apples := product("Apples", "")
+	deleted := uuid.New()
+	products := map[uuid.UUID]models.Products{
+		milk.ProductID: milk, yoghurt.ProductID: yoghurt, bread.ProductID: bread, apples.ProductID: apples,
+	}
+
+	totals := []wasteTotal{
+		{ProductID: bread.ProductID, Quantity: 6, Movements: 2},
+		{ProductID: milk.ProductID, Quantity: 2, Movements: 1},
+		{ProductID: apples.ProductID, Quantity: 1, Movements: 1},
+		{ProductID: yoghurt.ProductID, Quantity: 2, Movements: 2},
+		{ProductID: deleted, Quantity: 3, Movements: 1},
+	}
+	start := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	entry := func(p models.Products, qty, movements int) wasteProduct {
+		return wasteProduct{ProductID: p.ProductID, ProductName: p.ProductName, Unit: p.Unit, Quantity: qty, Movements: movements}
+	}
+	want := wasteReport{
+		StockID:  stockID,
+		Month:    "2026-03",
+		TotalQty: 14,
+		Categories: []wasteCategory{
+			{Category: "Bakery", Quantity: 6, Products: []wasteProduct{entry(bread, 6, 2)}},
+			// Products without a category, and products deleted since, share
+			// the empty category.
+			{Category: "", Quantity: 4, Products: []wasteProduct{
+				{ProductID: deleted, Quantity: 3, Movements: 1},
+				entry(apples, 1, 1),
+			}},
+			// Equal quantities are ordered by name, ignoring case.
+			{Category: "Dairy", Quantity: 4, Products: []wasteProduct{entry(milk, 2, 1), entry(yoghurt, 2, 2)}},
+		},
+	}
+
+	if got := buildWasteReport(stockID, start, totals, products); !reflect.DeepEqual(got, want) {
+		t.Errorf("report\n got %+v\nwant %+v", got, want)
+	}
+
+	empty := buildWasteReport(stockID, start, nil, nil)
+	if empty.TotalQty != 0 || empty.Categories == nil || len(empty.Categories) != 0 {
+		t.Errorf("report without waste %+v, want no categories encoded as []", empty)
+	}
+}
@@ -0,0 +1,96 @@
+// Package inventory holds the stock rules that the HTTP handlers and the
+// background jobs share: who may access a stock, how product updates detect
+// concurrent changes and the alerts sent to a stock's subscribers.
+package inventory
+
+import (
+	"context"
+	"errors"
+
+	"my-backend/internal/db"
+	"my-backend/internal/models"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AccessLevel orders what a user may do with a stock; higher levels include
+// every permission of the lower ones.
+type AccessLevel int
+
+const (
+	AccessNone AccessLevel = iota
+	AccessViewer
+	AccessEditor
+	AccessOwner
+)
+
+// HouseholdStockAccess maps a household role onto the access its members get
+// on the household's stocks. Owning the household does not make a member an
+// owner of the stocks in it: deleting, transferring and sharing a stock stay
+// with its owner.
+var HouseholdStockAccess = map[string]AccessLevel{
+	models.HouseholdRoleOwner:  AccessEditor,
+	models.HouseholdRoleEditor: AccessEditor,
+	models.HouseholdRoleViewer: AccessViewer,
+}
+
+// StockShareAccess maps a per-stock share role onto stock access.
+var StockShareAccess = map[string]AccessLevel{
+	models.StockShareRoleEditor: AccessEditor,
+	models.StockShareRoleViewer: AccessViewer,
+}
+
+// AccessFor resolves the access level a user holds on a stock: owners of the
+// stock have full access, members of its household get their role's level
+// and users it is shared with get the share's level. The highest one wins.
+func AccessFor(ctx context.Context, userID uuid.UUID, stock models.Warehouse) (AccessLevel, error) {
+	if stock.UserID == userID {
+		return AccessOwner, nil
+	}
+
+	level := AccessNone
+
+	sharesCol, err := db.StockSharesCollection(ctx)
+	if err != nil {
+		return AccessNone, err
+	}
+	var share models.StockShares
+	err = sharesCol.FindOne(ctx, bson.M{"StockID": stock.StockID, "UserID": userID}).Decode(&share)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return AccessNone, err
+	}
+	if err == nil {
+		level = StockShareAccess[share.Role]
+	}
+
+	if stock.HouseholdID != nil {
+		member, err := HouseholdMembership(ctx, *stock.HouseholdID, userID)
+		if err != nil {
+			return AccessNone, err
+		}
+		if member != nil && HouseholdStockAccess[member.Role] > level {
+			level = HouseholdStockAccess[member.Role]
+		}
+	}
+	return level, nil
+}
+
+// HouseholdMembership returns the user's membership of the household, or nil
+// when they are not a member.
+func HouseholdMembership(ctx context.Context, householdID, userID uuid.UUID) (*models.HouseholdMembers, error) {
+	collection, err := db.HouseholdMembersCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var member models.HouseholdMembers
+	if err := collection.FindOne(ctx, bson.M{"HouseholdID": householdID, "UserID": userID}).Decode(&member); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &member, nil
+}
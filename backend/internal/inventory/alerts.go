@@ -0,0 +1,193 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"my-backend/internal/config"
+	"my-backend/internal/db"
+	mailer "my-backend/internal/mail"
+	"my-backend/internal/models"
+	"my-backend/internal/notify"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const defaultExpiryAlertDays = 3
+
+// NotifySubscribers queues a notification for every user with notification
+// settings on the stock who can still access it.
+func NotifySubscribers(ctx context.Context, stockID uuid.UUID, build func(stock models.Warehouse) notify.Notification) error {
+	warehouseCol, err := db.WarehouseCollection(ctx)
+	if err != nil {
+		return err
+	}
+	var stock models.Warehouse
+	if err := warehouseCol.FindOne(ctx, bson.M{"StockID": stockID}).Decode(&stock); err != nil {
+		return err
+	}
+
+	settingsCol, err := db.NotificationSettingsCollection(ctx)
+	if err != nil {
+		return err
+	}
+	cursor, err := settingsCol.Find(ctx, bson.M{
+		"StockID":  stockID,
+		"Channels": bson.M{"$ne": bson.A{}},
+	})
+	if err != nil {
+		return err
+	}
+	var subscribers []models.NotificationSettings
+	if err := cursor.All(ctx, &subscribers); err != nil {
+		return err
+	}
+
+	n := build(stock)
+	for _, settings := range subscribers {
+		level, err := AccessFor(ctx, settings.UserID, stock)
+		if err != nil {
+			return err
+		}
+		if level < AccessViewer {
+			continue
+		}
+		if err := notify.Enqueue(ctx, settings, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NotifyExpiringLots tells the subscribers of each stock about lots that
+// expire within EXPIRY_ALERT_DAYS, or already have, once per lot. It is run
+// periodically by the jobs package.
+func NotifyExpiringLots(ctx context.Context) error {
+	now := time.Now().UTC()
+	until := StartOfDay(now).AddDate(0, 0, config.Int("EXPIRY_ALERT_DAYS", defaultExpiryAlertDays)+1)
+
+	collection, err := db.ProductsCollection(ctx)
+	if err != nil {
+		return err
+	}
+	cursor, err := collection.Find(ctx, bson.M{
+		"Lots": bson.M{"$elemMatch": bson.M{
+			"ExpiresAt":        bson.M{"$lt": until},
+			"ExpiryNotifiedAt": nil,
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	var products []models.Products
+	if err := cursor.All(ctx, &products); err != nil {
+		return err
+	}
+
+	notified := 0
+	for _, product := range products {
+		lots, ok, err := flagExpiringLots(ctx, product, until, now)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// Changed since it was read; the next run picks it up again.
+			continue
+		}
+		if err := NotifySubscribers(ctx, product.StockID, func(stock models.Warehouse) notify.Notification {
+			return expiringLotsNotification(stock, product, lots, now)
+		}); err != nil {
+			log.Printf("expiry alerts: failed to queue notifications for %s: %v", product.ProductID, err)
+		}
+		notified += len(lots)
+	}
+
+	if notified > 0 {
+		log.Printf("flagged %d expiring lots", notified)
+	}
+	return nil
+}
+
+// flagExpiringLots marks the product's unflagged lots expiring before until
+// as notified and returns them. The update is conditional on the product's
+// Revision, like movements, so it neither loses nor is lost to a concurrent
+// change of the lots; ok is false when such a change got in first.
+func flagExpiringLots(ctx context.Context, product models.Products, until, now time.Time) ([]models.ProductLots, bool, error) {
+	lots := make([]models.ProductLots, len(product.Lots))
+	copy(lots, product.Lots)
+
+	var flagged []models.ProductLots
+	for i := range lots {
+		if lots[i].ExpiresAt == nil || !lots[i].ExpiresAt.Before(until) || lots[i].ExpiryNotifiedAt != nil {
+			continue
+		}
+		lots[i].ExpiryNotifiedAt = &now
+		flagged = append(flagged, lots[i])
+	}
+	if len(flagged) == 0 {
+		return nil, false, nil
+	}
+
+	collection, err := db.ProductsCollection(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	res, err := collection.UpdateOne(ctx,
+		bson.M{"ProductID": product.ProductID, "Revision": RevisionFilter(product.Revision)},
+		bson.M{"$set": bson.M{"Lots": lots}, "$inc": bson.M{"Revision": 1}},
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	return flagged, res.ModifiedCount > 0, nil
+}
+
+func expiringLotsNotification(stock models.Warehouse, product models.Products, lots []models.ProductLots, now time.Time) notify.Notification {
+	today := StartOfDay(now)
+	parts := make([]string, 0, len(lots))
+	for _, lot := range lots {
+		label := FormatQty(lot.Quantity, product.Unit)
+		if lot.LotNumber != "" {
+			label += " (lot " + lot.LotNumber + ")"
+		}
+		switch days := int(StartOfDay(*lot.ExpiresAt).Sub(today).Hours() / 24); {
+		case days < 0:
+			label += " expired on " + lot.ExpiresAt.Format("2006-01-02")
+		case days == 0:
+			label += " expires today"
+		default:
+			label += " expires on " + lot.ExpiresAt.Format("2006-01-02")
+		}
+		parts = append(parts, label)
+	}
+
+	return notify.Notification{
+		Kind:    models.NotificationKindExpiring,
+		Title:   "Expiring soon: " + product.ProductName,
+		Message: fmt.Sprintf("%s in %s: %s.", product.ProductName, stock.StockName, strings.Join(parts, "; ")),
+		Link:    mailer.AppURL("/warehouse/" + stock.StockID.String()),
+		Data: map[string]string{
+			"stockId":   stock.StockID.String(),
+			"productId": product.ProductID.String(),
+		},
+	}
+}
+
+// FormatQty renders a quantity with its unit for notification texts.
+func FormatQty(qty int, unit string) string {
+	if unit == "" {
+		return fmt.Sprint(qty)
+	}
+	return fmt.Sprintf("%d %s", qty, unit)
+}
+
+// StartOfDay truncates t to midnight UTC, the boundary expiry dates are
+// compared on.
+func StartOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
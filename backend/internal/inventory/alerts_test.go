@@ -0,0 +1,130 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/models"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Like the handler tests, these run against the MongoDB server in MONGO_URL
+// in a scratch database, and are skipped without it.
+func TestMain(m *testing.M) {
+	if os.Getenv("MONGO_URL") == "" {
+		os.Exit(m.Run())
+	}
+
+	name := "inventory_test_" + uuid.NewString()[:8]
+	os.Setenv("MONGO_DB_NAME", name)
+
+	code := m.Run()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if client, err := db.Client(ctx); err == nil {
+		if err := client.Database(name).Drop(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "dropping %s: %v\n", name, err)
+		}
+	}
+	os.Exit(code)
+}
+
+// insertProduct stores a product with lots and returns it as read back.
+func insertProduct(t *testing.T, lots ...models.ProductLots) (*mongo.Collection, models.Products) {
+	t.Helper()
+	if os.Getenv("MONGO_URL") == "" {
+		t.Skip("MONGO_URL is not set; these tests need a MongoDB server")
+	}
+	ctx := context.Background()
+	collection, err := db.ProductsCollection(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	product := models.Products{ProductID: uuid.New(), StockID: uuid.New(), ProductName: "Yoghurt", Lots: lots}
+	for _, lot := range lots {
+		product.ProductQty += lot.Quantity
+	}
+	if _, err := collection.InsertOne(ctx, product); err != nil {
+		t.Fatal(err)
+	}
+	return collection, findProduct(t, collection, product.ProductID)
+}
+
+func findProduct(t *testing.T, collection *mongo.Collection, productID uuid.UUID) models.Products {
+	t.Helper()
+	var product models.Products
+	if err := collection.FindOne(context.Background(), bson.M{"ProductID": productID}).Decode(&product); err != nil {
+		t.Fatal(err)
+	}
+	return product
+}
+
+func expiringLot(expiresAt time.Time) models.ProductLots {
+	return models.ProductLots{LotID: uuid.New(), Quantity: 2, ExpiresAt: &expiresAt, ReceivedAt: expiresAt.AddDate(0, 0, -14)}
+}
+
+func TestFlagExpiringLotsFlagsEachLotOnce(t *testing.T) {
+	now := time.Now().UTC()
+	until := StartOfDay(now).AddDate(0, 0, defaultExpiryAlertDays+1)
+	soon := expiringLot(now.AddDate(0, 0, 1))
+	later := expiringLot(now.AddDate(0, 0, 30))
+	collection, product := insertProduct(t, soon, later)
+	ctx := context.Background()
+
+	flagged, ok, err := flagExpiringLots(ctx, product, until, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || len(flagged) != 1 || flagged[0].LotID != soon.LotID {
+		t.Fatalf("first run flagged %v (ok %v), want only the lot expiring tomorrow", flagged, ok)
+	}
+
+	product = findProduct(t, collection, product.ProductID)
+	if product.Lots[0].ExpiryNotifiedAt == nil || product.Lots[1].ExpiryNotifiedAt != nil {
+		t.Errorf("stored lots %+v, want only the first one flagged", product.Lots)
+	}
+
+	flagged, ok, err = flagExpiringLots(ctx, product, until, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok || len(flagged) != 0 {
+		t.Errorf("second run flagged %v (ok %v), want nothing", flagged, ok)
+	}
+}
+
+func TestFlagExpiringLotsSkipsProductsChangedMeanwhile(t *testing.T) {
+	now := time.Now().UTC()
+	until := StartOfDay(now).AddDate(0, 0, defaultExpiryAlertDays+1)
+	collection, product := insertProduct(t, expiringLot(now.AddDate(0, 0, 1)))
+	ctx := context.Background()
+
+	// A movement lands between the scan and the flagging.
+	if _, err := collection.UpdateOne(ctx,
+		bson.M{"ProductID": product.ProductID},
+		bson.M{"$inc": bson.M{"ProductQty": -1, "Revision": 1}},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	flagged, ok, err := flagExpiringLots(ctx, product, until, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("flagged %v over a newer revision", flagged)
+	}
+	stored := findProduct(t, collection, product.ProductID)
+	if stored.Lots[0].ExpiryNotifiedAt != nil || stored.ProductQty != product.ProductQty-1 {
+		t.Errorf("stored product %+v, want the movement kept and the lot unflagged", stored)
+	}
+}
@@ -0,0 +1,12 @@
+package inventory
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// RevisionFilter matches a product still at revision; products written before
+// revisions existed have none and count as 0.
+func RevisionFilter(revision int) interface{} {
+	if revision == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return revision
+}
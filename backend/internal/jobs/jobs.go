@@ -0,0 +1,38 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"my-backend/internal/inventory"
+	"my-backend/internal/notify"
+)
+
+// Start launches the backend's periodic maintenance jobs. They stop when ctx
+// is cancelled.
+func Start(ctx context.Context) {
+	go every(ctx, "purge unverified accounts", time.Hour, purgeUnverifiedAccounts)
+	go every(ctx, "deliver notifications", time.Minute, notify.DeliverPending)
+	go every(ctx, "expiry alerts", time.Hour, inventory.NotifyExpiringLots)
+}
+
+// every runs job immediately and then once per interval until ctx is done.
+func every(ctx context.Context, name string, interval time.Duration, job func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		runCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		if err := job(runCtx); err != nil {
+			log.Printf("job %q failed: %v", name, err)
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
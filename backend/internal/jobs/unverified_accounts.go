@@ -0,0 +1,71 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"my-backend/internal/config"
+	"my-backend/internal/db"
+	"my-backend/internal/models"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const defaultUnverifiedAccountTTL = 7 * 24 * time.Hour
+
+// purgeUnverifiedAccounts deletes accounts that never verified their email
+// within UNVERIFIED_ACCOUNT_TTL of registering, freeing the address again.
+func purgeUnverifiedAccounts(ctx context.Context) error {
+	usersCol, err := db.UsersCollection(ctx)
+	if err != nil {
+		return err
+	}
+	verificationsCol, err := db.EmailVerificationsCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().UTC().Add(-config.Duration("UNVERIFIED_ACCOUNT_TTL", defaultUnverifiedAccountTTL))
+	filter := bson.M{
+		"Status":    models.UserStatusPendingVerification,
+		"CreatedAt": bson.M{"$lt": cutoff},
+	}
+
+	cursor, err := usersCol.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	var users []models.Users
+	if err := cursor.All(ctx, &users); err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	userIDs := make(bson.A, 0, len(users))
+	userUUIDs := make(bson.A, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.UserID)
+		if userUUID, err := uuid.Parse(user.UserID); err == nil {
+			userUUIDs = append(userUUIDs, userUUID)
+		}
+	}
+
+	res, err := usersCol.DeleteMany(ctx, bson.M{
+		"UserId":    bson.M{"$in": userIDs},
+		"Status":    models.UserStatusPendingVerification,
+		"CreatedAt": bson.M{"$lt": cutoff},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := verificationsCol.DeleteMany(ctx, bson.M{"UserID": bson.M{"$in": userUUIDs}}); err != nil {
+		return err
+	}
+
+	log.Printf("purged %d unverified accounts", res.DeletedCount)
+	return nil
+}
@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogSender writes messages to the server log instead of sending them. It is
+// meant for local development.
+type LogSender struct{}
+
+func (LogSender) Send(_ context.Context, msg Message) error {
+	log.Printf("mail to=%q subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileSender appends messages to a file so they can be inspected during
+// local development.
+type FileSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSender returns a FileSender writing to path, or mail.log when empty.
+func NewFileSender(path string) *FileSender {
+	if path == "" {
+		path = "mail.log"
+	}
+	return &FileSender{path: path}
+}
+
+func (s *FileSender) Send(_ context.Context, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n----\n",
+		time.Now().Format(time.RFC1123Z), msg.To, msg.Subject, msg.Body)
+	return err
+}
@@ -0,0 +1,62 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers email messages.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultSender Sender
+)
+
+// DefaultSender returns the sender configured by MAIL_DRIVER:
+// "smtp" uses the SMTP_* variables, "file" appends messages to MAIL_FILE_PATH
+// and anything else (the default) writes messages to the server log.
+func DefaultSender() Sender {
+	defaultOnce.Do(func() {
+		switch strings.ToLower(os.Getenv("MAIL_DRIVER")) {
+		case "smtp":
+			defaultSender = SMTPSenderFromEnv()
+		case "file":
+			defaultSender = NewFileSender(os.Getenv("MAIL_FILE_PATH"))
+		default:
+			defaultSender = LogSender{}
+		}
+	})
+	return defaultSender
+}
+
+// AppURL joins path onto APP_BASE_URL, the public address of the frontend
+// used in links sent by email.
+func AppURL(path string) string {
+	base := strings.TrimRight(os.Getenv("APP_BASE_URL"), "/")
+	if base == "" {
+		base = "http://localhost:5173"
+	}
+	return base + path
+}
+
+// APIURL joins path onto API_BASE_URL, the public address of this backend,
+// for links that must reach the API directly rather than the frontend.
+func APIURL(path string) string {
+	base := strings.TrimRight(os.Getenv("API_BASE_URL"), "/")
+	if base == "" {
+		base = "http://localhost:8080"
+	}
+	return base + path
+}
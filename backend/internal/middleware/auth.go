@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"my-backend/internal/auth"
+	"my-backend/internal/db"
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	currentUserKey    = "currentUser"
+	currentSessionKey = "currentSession"
+	currentAPIKeyKey  = "currentAPIKey"
+)
+
+// RequireAuth authenticates the request with either an access token
+// ("Authorization: Bearer ...") or a personal API key ("Authorization: ApiKey
+// ...") and stores the authenticated user in c.Locals for downstream handlers.
+func RequireAuth(c *fiber.Ctx) error {
+	scheme, credential, ok := authorizationHeader(c.Get(fiber.HeaderAuthorization))
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "missing bearer token or API key")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var userID string
+	switch {
+	case strings.EqualFold(scheme, "Bearer"):
+		sessionUUID, subject, err := authenticateSession(ctx, credential)
+		if err != nil {
+			return err
+		}
+		userID = subject
+		c.Locals(currentSessionKey, sessionUUID)
+	case strings.EqualFold(scheme, "ApiKey"):
+		key, err := authenticateAPIKey(ctx, credential)
+		if err != nil {
+			return err
+		}
+		userID = key.UserID.String()
+		c.Locals(currentAPIKeyKey, key)
+	default:
+		return fiber.NewError(fiber.StatusUnauthorized, "unsupported authorization scheme")
+	}
+
+	collection, err := db.UsersCollection(ctx)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	var user models.Users
+	if err := collection.FindOne(ctx, bson.M{"UserId": userID}).Decode(&user); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid credentials")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch user")
+	}
+
+	if err := AccountStatusError(user); err != nil {
+		return err
+	}
+
+	c.Locals(currentUserKey, user)
+	return c.Next()
+}
+
+// authenticateSession verifies an access token and checks that its session
+// is still active, so logout takes effect before the token itself expires.
+func authenticateSession(ctx context.Context, token string) (uuid.UUID, string, error) {
+	claims, err := auth.ParseAccessToken(token)
+	if err != nil {
+		if errors.Is(err, auth.ErrExpiredToken) {
+			return uuid.Nil, "", fiber.NewError(fiber.StatusUnauthorized, "token expired")
+		}
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return uuid.Nil, "", fiber.NewError(fiber.StatusUnauthorized, "invalid token")
+		}
+		return uuid.Nil, "", fiber.NewError(fiber.StatusInternalServerError, "authentication unavailable")
+	}
+
+	userUUID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, "", fiber.NewError(fiber.StatusUnauthorized, "invalid token")
+	}
+	sessionUUID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return uuid.Nil, "", fiber.NewError(fiber.StatusUnauthorized, "invalid token")
+	}
+
+	sessionsCol, err := db.SessionsCollection(ctx)
+	if err != nil {
+		return uuid.Nil, "", fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	sessionCount, err := sessionsCol.CountDocuments(ctx, bson.M{
+		"SessionID": sessionUUID,
+		"UserID":    userUUID,
+		"RevokedAt": nil,
+		"ExpiresAt": bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return uuid.Nil, "", fiber.NewError(fiber.StatusInternalServerError, "failed to fetch session")
+	}
+	if sessionCount == 0 {
+		return uuid.Nil, "", fiber.NewError(fiber.StatusUnauthorized, "session revoked")
+	}
+
+	return sessionUUID, claims.Subject, nil
+}
+
+// authenticateAPIKey resolves an unrevoked, unexpired API key and records
+// when it was last used.
+func authenticateAPIKey(ctx context.Context, rawKey string) (models.APIKeys, error) {
+	collection, err := db.APIKeysCollection(ctx)
+	if err != nil {
+		return models.APIKeys{}, fiber.NewError(fiber.StatusInternalServerError, "database unavailable")
+	}
+
+	now := time.Now().UTC()
+	var key models.APIKeys
+	err = collection.FindOne(ctx, bson.M{
+		"KeyHash":   auth.HashOpaqueToken(rawKey),
+		"RevokedAt": nil,
+		"$or": bson.A{
+			bson.M{"ExpiresAt": nil},
+			bson.M{"ExpiresAt": bson.M{"$gt": now}},
+		},
+	}).Decode(&key)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return models.APIKeys{}, fiber.NewError(fiber.StatusUnauthorized, "invalid API key")
+		}
+		return models.APIKeys{}, fiber.NewError(fiber.StatusInternalServerError, "failed to fetch API key")
+	}
+
+	// Only write LastUsedAt once a minute so busy scanners do not turn every
+	// read into a write.
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > time.Minute {
+		_, _ = collection.UpdateOne(ctx, bson.M{"KeyID": key.KeyID}, bson.M{"$set": bson.M{"LastUsedAt": now}})
+	}
+
+	return key, nil
+}
+
+// CurrentUser returns the user resolved by RequireAuth.
+func CurrentUser(c *fiber.Ctx) (models.Users, bool) {
+	user, ok := c.Locals(currentUserKey).(models.Users)
+	return user, ok
+}
+
+// CurrentSessionID returns the session the request's access token belongs to.
+// It is not set for requests authenticated with an API key.
+func CurrentSessionID(c *fiber.Ctx) (uuid.UUID, bool) {
+	sessionID, ok := c.Locals(currentSessionKey).(uuid.UUID)
+	return sessionID, ok
+}
+
+// CurrentAPIKey returns the API key the request was authenticated with, if any.
+func CurrentAPIKey(c *fiber.Ctx) (models.APIKeys, bool) {
+	key, ok := c.Locals(currentAPIKeyKey).(models.APIKeys)
+	return key, ok
+}
+
+// CurrentUserID returns the authenticated user's ID as a UUID.
+func CurrentUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	user, ok := CurrentUser(c)
+	if !ok {
+		return uuid.Nil, fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+	}
+
+	userUUID, err := uuid.Parse(user.UserID)
+	if err != nil {
+		return uuid.Nil, fiber.NewError(fiber.StatusInternalServerError, "stored user ID is not a valid UUID")
+	}
+	return userUUID, nil
+}
+
+func authorizationHeader(header string) (string, string, bool) {
+	scheme, credential, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found {
+		return "", "", false
+	}
+	credential = strings.TrimSpace(credential)
+	return scheme, credential, credential != ""
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole only lets through users authenticated by RequireAuth whose role
+// is one of roles.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := CurrentUser(c)
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, "authentication required")
+		}
+
+		role := user.EffectiveRole()
+		for _, allowed := range roles {
+			if role == allowed {
+				return c.Next()
+			}
+		}
+
+		return fiber.NewError(fiber.StatusForbidden, "insufficient role")
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireScope rejects API key requests whose key was not granted scope.
+// Session tokens carry every scope.
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if HasScope(c, scope) {
+			return c.Next()
+		}
+		return fiber.NewError(fiber.StatusForbidden, "API key is missing scope "+scope)
+	}
+}
+
+// HasScope reports whether the request may use scope, for handlers that only
+// do part of their work with it.
+func HasScope(c *fiber.Ctx, scope string) bool {
+	key, ok := CurrentAPIKey(c)
+	if !ok {
+		return true
+	}
+	for _, granted := range key.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireSession rejects requests authenticated with an API key. Account
+// management (sessions, 2FA, API keys, admin) needs an interactive login.
+func RequireSession(c *fiber.Ctx) error {
+	if _, ok := CurrentSessionID(c); !ok {
+		return fiber.NewError(fiber.StatusForbidden, "this endpoint requires a signed-in session")
+	}
+	return c.Next()
+}
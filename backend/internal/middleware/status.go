@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"my-backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AccountStatusError returns a 403 error describing why an account that is
+// not active may not sign in, or nil for active accounts.
+func AccountStatusError(user models.Users) error {
+	if user.IsActive() {
+		return nil
+	}
+
+	switch user.Status {
+	case models.UserStatusSuspended:
+		return fiber.NewError(fiber.StatusForbidden, "account is suspended")
+	case models.UserStatusPendingVerification:
+		return fiber.NewError(fiber.StatusForbidden, "account is pending email verification")
+	default:
+		return fiber.NewError(fiber.StatusForbidden, "account is inactive")
+	}
+}
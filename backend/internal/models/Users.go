@@ -1,6 +1,24 @@
 package models
 
-// User represents a registered user stored in MongoDB.
+import "time"
+
+// Roles a user can hold. Users stored before roles existed have no Role and
+// are treated as RoleUser.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Account statuses stored in Users.Status. Only active accounts may sign in.
+const (
+	UserStatusActive              = "ACTIVE"
+	UserStatusInactive            = "INACTIVE"
+	UserStatusSuspended           = "SUSPENDED"
+	UserStatusPendingVerification = "PENDING_VERIFICATION"
+)
+
+// User represents a registered user stored in MongoDB. It is returned as-is
+// by the API, so credentials and secrets must keep their json:"-" tags.
 type Users struct {
 	UserID       string `bson:"UserId" json:"UserId"`
 	Email        string `bson:"Email" json:"Email"`
@@ -8,4 +26,33 @@ type Users struct {
 	PasswordHash string `bson:"PasswordHash,omitempty" json:"-"`
 	AvatarURL    string `bson:"AvatarURL,omitempty" json:"AvatarURL,omitempty"`
 	Status       string `bson:"Status" json:"Status"`
+	Role         string `bson:"Role,omitempty" json:"Role,omitempty"`
+
+	CreatedAt       time.Time  `bson:"CreatedAt,omitempty" json:"CreatedAt,omitempty"`
+	EmailVerifiedAt *time.Time `bson:"EmailVerifiedAt,omitempty" json:"EmailVerifiedAt,omitempty"`
+
+	// Identity at the OpenID Connect provider the account is linked to.
+	OIDCIssuer  string `bson:"OIDCIssuer,omitempty" json:"OIDCIssuer,omitempty"`
+	OIDCSubject string `bson:"OIDCSubject,omitempty" json:"OIDCSubject,omitempty"`
+
+	// Two-factor authentication. Secrets and recovery code hashes are never serialized.
+	TOTPEnabled        bool     `bson:"TOTPEnabled,omitempty" json:"TOTPEnabled,omitempty"`
+	TOTPSecret         string   `bson:"TOTPSecret,omitempty" json:"-"`
+	TOTPPendingSecret  string   `bson:"TOTPPendingSecret,omitempty" json:"-"`
+	TOTPLastStep       int64    `bson:"TOTPLastStep,omitempty" json:"-"`
+	RecoveryCodeHashes []string `bson:"RecoveryCodeHashes,omitempty" json:"-"`
+}
+
+// EffectiveRole returns the user's role, defaulting to RoleUser.
+func (u Users) EffectiveRole() string {
+	if u.Role == "" {
+		return RoleUser
+	}
+	return u.Role
+}
+
+// IsActive reports whether the account may sign in and call the API. Users
+// stored without a Status predate status tracking and count as active.
+func (u Users) IsActive() bool {
+	return u.Status == UserStatusActive || u.Status == ""
 }
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scopes that can be granted to API keys. Session tokens carry every scope.
+const (
+	ScopeProductsRead    = "products:read"
+	ScopeProductsWrite   = "products:write"
+	ScopeCategoriesRead  = "categories:read"
+	ScopeCategoriesWrite = "categories:write"
+	ScopeWarehouseRead   = "warehouse:read"
+	ScopeWarehouseWrite  = "warehouse:write"
+)
+
+// APIKeyScopes lists every scope an API key may be granted.
+var APIKeyScopes = []string{
+	ScopeProductsRead,
+	ScopeProductsWrite,
+	ScopeCategoriesRead,
+	ScopeCategoriesWrite,
+	ScopeWarehouseRead,
+	ScopeWarehouseWrite,
+}
+
+// APIKeys represents a personal API key used by scripts and devices. Only a
+// hash of the key is stored; Prefix lets users recognise it in listings.
+type APIKeys struct {
+	KeyID      uuid.UUID  `bson:"KeyID" json:"KeyID"`
+	UserID     uuid.UUID  `bson:"UserID" json:"UserID"`
+	Name       string     `bson:"Name" json:"Name"`
+	Prefix     string     `bson:"Prefix" json:"Prefix"`
+	KeyHash    string     `bson:"KeyHash" json:"-"`
+	Scopes     []string   `bson:"Scopes" json:"Scopes"`
+	CreatedAt  time.Time  `bson:"CreatedAt" json:"CreatedAt"`
+	ExpiresAt  *time.Time `bson:"ExpiresAt,omitempty" json:"ExpiresAt,omitempty"`
+	LastUsedAt *time.Time `bson:"LastUsedAt,omitempty" json:"LastUsedAt,omitempty"`
+	RevokedAt  *time.Time `bson:"RevokedAt,omitempty" json:"RevokedAt,omitempty"`
+}
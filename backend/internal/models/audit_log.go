@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit actions.
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// Entity types recorded in the audit log.
+const (
+	AuditEntityProduct              = "product"
+	AuditEntityCategory             = "category"
+	AuditEntityStock                = "stock"
+	AuditEntityStockShare           = "stock_share"
+	AuditEntityHousehold            = "household"
+	AuditEntityHouseholdMember      = "household_member"
+	AuditEntityHouseholdInvite      = "household_invite"
+	AuditEntityAPIKey               = "api_key"
+	AuditEntityUser                 = "user"
+	AuditEntityStockMovement        = "stock_movement"
+	AuditEntityShoppingItem         = "shopping_list_item"
+	AuditEntityNotificationSettings = "notification_settings"
+	AuditEntitySession              = "session"
+	AuditEntityPassword             = "password"
+	AuditEntityTwoFactor            = "two_factor"
+)
+
+// AuditLog is one append-only record of a change. Before and After hold the
+// entity as the API returns it, so fields hidden from JSON (password and
+// token hashes) are never copied into the log.
+type AuditLog struct {
+	EntryID    uuid.UUID              `bson:"EntryID" json:"EntryID"`
+	ActorID    uuid.UUID              `bson:"ActorID" json:"ActorID"`
+	APIKeyID   *uuid.UUID             `bson:"APIKeyID,omitempty" json:"APIKeyID,omitempty"`
+	Action     string                 `bson:"Action" json:"Action"`
+	EntityType string                 `bson:"EntityType" json:"EntityType"`
+	EntityID   string                 `bson:"EntityID" json:"EntityID"`
+	StockID    *uuid.UUID             `bson:"StockID,omitempty" json:"StockID,omitempty"`
+	Before     map[string]interface{} `bson:"Before,omitempty" json:"Before,omitempty"`
+	After      map[string]interface{} `bson:"After,omitempty" json:"After,omitempty"`
+	CreatedAt  time.Time              `bson:"CreatedAt" json:"CreatedAt"`
+}
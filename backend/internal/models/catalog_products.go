@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// CatalogSourceOpenFoodFacts marks entries imported from an Open Food Facts
+// dump.
+const CatalogSourceOpenFoodFacts = "openfoodfacts"
+
+// CatalogProducts is a product from the offline catalog that new products are
+// prefilled from when their barcode is not in any stock yet. GTIN is the
+// barcode padded to 14 digits, so EAN-13 and UPC-A scans of the same product
+// find the same entry.
+type CatalogProducts struct {
+	GTIN        string `bson:"GTIN" json:"-"`
+	Barcode     string `bson:"Barcode" json:"Barcode"`
+	ProductName string `bson:"ProductName" json:"ProductName"`
+	Brand       string `bson:"Brand,omitempty" json:"Brand,omitempty"`
+	Category    string `bson:"Category,omitempty" json:"Category,omitempty"`
+	Unit        string `bson:"Unit,omitempty" json:"Unit,omitempty"`
+	// Quantity is the package size as printed, e.g. "6 x 33 cl".
+	Quantity   string    `bson:"Quantity,omitempty" json:"Quantity,omitempty"`
+	Source     string    `bson:"Source" json:"Source"`
+	ImportedAt time.Time `bson:"ImportedAt" json:"ImportedAt"`
+}
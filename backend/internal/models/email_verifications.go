@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailVerifications stores a hashed, single-use token proving ownership of Email.
+type EmailVerifications struct {
+	TokenHash string     `bson:"TokenHash" json:"-"`
+	UserID    uuid.UUID  `bson:"UserID" json:"UserID"`
+	Email     string     `bson:"Email" json:"Email"`
+	CreatedAt time.Time  `bson:"CreatedAt" json:"CreatedAt"`
+	ExpiresAt time.Time  `bson:"ExpiresAt" json:"ExpiresAt"`
+	UsedAt    *time.Time `bson:"UsedAt,omitempty" json:"UsedAt,omitempty"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Household member roles. They map onto stock access: owners manage the
+// household and its stocks, editors change inventory, viewers only read.
+const (
+	HouseholdRoleOwner  = "owner"
+	HouseholdRoleEditor = "editor"
+	HouseholdRoleViewer = "viewer"
+)
+
+// Households groups users who manage the same stocks together.
+type Households struct {
+	HouseholdID uuid.UUID `bson:"HouseholdID" json:"HouseholdID"`
+	Name        string    `bson:"Name" json:"Name"`
+	CreatedBy   uuid.UUID `bson:"CreatedBy" json:"CreatedBy"`
+	CreatedAt   time.Time `bson:"CreatedAt" json:"CreatedAt"`
+}
+
+// HouseholdMembers links a user to a household with a role.
+type HouseholdMembers struct {
+	HouseholdID uuid.UUID `bson:"HouseholdID" json:"HouseholdID"`
+	UserID      uuid.UUID `bson:"UserID" json:"UserID"`
+	Role        string    `bson:"Role" json:"Role"`
+	JoinedAt    time.Time `bson:"JoinedAt" json:"JoinedAt"`
+}
+
+// HouseholdInvites is a pending invitation. Invites with an Email can only be
+// accepted by that user; others by anyone holding the code.
+type HouseholdInvites struct {
+	InviteID    uuid.UUID  `bson:"InviteID" json:"InviteID"`
+	HouseholdID uuid.UUID  `bson:"HouseholdID" json:"HouseholdID"`
+	Email       string     `bson:"Email,omitempty" json:"Email,omitempty"`
+	CodeHash    string     `bson:"CodeHash" json:"-"`
+	Role        string     `bson:"Role" json:"Role"`
+	CreatedBy   uuid.UUID  `bson:"CreatedBy" json:"CreatedBy"`
+	CreatedAt   time.Time  `bson:"CreatedAt" json:"CreatedAt"`
+	ExpiresAt   time.Time  `bson:"ExpiresAt" json:"ExpiresAt"`
+	AcceptedAt  *time.Time `bson:"AcceptedAt,omitempty" json:"AcceptedAt,omitempty"`
+	AcceptedBy  *uuid.UUID `bson:"AcceptedBy,omitempty" json:"AcceptedBy,omitempty"`
+}
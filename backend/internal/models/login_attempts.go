@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// LoginAttempts tracks consecutive failed logins for one account or client IP.
+type LoginAttempts struct {
+	Key           string     `bson:"Key" json:"Key"`
+	Failures      int        `bson:"Failures" json:"Failures"`
+	LastFailureAt time.Time  `bson:"LastFailureAt" json:"LastFailureAt"`
+	LockedUntil   *time.Time `bson:"LockedUntil,omitempty" json:"LockedUntil,omitempty"`
+	UpdatedAt     time.Time  `bson:"UpdatedAt" json:"UpdatedAt"`
+}
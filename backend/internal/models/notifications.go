@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification channel types.
+const (
+	NotificationChannelEmail   = "email"
+	NotificationChannelWebhook = "webhook"
+	NotificationChannelNtfy    = "ntfy"
+)
+
+// Notification kinds.
+const (
+	NotificationKindLowStock = "low_stock"
+	NotificationKindExpiring = "expiring"
+)
+
+// NotificationChannel is one place a user wants to be notified. Email goes to
+// the account's address; webhook and ntfy post to URL. Secret is the webhook
+// signing secret or the ntfy access token and is never returned by the API.
+type NotificationChannel struct {
+	Type   string `bson:"Type" json:"Type"`
+	URL    string `bson:"URL,omitempty" json:"URL,omitempty"`
+	Secret string `bson:"Secret,omitempty" json:"-"`
+}
+
+// QuietHours is a daily window, in TimeZone, during which notifications are
+// held back. Start and End are "HH:MM"; a window may wrap past midnight.
+type QuietHours struct {
+	Start    string `bson:"Start" json:"Start"`
+	End      string `bson:"End" json:"End"`
+	TimeZone string `bson:"TimeZone" json:"TimeZone"`
+}
+
+// NotificationSettings holds one user's notification preferences for one stock.
+type NotificationSettings struct {
+	StockID    uuid.UUID             `bson:"StockID" json:"StockID"`
+	UserID     uuid.UUID             `bson:"UserID" json:"UserID"`
+	Channels   []NotificationChannel `bson:"Channels" json:"Channels"`
+	QuietHours *QuietHours           `bson:"QuietHours,omitempty" json:"QuietHours,omitempty"`
+	UpdatedAt  time.Time             `bson:"UpdatedAt" json:"UpdatedAt"`
+}
+
+// NotificationOutbox is a notification waiting to be delivered on one channel.
+// Delivery is retried with backoff until it succeeds or runs out of attempts.
+type NotificationOutbox struct {
+	NotificationID uuid.UUID           `bson:"NotificationID" json:"NotificationID"`
+	UserID         uuid.UUID           `bson:"UserID" json:"UserID"`
+	StockID        uuid.UUID           `bson:"StockID" json:"StockID"`
+	Channel        NotificationChannel `bson:"Channel" json:"Channel"`
+	Kind           string              `bson:"Kind" json:"Kind"`
+	Title          string              `bson:"Title" json:"Title"`
+	Message        string              `bson:"Message" json:"Message"`
+	Link           string              `bson:"Link,omitempty" json:"Link,omitempty"`
+	Data           map[string]string   `bson:"Data,omitempty" json:"Data,omitempty"`
+	CreatedAt      time.Time           `bson:"CreatedAt" json:"CreatedAt"`
+	NotBefore      time.Time           `bson:"NotBefore" json:"NotBefore"`
+	LockedUntil    *time.Time          `bson:"LockedUntil,omitempty" json:"-"`
+	Attempts       int                 `bson:"Attempts" json:"Attempts"`
+	LastError      string              `bson:"LastError,omitempty" json:"LastError,omitempty"`
+	SentAt         *time.Time          `bson:"SentAt,omitempty" json:"SentAt,omitempty"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// OIDCStates holds the per-login secrets of an OpenID Connect authorization
+// request until the provider redirects back. The state itself is only stored
+// hashed.
+type OIDCStates struct {
+	StateHash    string    `bson:"StateHash" json:"-"`
+	Nonce        string    `bson:"Nonce" json:"-"`
+	CodeVerifier string    `bson:"CodeVerifier" json:"-"`
+	CreatedAt    time.Time `bson:"CreatedAt" json:"CreatedAt"`
+	ExpiresAt    time.Time `bson:"ExpiresAt" json:"ExpiresAt"`
+}
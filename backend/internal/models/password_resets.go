@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResets stores a hashed, single-use password reset token.
+type PasswordResets struct {
+	TokenHash string     `bson:"TokenHash" json:"-"`
+	UserID    uuid.UUID  `bson:"UserID" json:"UserID"`
+	CreatedAt time.Time  `bson:"CreatedAt" json:"CreatedAt"`
+	ExpiresAt time.Time  `bson:"ExpiresAt" json:"ExpiresAt"`
+	UsedAt    *time.Time `bson:"UsedAt,omitempty" json:"UsedAt,omitempty"`
+}
@@ -1,6 +1,10 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // Products represents a product in stock.
 type Products struct {
@@ -10,4 +14,66 @@ type Products struct {
 	Category    string    `bson:"Category,omitempty" json:"Category,omitempty"`
 	Unit        string    `bson:"Unit,omitempty" json:"Unit,omitempty"`
 	ProductQty  int       `bson:"ProductQty" json:"ProductQty"`
+	// Barcodes are the product's EAN/UPC codes as entered and SKU its own
+	// article number; both are unique within the stock. GTINs holds the
+	// barcodes padded to GTIN-14, so that a UPC-A code and its EAN-13
+	// spelling with a leading 0 are the same code to lookups and the index.
+	Barcodes []string `bson:"Barcodes,omitempty" json:"Barcodes,omitempty"`
+	GTINs    []string `bson:"GTINs,omitempty" json:"-"`
+	SKU      string   `bson:"SKU,omitempty" json:"SKU,omitempty"`
+	// MinQty is the reorder point: the product is low on stock once
+	// ProductQty is at or below it. ReorderQty is how much to buy then.
+	MinQty     *int `bson:"MinQty,omitempty" json:"MinQty,omitempty"`
+	ReorderQty *int `bson:"ReorderQty,omitempty" json:"ReorderQty,omitempty"`
+	// LowStockNotifiedAt is set when a low-stock notification went out and
+	// cleared once the quantity recovers, so each shortage is reported once.
+	LowStockNotifiedAt *time.Time `bson:"LowStockNotifiedAt,omitempty" json:"LowStockNotifiedAt,omitempty"`
+	// Lots split part or all of ProductQty into batches with their own expiry
+	// date; whatever ProductQty holds beyond them is untracked.
+	Lots []ProductLots `bson:"Lots,omitempty" json:"Lots,omitempty"`
+	// UpdatedAt is when the product last changed; products that have not
+	// changed since it was introduced have none.
+	UpdatedAt *time.Time `bson:"UpdatedAt,omitempty" json:"UpdatedAt,omitempty"`
+	// Revision is bumped on every quantity change so that lot updates can
+	// detect concurrent writes.
+	Revision int `bson:"Revision" json:"-"`
+}
+
+// ProductLots is a batch of a product received together.
+type ProductLots struct {
+	LotID      uuid.UUID  `bson:"LotID" json:"LotID"`
+	LotNumber  string     `bson:"LotNumber,omitempty" json:"LotNumber,omitempty"`
+	Quantity   int        `bson:"Quantity" json:"Quantity"`
+	ExpiresAt  *time.Time `bson:"ExpiresAt,omitempty" json:"ExpiresAt,omitempty"`
+	ReceivedAt time.Time  `bson:"ReceivedAt" json:"ReceivedAt"`
+	// ExpiryNotifiedAt is set once subscribers were told the lot is about
+	// to expire.
+	ExpiryNotifiedAt *time.Time `bson:"ExpiryNotifiedAt,omitempty" json:"ExpiryNotifiedAt,omitempty"`
+}
+
+// LotQty returns the quantity held in lots.
+func (p Products) LotQty() int {
+	total := 0
+	for _, lot := range p.Lots {
+		total += lot.Quantity
+	}
+	return total
+}
+
+// IsLowStock reports whether the product has a threshold and is at or below it.
+func (p Products) IsLowStock() bool {
+	return p.MinQty != nil && p.ProductQty <= *p.MinQty
+}
+
+// SuggestedReorderQty returns ReorderQty when set, otherwise the amount that
+// brings the quantity back above MinQty. It is 0 for products that are not
+// low on stock.
+func (p Products) SuggestedReorderQty() int {
+	if !p.IsLowStock() {
+		return 0
+	}
+	if p.ReorderQty != nil {
+		return *p.ReorderQty
+	}
+	return *p.MinQty - p.ProductQty + 1
 }
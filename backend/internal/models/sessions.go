@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sessions represents a signed-in device holding a rotating refresh token.
+type Sessions struct {
+	SessionID           uuid.UUID  `bson:"SessionID" json:"SessionID"`
+	UserID              uuid.UUID  `bson:"UserID" json:"UserID"`
+	RefreshTokenHash    string     `bson:"RefreshTokenHash" json:"-"`
+	PreviousTokenHashes []string   `bson:"PreviousTokenHashes,omitempty" json:"-"`
+	UserAgent           string     `bson:"UserAgent,omitempty" json:"UserAgent,omitempty"`
+	IPAddress           string     `bson:"IPAddress,omitempty" json:"IPAddress,omitempty"`
+	CreatedAt           time.Time  `bson:"CreatedAt" json:"CreatedAt"`
+	LastUsedAt          time.Time  `bson:"LastUsedAt" json:"LastUsedAt"`
+	ExpiresAt           time.Time  `bson:"ExpiresAt" json:"ExpiresAt"`
+	RevokedAt           *time.Time `bson:"RevokedAt,omitempty" json:"RevokedAt,omitempty"`
+	RevokeReason        string     `bson:"RevokeReason,omitempty" json:"RevokeReason,omitempty"`
+}
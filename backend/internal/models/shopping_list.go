@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Shopping list item sources.
+const (
+	ShoppingListSourceAuto   = "auto"
+	ShoppingListSourceManual = "manual"
+)
+
+// ShoppingListItems is one line of a user's shopping list. Auto items are
+// generated from low-stock products and removed again once the product has
+// recovered; manual items are added by the user and may name something that
+// is not a product yet. Items linked to a product are restocked when the
+// list is received.
+type ShoppingListItems struct {
+	ItemID    uuid.UUID  `bson:"ItemID" json:"ItemID"`
+	UserID    uuid.UUID  `bson:"UserID" json:"UserID"`
+	StockID   *uuid.UUID `bson:"StockID,omitempty" json:"StockID,omitempty"`
+	ProductID *uuid.UUID `bson:"ProductID,omitempty" json:"ProductID,omitempty"`
+	Name      string     `bson:"Name" json:"Name"`
+	Unit      string     `bson:"Unit,omitempty" json:"Unit,omitempty"`
+	Quantity  int        `bson:"Quantity" json:"Quantity"`
+	Source    string     `bson:"Source" json:"Source"`
+	Checked   bool       `bson:"Checked" json:"Checked"`
+	CheckedAt *time.Time `bson:"CheckedAt,omitempty" json:"CheckedAt,omitempty"`
+	// Dismissed hides an auto item until its product recovers.
+	Dismissed bool      `bson:"Dismissed,omitempty" json:"-"`
+	CreatedAt time.Time `bson:"CreatedAt" json:"CreatedAt"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Movement types. IN and OUT move a positive amount into or out of stock;
+// WASTE takes out stock that was thrown away, e.g. because it expired;
+// ADJUST corrects the quantity by a signed amount, e.g. after a stocktake.
+const (
+	MovementTypeIn     = "IN"
+	MovementTypeOut    = "OUT"
+	MovementTypeWaste  = "WASTE"
+	MovementTypeAdjust = "ADJUST"
+)
+
+// StockMovements is one entry in a product's quantity ledger. Delta is the
+// signed change applied to ProductQty and QtyAfter the quantity it left.
+type StockMovements struct {
+	MovementID uuid.UUID  `bson:"MovementID" json:"MovementID"`
+	ProductID  uuid.UUID  `bson:"ProductID" json:"ProductID"`
+	StockID    uuid.UUID  `bson:"StockID" json:"StockID"`
+	Type       string     `bson:"Type" json:"Type"`
+	Delta      int        `bson:"Delta" json:"Delta"`
+	QtyAfter   int        `bson:"QtyAfter" json:"QtyAfter"`
+	Reason     string     `bson:"Reason,omitempty" json:"Reason,omitempty"`
+	Note       string     `bson:"Note,omitempty" json:"Note,omitempty"`
+	ActorID    uuid.UUID  `bson:"ActorID" json:"ActorID"`
+	APIKeyID   *uuid.UUID `bson:"APIKeyID,omitempty" json:"APIKeyID,omitempty"`
+	// Lots lists how the movement changed the product's lots, if at all.
+	Lots      []MovementLots `bson:"Lots,omitempty" json:"Lots,omitempty"`
+	CreatedAt time.Time      `bson:"CreatedAt" json:"CreatedAt"`
+}
+
+// MovementLots is the signed change a movement made to one lot.
+type MovementLots struct {
+	LotID     uuid.UUID  `bson:"LotID" json:"LotID"`
+	LotNumber string     `bson:"LotNumber,omitempty" json:"LotNumber,omitempty"`
+	ExpiresAt *time.Time `bson:"ExpiresAt,omitempty" json:"ExpiresAt,omitempty"`
+	Delta     int        `bson:"Delta" json:"Delta"`
+}
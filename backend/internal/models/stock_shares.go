@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Roles a stock can be shared with.
+const (
+	StockShareRoleViewer = "viewer"
+	StockShareRoleEditor = "editor"
+)
+
+// StockShares grants a single user access to one stock, independent of
+// households.
+type StockShares struct {
+	StockID   uuid.UUID `bson:"StockID" json:"StockID"`
+	UserID    uuid.UUID `bson:"UserID" json:"UserID"`
+	Role      string    `bson:"Role" json:"Role"`
+	SharedBy  uuid.UUID `bson:"SharedBy" json:"SharedBy"`
+	CreatedAt time.Time `bson:"CreatedAt" json:"CreatedAt"`
+}
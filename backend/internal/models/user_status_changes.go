@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserStatusChanges records who changed a user's status, when and why.
+type UserStatusChanges struct {
+	ChangeID   uuid.UUID `bson:"ChangeID" json:"ChangeID"`
+	UserID     uuid.UUID `bson:"UserID" json:"UserID"`
+	ActorID    uuid.UUID `bson:"ActorID" json:"ActorID"`
+	FromStatus string    `bson:"FromStatus" json:"FromStatus"`
+	ToStatus   string    `bson:"ToStatus" json:"ToStatus"`
+	Reason     string    `bson:"Reason" json:"Reason"`
+	ChangedAt  time.Time `bson:"ChangedAt" json:"ChangedAt"`
+}
@@ -2,9 +2,11 @@ package models
 
 import "github.com/google/uuid"
 
-// Warehouse represents a user's stock list.
+// Warehouse represents a user's stock list. UserID is the owner; when
+// HouseholdID is set the household's members can access it as well.
 type Warehouse struct {
-	StockID   uuid.UUID `bson:"StockID" json:"StockID"`
-	UserID    uuid.UUID `bson:"UserID" json:"UserID"`
-	StockName string    `bson:"StockName" json:"StockName"`
+	StockID     uuid.UUID  `bson:"StockID" json:"StockID"`
+	UserID      uuid.UUID  `bson:"UserID" json:"UserID"`
+	StockName   string     `bson:"StockName" json:"StockName"`
+	HouseholdID *uuid.UUID `bson:"HouseholdID,omitempty" json:"HouseholdID,omitempty"`
 }
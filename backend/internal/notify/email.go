@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"context"
+	"errors"
+
+	mailer "my-backend/internal/mail"
+)
+
+// EmailChannel sends notifications as plain-text email.
+type EmailChannel struct {
+	Sender mailer.Sender
+	To     string
+}
+
+func (ch EmailChannel) Send(ctx context.Context, n Notification) error {
+	if ch.To == "" {
+		return errors.New("no email address to notify")
+	}
+	body := n.Message
+	if n.Link != "" {
+		body += "\n\n" + n.Link
+	}
+	return ch.Sender.Send(ctx, mailer.Message{To: ch.To, Subject: n.Title, Body: body})
+}
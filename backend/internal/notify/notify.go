@@ -0,0 +1,123 @@
+// Package notify delivers user notifications over pluggable channels.
+// Notifications are queued in an outbox and sent by a background job, so a
+// slow or failing channel never holds up the request that caused them.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	mailer "my-backend/internal/mail"
+	"my-backend/internal/models"
+)
+
+// Notification is the channel-independent content of a notification.
+type Notification struct {
+	Kind    string            `json:"kind"`
+	Title   string            `json:"title"`
+	Message string            `json:"message"`
+	Link    string            `json:"link,omitempty"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+// Channel delivers notifications to one destination.
+type Channel interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+// httpClient is shared by the HTTP based channels. It refuses to connect to
+// internal addresses, which also covers redirects and hosts whose DNS changed
+// after the channel was validated.
+var httpClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				return checkPublicIP(net.ParseIP(host))
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+// ValidateChannel checks a channel configuration before it is stored. The
+// host of a webhook or ntfy URL must resolve to public addresses only, so
+// channels cannot be used to reach services inside the network.
+func ValidateChannel(ctx context.Context, ch models.NotificationChannel) error {
+	if err := checkChannel(ch); err != nil {
+		return err
+	}
+	if ch.Type == models.NotificationChannelEmail {
+		return nil
+	}
+
+	u, _ := url.Parse(ch.URL)
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("URL host %s could not be resolved", u.Hostname())
+	}
+	for _, addr := range addrs {
+		if err := checkPublicIP(addr.IP); err != nil {
+			return fmt.Errorf("URL host %s: %v", u.Hostname(), err)
+		}
+	}
+	return nil
+}
+
+// checkChannel checks the form of a channel configuration.
+func checkChannel(ch models.NotificationChannel) error {
+	switch ch.Type {
+	case models.NotificationChannelEmail:
+		return nil
+	case models.NotificationChannelWebhook, models.NotificationChannelNtfy:
+		u, err := url.Parse(ch.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+			return errors.New("URL must be an absolute http or https URL")
+		}
+		return nil
+	default:
+		return errors.New("Type must be email, webhook or ntfy")
+	}
+}
+
+// checkPublicIP rejects loopback, private, link-local and other addresses
+// that do not belong to a public host.
+func checkPublicIP(ip net.IP) error {
+	switch {
+	case ip == nil:
+		return errors.New("invalid address")
+	case ip.IsLoopback(), ip.IsPrivate(), ip.IsLinkLocalUnicast(), ip.IsLinkLocalMulticast(),
+		ip.IsInterfaceLocalMulticast(), ip.IsMulticast(), ip.IsUnspecified():
+		return fmt.Errorf("%s is not a public address", ip)
+	}
+	return nil
+}
+
+// New returns the Channel for a stored configuration. email is the address of
+// the user the channel belongs to.
+func New(ch models.NotificationChannel, email string) (Channel, error) {
+	if err := checkChannel(ch); err != nil {
+		return nil, err
+	}
+	switch ch.Type {
+	case models.NotificationChannelEmail:
+		return EmailChannel{Sender: mailer.DefaultSender(), To: email}, nil
+	case models.NotificationChannelWebhook:
+		return WebhookChannel{URL: ch.URL, Secret: ch.Secret}, nil
+	default:
+		return NtfyChannel{URL: ch.URL, Token: ch.Secret}, nil
+	}
+}
@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// NtfyChannel publishes notifications to an ntfy topic URL such as
+// https://ntfy.sh/my-pantry. Token, when set, is sent as a bearer token for
+// protected topics.
+type NtfyChannel struct {
+	URL   string
+	Token string
+}
+
+func (ch NtfyChannel) Send(ctx context.Context, n Notification) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ch.URL, strings.NewReader(n.Message))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", headerValue(n.Title))
+	req.Header.Set("Tags", n.Kind)
+	if n.Link != "" {
+		req.Header.Set("Click", n.Link)
+	}
+	if ch.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+ch.Token)
+	}
+
+	return doRequest(req)
+}
+
+// headerValue makes text safe for an ntfy header: line breaks and other
+// control characters become spaces and non-ASCII text is encoded as an
+// RFC 2047 word.
+func headerValue(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, text)
+	return mime.QEncoding.Encode("utf-8", text)
+}
@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"my-backend/internal/db"
+	"my-backend/internal/models"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	maxDeliveryAttempts = 5
+	deliveryLease       = 2 * time.Minute
+	maxBatchSize        = 100
+)
+
+// Enqueue queues n on every channel of settings. Delivery is held back until
+// the end of the user's quiet hours.
+func Enqueue(ctx context.Context, settings models.NotificationSettings, n Notification) error {
+	if len(settings.Channels) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	notBefore := NextAllowed(settings.QuietHours, now)
+
+	docs := make([]interface{}, 0, len(settings.Channels))
+	for _, ch := range settings.Channels {
+		docs = append(docs, models.NotificationOutbox{
+			NotificationID: uuid.New(),
+			UserID:         settings.UserID,
+			StockID:        settings.StockID,
+			Channel:        ch,
+			Kind:           n.Kind,
+			Title:          n.Title,
+			Message:        n.Message,
+			Link:           n.Link,
+			Data:           n.Data,
+			CreatedAt:      now,
+			NotBefore:      notBefore,
+		})
+	}
+
+	collection, err := db.NotificationOutboxCollection(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = collection.InsertMany(ctx, docs)
+	return err
+}
+
+// DeliverPending sends queued notifications that are due. Each one is leased
+// before sending so that several backend instances never deliver it twice;
+// failures are retried with a growing delay.
+func DeliverPending(ctx context.Context) error {
+	outboxCol, err := db.NotificationOutboxCollection(ctx)
+	if err != nil {
+		return err
+	}
+	usersCol, err := db.UsersCollection(ctx)
+	if err != nil {
+		return err
+	}
+
+	sent, failed := 0, 0
+	for i := 0; i < maxBatchSize && ctx.Err() == nil; i++ {
+		now := time.Now().UTC()
+		var item models.NotificationOutbox
+		err := outboxCol.FindOneAndUpdate(ctx,
+			bson.M{
+				"SentAt":    nil,
+				"NotBefore": bson.M{"$lte": now},
+				"Attempts":  bson.M{"$lt": maxDeliveryAttempts},
+				"$or": bson.A{
+					bson.M{"LockedUntil": nil},
+					bson.M{"LockedUntil": bson.M{"$lt": now}},
+				},
+			},
+			bson.M{"$set": bson.M{"LockedUntil": now.Add(deliveryLease)}},
+			options.FindOneAndUpdate().SetSort(bson.D{{Key: "NotBefore", Value: 1}}).SetReturnDocument(options.After),
+		).Decode(&item)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := deliver(ctx, usersCol, item); err != nil {
+			failed++
+			attempts := item.Attempts + 1
+			if _, updateErr := outboxCol.UpdateOne(ctx,
+				bson.M{"NotificationID": item.NotificationID},
+				bson.M{
+					"$set": bson.M{
+						"Attempts":  attempts,
+						"LastError": err.Error(),
+						"NotBefore": now.Add(time.Duration(attempts*attempts) * time.Minute),
+					},
+					"$unset": bson.M{"LockedUntil": ""},
+				},
+			); updateErr != nil {
+				return updateErr
+			}
+			continue
+		}
+
+		sent++
+		if _, err := outboxCol.UpdateOne(ctx,
+			bson.M{"NotificationID": item.NotificationID},
+			bson.M{
+				"$set":   bson.M{"SentAt": time.Now().UTC(), "Attempts": item.Attempts + 1},
+				"$unset": bson.M{"LockedUntil": "", "LastError": ""},
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	if sent > 0 || failed > 0 {
+		log.Printf("notifications: %d sent, %d failed", sent, failed)
+	}
+	return nil
+}
+
+func deliver(ctx context.Context, usersCol *mongo.Collection, item models.NotificationOutbox) error {
+	var user models.Users
+	if err := usersCol.FindOne(ctx, bson.M{"UserId": item.UserID.String()}).Decode(&user); err != nil {
+		return fmt.Errorf("load recipient: %w", err)
+	}
+	if !user.IsActive() {
+		return errors.New("recipient account is not active")
+	}
+
+	ch, err := New(item.Channel, user.Email)
+	if err != nil {
+		return err
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	return ch.Send(sendCtx, Notification{
+		Kind:    item.Kind,
+		Title:   item.Title,
+		Message: item.Message,
+		Link:    item.Link,
+		Data:    item.Data,
+	})
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	_ "time/tzdata" // quiet hours must work in containers without zoneinfo
+
+	"my-backend/internal/models"
+)
+
+// ValidateQuietHours checks the format of a quiet hours window.
+func ValidateQuietHours(q models.QuietHours) error {
+	_, _, _, err := parseQuietHours(q)
+	return err
+}
+
+// NextAllowed returns the earliest time from now at which a notification may
+// be delivered: now itself, or the end of the quiet window now falls into.
+func NextAllowed(q *models.QuietHours, now time.Time) time.Time {
+	if q == nil {
+		return now
+	}
+	start, end, loc, err := parseQuietHours(*q)
+	if err != nil {
+		return now
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	endOn := func(days int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+days, end/60, end%60, 0, 0, loc).UTC()
+	}
+
+	switch {
+	case start < end && minute >= start && minute < end:
+		return endOn(0)
+	case start > end && minute >= start:
+		return endOn(1)
+	case start > end && minute < end:
+		return endOn(0)
+	}
+	return now
+}
+
+// parseQuietHours returns the window as minutes after midnight.
+func parseQuietHours(q models.QuietHours) (int, int, *time.Location, error) {
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("Start: %w", err)
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("End: %w", err)
+	}
+	if start == end {
+		return 0, 0, nil, errors.New("Start and End must differ")
+	}
+	loc := time.UTC
+	if q.TimeZone != "" {
+		if loc, err = time.LoadLocation(q.TimeZone); err != nil {
+			return 0, 0, nil, errors.New("TimeZone is not a known IANA time zone")
+		}
+	}
+	return start, end, loc, nil
+}
+
+func parseClock(raw string) (int, error) {
+	t, err := time.Parse("15:04", raw)
+	if err != nil {
+		return 0, errors.New("must be a time as HH:MM")
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
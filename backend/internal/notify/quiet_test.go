@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"my-backend/internal/models"
+)
+
+func TestNextAllowed(t *testing.T) {
+	utc := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2026, month, day, hour, min, 0, 0, time.UTC)
+	}
+	sameDay := &models.QuietHours{Start: "12:00", End: "14:00"}
+	overnight := &models.QuietHours{Start: "22:00", End: "07:00"}
+	newYork := &models.QuietHours{Start: "22:00", End: "06:00", TimeZone: "America/New_York"}
+	berlin := &models.QuietHours{Start: "22:00", End: "07:00", TimeZone: "Europe/Berlin"}
+
+	tests := []struct {
+		name  string
+		quiet *models.QuietHours
+		now   time.Time
+		want  time.Time
+	}{
+		{"no quiet hours", nil, utc(5, 1, 3, 0), utc(5, 1, 3, 0)},
+		{"invalid window", &models.QuietHours{Start: "25:00", End: "07:00"}, utc(5, 1, 3, 0), utc(5, 1, 3, 0)},
+
+		{"same day: before", sameDay, utc(5, 1, 11, 59), utc(5, 1, 11, 59)},
+		{"same day: at the start", sameDay, utc(5, 1, 12, 0), utc(5, 1, 14, 0)},
+		{"same day: inside", sameDay, utc(5, 1, 13, 30), utc(5, 1, 14, 0)},
+		{"same day: at the end", sameDay, utc(5, 1, 14, 0), utc(5, 1, 14, 0)},
+
+		{"overnight: before", overnight, utc(5, 1, 21, 59), utc(5, 1, 21, 59)},
+		{"overnight: before midnight", overnight, utc(5, 1, 23, 30), utc(5, 2, 7, 0)},
+		{"overnight: after midnight", overnight, utc(5, 2, 3, 0), utc(5, 2, 7, 0)},
+		{"overnight: at the end", overnight, utc(5, 2, 7, 0), utc(5, 2, 7, 0)},
+		{"overnight: across a month", overnight, utc(1, 31, 22, 0), utc(2, 1, 7, 0)},
+
+		// New York is UTC-4 in summer.
+		{"time zone: before in local time", newYork, utc(7, 1, 1, 0), utc(7, 1, 1, 0)},
+		{"time zone: before local midnight", newYork, utc(7, 1, 3, 0), utc(7, 1, 10, 0)},
+		{"time zone: after local midnight", newYork, utc(7, 1, 8, 0), utc(7, 1, 10, 0)},
+		{"time zone: after the end", newYork, utc(7, 1, 12, 0), utc(7, 1, 12, 0)},
+
+		// Berlin moves from UTC+1 to UTC+2 on 29 March 2026 and back on
+		// 25 October; the window still ends at 07:00 local time.
+		{"DST starts overnight", berlin, utc(3, 28, 22, 0), utc(3, 29, 5, 0)},
+		{"DST ends overnight", berlin, utc(10, 24, 21, 0), utc(10, 25, 6, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NextAllowed(tt.quiet, tt.now); !got.Equal(tt.want) {
+				t.Errorf("NextAllowed(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateQuietHours(t *testing.T) {
+	tests := []struct {
+		quiet models.QuietHours
+		ok    bool
+	}{
+		{models.QuietHours{Start: "22:00", End: "07:00"}, true},
+		{models.QuietHours{Start: "22:00", End: "07:00", TimeZone: "Europe/Berlin"}, true},
+		{models.QuietHours{Start: "22:00", End: "22:00"}, false},
+		{models.QuietHours{Start: "10pm", End: "07:00"}, false},
+		{models.QuietHours{Start: "22:00", End: "7"}, false},
+		{models.QuietHours{Start: "22:00", End: "07:00", TimeZone: "Mars/Olympus"}, false},
+	}
+	for _, tt := range tests {
+		if err := ValidateQuietHours(tt.quiet); (err == nil) != tt.ok {
+			t.Errorf("ValidateQuietHours(%+v) = %v, want ok %v", tt.quiet, err, tt.ok)
+		}
+	}
+}
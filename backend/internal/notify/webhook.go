@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// WebhookChannel posts notifications as JSON to a URL. With a Secret the body
+// is signed with HMAC-SHA256 and the signature sent in X-Signature-256 as
+// "sha256=<hex>", so receivers can check where the request came from.
+type WebhookChannel struct {
+	URL    string
+	Secret string
+}
+
+func (ch WebhookChannel) Send(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ch.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "personal-warehouse-notify")
+	if ch.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(ch.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	return doRequest(req)
+}
+
+// doRequest sends req and treats any non-2xx answer as a failure.
+func doRequest(req *http.Request) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s answered %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
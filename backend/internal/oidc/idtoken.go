@@ -0,0 +1,251 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// ErrInvalidIDToken is returned when an ID token fails verification.
+var ErrInvalidIDToken = errors.New("oidc: invalid id token")
+
+// Tolerated clock difference between this server and the provider.
+const clockSkew = time.Minute
+
+// keysRefreshInterval limits how often an unknown key ID triggers a JWKS
+// refetch, so forged tokens cannot be used to hammer the provider.
+const keysRefreshInterval = time.Minute
+
+// IDTokenClaims are the verified claims used to sign a user in.
+type IDTokenClaims struct {
+	Issuer        string `json:"iss"`
+	Subject       string `json:"sub"`
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"-"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+}
+
+type rawClaims struct {
+	IDTokenClaims
+	Audience      audience        `json:"aud"`
+	AuthorizedBy  string          `json:"azp"`
+	ExpiresAt     int64           `json:"exp"`
+	IssuedAt      int64           `json:"iat"`
+	EmailVerified json.RawMessage `json:"email_verified"`
+}
+
+// audience accepts both the single-string and array forms of "aud".
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+func (a audience) contains(v string) bool {
+	for _, aud := range a {
+		if aud == v {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyIDToken checks the signature of an ID token against the provider's
+// JWKS and validates issuer, audience, expiry and nonce. RS256 and ES256
+// signatures are supported.
+func (p *Provider) VerifyIDToken(ctx context.Context, token, nonce string) (IDTokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return IDTokenClaims{}, ErrInvalidIDToken
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return IDTokenClaims{}, ErrInvalidIDToken
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return IDTokenClaims{}, ErrInvalidIDToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return IDTokenClaims{}, ErrInvalidIDToken
+	}
+
+	key, err := p.signingKey(ctx, header.Kid)
+	if err != nil {
+		return IDTokenClaims{}, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !verifySignature(header.Alg, key, digest[:], sig) {
+		return IDTokenClaims{}, ErrInvalidIDToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return IDTokenClaims{}, ErrInvalidIDToken
+	}
+	var claims rawClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return IDTokenClaims{}, ErrInvalidIDToken
+	}
+
+	now := time.Now()
+	switch {
+	case strings.TrimRight(claims.Issuer, "/") != p.config.Issuer:
+		return IDTokenClaims{}, fmt.Errorf("%w: unexpected issuer", ErrInvalidIDToken)
+	case !claims.Audience.contains(p.config.ClientID):
+		return IDTokenClaims{}, fmt.Errorf("%w: unexpected audience", ErrInvalidIDToken)
+	case len(claims.Audience) > 1 && claims.AuthorizedBy != "" && claims.AuthorizedBy != p.config.ClientID:
+		return IDTokenClaims{}, fmt.Errorf("%w: unexpected authorized party", ErrInvalidIDToken)
+	case claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(clockSkew)):
+		return IDTokenClaims{}, fmt.Errorf("%w: token expired", ErrInvalidIDToken)
+	case claims.IssuedAt != 0 && time.Unix(claims.IssuedAt, 0).After(now.Add(clockSkew)):
+		return IDTokenClaims{}, fmt.Errorf("%w: token issued in the future", ErrInvalidIDToken)
+	case claims.Nonce != nonce:
+		return IDTokenClaims{}, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	case claims.Subject == "":
+		return IDTokenClaims{}, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	// Some providers send email_verified as the string "true".
+	verified := claims.IDTokenClaims
+	verified.EmailVerified = strings.Trim(string(claims.EmailVerified), `"`) == "true"
+	return verified, nil
+}
+
+func verifySignature(alg string, key interface{}, digest, sig []byte) bool {
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, sig) == nil
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(pub, digest, r, s)
+	default:
+		return false
+	}
+}
+
+// signingKey returns the provider key with the given ID, refetching the JWKS
+// when the provider has rotated its keys.
+func (p *Provider) signingKey(ctx context.Context, kid string) (interface{}, error) {
+	p.keysMu.Lock()
+	defer p.keysMu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if !p.keysFetched.IsZero() && time.Since(p.keysFetched) < keysRefreshInterval {
+		return nil, fmt.Errorf("%w: unknown signing key", ErrInvalidIDToken)
+	}
+
+	keys, err := p.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+	p.keysFetched = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown signing key", ErrInvalidIDToken)
+}
+
+// lookupKey finds a key by ID. Tokens without a kid are accepted when the
+// provider publishes exactly one key.
+func (p *Provider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *Provider) fetchKeys(ctx context.Context) (map[string]interface{}, error) {
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, p.httpClient, p.jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("oidc: fetching JWKS failed: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		switch jwk.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+			if errN != nil || errE != nil || len(e) > 4 {
+				continue
+			}
+			keys[jwk.Kid] = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		case "EC":
+			if jwk.Crv != "P-256" {
+				continue
+			}
+			x, errX := base64.RawURLEncoding.DecodeString(jwk.X)
+			y, errY := base64.RawURLEncoding.DecodeString(jwk.Y)
+			if errX != nil || errY != nil || len(x) != 32 || len(y) != 32 {
+				continue
+			}
+			// ecdh rejects points that are not on the curve.
+			point := append(append([]byte{4}, x...), y...)
+			if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+				continue
+			}
+			keys[jwk.Kid] = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,221 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"my-backend/internal/oidc"
+	"my-backend/internal/oidc/oidctest"
+)
+
+const (
+	testClientID    = "warehouse"
+	testRedirectURL = "http://localhost:8080/api/oidc/callback"
+)
+
+func discover(t *testing.T, mock *oidctest.Provider) *oidc.Provider {
+	t.Helper()
+	provider, err := oidc.Discover(context.Background(), oidc.Config{
+		Issuer:      mock.Issuer(),
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+		Scopes:      []string{"openid", "email"},
+	}, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	return provider
+}
+
+func TestDiscoverRejectsIssuerMismatch(t *testing.T) {
+	mock := oidctest.NewProvider(testClientID)
+	defer mock.Close()
+
+	_, err := oidc.Discover(context.Background(), oidc.Config{
+		Issuer:   mock.Issuer() + "/other",
+		ClientID: testClientID,
+	}, http.DefaultClient)
+	if err == nil {
+		t.Fatal("Discover accepted a document for a different issuer")
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	mock := oidctest.NewProvider(testClientID)
+	defer mock.Close()
+	provider := discover(t, mock)
+
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verifier == challenge {
+		t.Fatal("PKCE challenge equals the verifier")
+	}
+
+	u, err := url.Parse(provider.AuthCodeURL("the-state", "the-nonce", challenge))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	for name, want := range map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid email",
+		"state":                 "the-state",
+		"nonce":                 "the-nonce",
+		"code_challenge":        challenge,
+		"code_challenge_method": "S256",
+	} {
+		if got := q.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestExchangeEnforcesPKCE(t *testing.T) {
+	mock := oidctest.NewProvider(testClientID)
+	defer mock.Close()
+	provider := discover(t, mock)
+	ctx := context.Background()
+
+	authorize := func(t *testing.T, verifierChallenge string) string {
+		t.Helper()
+		code, state, err := mock.Authorize(provider.AuthCodeURL("state", "nonce", verifierChallenge), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state != "state" {
+			t.Fatalf("state = %q, want it returned unchanged", state)
+		}
+		return code
+	}
+
+	t.Run("matching verifier", func(t *testing.T) {
+		verifier, challenge, _ := oidc.NewPKCE()
+		code := authorize(t, challenge)
+
+		idToken, err := provider.Exchange(ctx, code, verifier)
+		if err != nil {
+			t.Fatalf("Exchange: %v", err)
+		}
+		claims, err := provider.VerifyIDToken(ctx, idToken, "nonce")
+		if err != nil {
+			t.Fatalf("VerifyIDToken: %v", err)
+		}
+		if claims.Subject != "subject-1" {
+			t.Errorf("Subject = %q", claims.Subject)
+		}
+
+		if _, err := provider.Exchange(ctx, code, verifier); err == nil {
+			t.Error("a code was redeemed twice")
+		}
+	})
+
+	t.Run("wrong verifier", func(t *testing.T) {
+		_, challenge, _ := oidc.NewPKCE()
+		otherVerifier, _, _ := oidc.NewPKCE()
+		code := authorize(t, challenge)
+
+		if _, err := provider.Exchange(ctx, code, otherVerifier); err == nil {
+			t.Error("Exchange succeeded with a verifier that does not match the challenge")
+		}
+	})
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	mock := oidctest.NewProvider(testClientID)
+	defer mock.Close()
+	provider := discover(t, mock)
+	ctx := context.Background()
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := map[string]interface{}{"nonce": "n-1", "email": "Ann@Example.com", "email_verified": true}
+	with := func(changes map[string]interface{}) map[string]interface{} {
+		claims := map[string]interface{}{}
+		for k, v := range valid {
+			claims[k] = v
+		}
+		for k, v := range changes {
+			claims[k] = v
+		}
+		return claims
+	}
+	tamper := func(token string) string {
+		parts := strings.Split(token, ".")
+		other := strings.Split(mock.SignIDToken(with(map[string]interface{}{"sub": "someone-else"})), ".")
+		return parts[0] + "." + other[1] + "." + parts[2]
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"valid", mock.SignIDToken(valid), true},
+		{"audience list", mock.SignIDToken(with(map[string]interface{}{"aud": []string{"other", testClientID}, "azp": testClientID})), true},
+		{"wrong nonce", mock.SignIDToken(with(map[string]interface{}{"nonce": "n-2"})), false},
+		{"missing nonce", mock.SignIDToken(with(map[string]interface{}{"nonce": nil})), false},
+		{"wrong issuer", mock.SignIDToken(with(map[string]interface{}{"iss": "https://evil.example"})), false},
+		{"wrong audience", mock.SignIDToken(with(map[string]interface{}{"aud": "other-client"})), false},
+		{"foreign azp", mock.SignIDToken(with(map[string]interface{}{"aud": []string{"other", testClientID}, "azp": "other"})), false},
+		{"expired", mock.SignIDToken(with(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})), false},
+		{"missing exp", mock.SignIDToken(with(map[string]interface{}{"exp": nil})), false},
+		{"issued in the future", mock.SignIDToken(with(map[string]interface{}{"iat": time.Now().Add(time.Hour).Unix()})), false},
+		{"missing subject", mock.SignIDToken(with(map[string]interface{}{"sub": nil})), false},
+		{"tampered payload", tamper(mock.SignIDToken(valid)), false},
+		{"foreign key", oidctest.SignToken(otherKey, oidctest.KeyID, with(map[string]interface{}{
+			"iss": mock.Issuer(), "aud": testClientID, "sub": "subject-1", "exp": time.Now().Add(time.Minute).Unix(),
+		})), false},
+		{"unknown key ID", oidctest.SignToken(mock.Key, "rotated-away", with(map[string]interface{}{
+			"iss": mock.Issuer(), "aud": testClientID, "sub": "subject-1", "exp": time.Now().Add(time.Minute).Unix(),
+		})), false},
+		{"unsigned", "eyJhbGciOiJub25lIn0." + strings.Split(mock.SignIDToken(valid), ".")[1] + ".", false},
+		{"not a JWT", "garbage", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := provider.VerifyIDToken(ctx, tt.token, "n-1")
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("VerifyIDToken: %v", err)
+				}
+				if claims.Email != "Ann@Example.com" || !claims.EmailVerified {
+					t.Errorf("claims = %+v", claims)
+				}
+				return
+			}
+			if !errors.Is(err, oidc.ErrInvalidIDToken) {
+				t.Fatalf("VerifyIDToken error = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenEmailVerifiedString(t *testing.T) {
+	mock := oidctest.NewProvider(testClientID)
+	defer mock.Close()
+	provider := discover(t, mock)
+
+	for value, want := range map[interface{}]bool{"true": true, "false": false, true: true, false: false} {
+		token := mock.SignIDToken(map[string]interface{}{"nonce": "n", "email_verified": value})
+		claims, err := provider.VerifyIDToken(context.Background(), token, "n")
+		if err != nil {
+			t.Fatalf("email_verified %#v: %v", value, err)
+		}
+		if claims.EmailVerified != want {
+			t.Errorf("email_verified %#v: EmailVerified = %v, want %v", value, claims.EmailVerified, want)
+		}
+	}
+}
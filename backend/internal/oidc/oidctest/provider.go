@@ -0,0 +1,215 @@
+// Package oidctest runs a minimal OpenID provider for tests: discovery, JWKS
+// and a token endpoint that enforces single-use codes and PKCE, in the
+// spirit of net/http/httptest.
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// KeyID is the kid of the key the provider signs with.
+const KeyID = "test-key"
+
+// Provider is a running mock provider. Close it when done.
+type Provider struct {
+	ClientID string
+	Key      *rsa.PrivateKey
+
+	server *httptest.Server
+
+	mu    sync.Mutex
+	codes map[string]grant
+}
+
+// grant is what an issued code stands for until it is redeemed.
+type grant struct {
+	redirectURI string
+	challenge   string
+	claims      map[string]interface{}
+}
+
+// NewProvider starts a provider that accepts clientID.
+func NewProvider(clientID string) *Provider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: generating key: %v", err))
+	}
+	p := &Provider{ClientID: clientID, Key: key, codes: map[string]grant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/jwks", p.jwks)
+	mux.HandleFunc("/token", p.token)
+	p.server = httptest.NewServer(mux)
+	return p
+}
+
+// Issuer returns the provider's issuer URL.
+func (p *Provider) Issuer() string {
+	return p.server.URL
+}
+
+// Close shuts the provider down.
+func (p *Provider) Close() {
+	p.server.Close()
+}
+
+// Authorize plays the user approving the login at authURL, the URL a relying
+// party redirects the browser to. It checks the request like a provider would
+// and returns the code and state to call back with. The ID token issued for
+// the code carries the request's nonce and the defaults of SignIDToken, with
+// claims applied on top.
+func (p *Provider) Authorize(authURL string, claims map[string]interface{}) (code, state string, err error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	q := u.Query()
+	switch {
+	case q.Get("response_type") != "code":
+		return "", "", errors.New("oidctest: response_type must be code")
+	case q.Get("client_id") != p.ClientID:
+		return "", "", errors.New("oidctest: unknown client_id")
+	case q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "":
+		return "", "", errors.New("oidctest: PKCE with S256 is required")
+	case q.Get("state") == "" || q.Get("nonce") == "":
+		return "", "", errors.New("oidctest: state and nonce are required")
+	}
+
+	all := map[string]interface{}{"nonce": q.Get("nonce")}
+	for k, v := range claims {
+		all[k] = v
+	}
+
+	code = randomString()
+	p.mu.Lock()
+	p.codes[code] = grant{redirectURI: q.Get("redirect_uri"), challenge: q.Get("code_challenge"), claims: all}
+	p.mu.Unlock()
+	return code, q.Get("state"), nil
+}
+
+// SignIDToken returns an RS256 ID token for claims. iss, aud, sub, exp and iat
+// default to values a relying party for ClientID accepts; a claim set to nil
+// is left out.
+func (p *Provider) SignIDToken(claims map[string]interface{}) string {
+	now := time.Now()
+	all := map[string]interface{}{
+		"iss": p.Issuer(),
+		"aud": p.ClientID,
+		"sub": "subject-1",
+		"exp": now.Add(5 * time.Minute).Unix(),
+		"iat": now.Unix(),
+	}
+	for k, v := range claims {
+		if v == nil {
+			delete(all, k)
+			continue
+		}
+		all[k] = v
+	}
+	return SignToken(p.Key, KeyID, all)
+}
+
+// SignToken signs claims with key as an RS256 JWT with the given kid.
+func SignToken(key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: encoding claims: %v", err))
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: signing: %v", err))
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 p.Issuer(),
+		"authorization_endpoint": p.Issuer() + "/authorize",
+		"token_endpoint":         p.Issuer() + "/token",
+		"jwks_uri":               p.Issuer() + "/jwks",
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := p.Key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": KeyID,
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	if clientID := r.PostForm.Get("client_id"); clientID != p.ClientID {
+		if user, _, ok := r.BasicAuth(); !ok || user != p.ClientID {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+			return
+		}
+	}
+
+	// Codes are single-use: a redeemed code is gone even when the rest of
+	// the request is rejected.
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	g, ok := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case !ok:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "unknown code"})
+	case r.PostForm.Get("redirect_uri") != g.redirectURI:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "redirect_uri mismatch"})
+	case base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+	default:
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token": randomString(),
+			"token_type":   "Bearer",
+			"expires_in":   300,
+			"id_token":     p.SignIDToken(g.claims),
+		})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -2,6 +2,8 @@ package routes
 
 import (
 	"my-backend/internal/handlers"
+	"my-backend/internal/middleware"
+	"my-backend/internal/models"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -12,17 +14,100 @@ func RegisterRoutes(app *fiber.App) {
 	//Auth
 	app.Post("/api/register", handlers.RegisterUser)
 	app.Post("/api/login", handlers.LoginUser)
+	app.Post("/api/login/2fa", handlers.LoginTwoFactor)
+	app.Post("/api/token/refresh", handlers.RefreshToken)
+	app.Post("/api/password/forgot", handlers.ForgotPassword)
+	app.Post("/api/password/reset", handlers.ResetPassword)
+	app.Get("/api/verify-email", handlers.VerifyEmail)
+	app.Post("/api/verify-email/resend", handlers.ResendVerification)
+	app.Get("/api/oidc/login", handlers.OIDCLogin)
+	app.Get("/api/oidc/callback", handlers.OIDCCallback)
 
-	app.Get("/api/products", handlers.ListProducts)
-	app.Delete("/api/products/:productId", handlers.DeleteProduct)
-	app.Put("/api/products/:productId", handlers.UpdateProduct)
-	app.Post("/api/products", handlers.CreateProduct)
+	// Everything registered on api requires an access token or API key. Public
+	// routes must be registered above this group so they match before the middleware.
+	api := app.Group("/api", middleware.RequireAuth)
 
-	app.Get("/api/categories", handlers.ListCategories)
-	app.Post("/api/categories", handlers.CreateCategories)
+	// Account management needs an interactive login; API keys are rejected.
+	session := middleware.RequireSession
 
-	app.Get("/api/warehouse", handlers.ListWarehouse)
-	app.Post("/api/warehouse", handlers.CreateStock)
-	app.Delete("/api/warehouse/:stockId", handlers.DeleteStock)
-	app.Delete("/api/categories/:categoryId", handlers.DeleteCategory)
+	api.Get("/me", session, handlers.GetProfile)
+	api.Patch("/me", session, handlers.UpdateProfile)
+	api.Put("/me/password", session, handlers.ChangePassword)
+	api.Post("/me/email", session, handlers.ChangeEmail)
+	api.Get("/me/export", session, handlers.ExportAccount)
+	api.Delete("/me", session, handlers.DeleteAccount)
+
+	api.Post("/logout", session, handlers.Logout)
+	api.Post("/logout-all", session, handlers.LogoutAll)
+	api.Get("/sessions", session, handlers.ListSessions)
+	api.Delete("/sessions/:sessionId", session, handlers.RevokeSession)
+
+	api.Post("/2fa/enroll", session, handlers.EnrollTwoFactor)
+	api.Post("/2fa/confirm", session, handlers.ConfirmTwoFactor)
+	api.Post("/2fa/disable", session, handlers.DisableTwoFactor)
+
+	api.Get("/api-keys", session, handlers.ListAPIKeys)
+	api.Post("/api-keys", session, handlers.CreateAPIKey)
+	api.Delete("/api-keys/:keyId", session, handlers.RevokeAPIKey)
+
+	api.Get("/households", session, handlers.ListHouseholds)
+	api.Post("/households", session, handlers.CreateHousehold)
+	api.Post("/households/join", session, handlers.JoinHousehold)
+	api.Get("/households/:householdId", session, handlers.GetHousehold)
+	api.Delete("/households/:householdId", session, handlers.DeleteHousehold)
+	api.Get("/households/:householdId/invites", session, handlers.ListHouseholdInvites)
+	api.Post("/households/:householdId/invites", session, handlers.CreateHouseholdInvite)
+	api.Delete("/households/:householdId/invites/:inviteId", session, handlers.RevokeHouseholdInvite)
+	api.Put("/households/:householdId/members/:userId", session, handlers.UpdateHouseholdMember)
+	api.Delete("/households/:householdId/members/:userId", session, handlers.RemoveHouseholdMember)
+
+	productsRead := middleware.RequireScope(models.ScopeProductsRead)
+	productsWrite := middleware.RequireScope(models.ScopeProductsWrite)
+	categoriesRead := middleware.RequireScope(models.ScopeCategoriesRead)
+	categoriesWrite := middleware.RequireScope(models.ScopeCategoriesWrite)
+	warehouseRead := middleware.RequireScope(models.ScopeWarehouseRead)
+	warehouseWrite := middleware.RequireScope(models.ScopeWarehouseWrite)
+
+	api.Get("/products", productsRead, handlers.ListProducts)
+	api.Get("/products/lookup", productsRead, handlers.LookupProduct)
+	api.Get("/catalog/lookup", productsRead, handlers.LookupCatalog)
+	api.Delete("/products/:productId", productsWrite, handlers.DeleteProduct)
+	api.Put("/products/:productId", productsWrite, handlers.UpdateProduct)
+	api.Post("/products", productsWrite, handlers.CreateProduct)
+	api.Get("/products/:productId/movements", productsRead, handlers.ListMovements)
+	api.Post("/products/:productId/movements", productsWrite, handlers.CreateMovement)
+
+	api.Get("/categories", categoriesRead, handlers.ListCategories)
+	api.Post("/categories", categoriesWrite, handlers.CreateCategories)
+
+	api.Get("/warehouse", warehouseRead, handlers.ListWarehouse)
+	api.Post("/warehouse", warehouseWrite, handlers.CreateStock)
+	api.Delete("/warehouse/:stockId", warehouseWrite, handlers.DeleteStock)
+	api.Get("/warehouse/:stockId/low-stock", productsRead, handlers.ListLowStock)
+	api.Get("/warehouse/:stockId/expiring", productsRead, handlers.ListExpiring)
+	api.Post("/warehouse/:stockId/waste-expired", productsWrite, handlers.WasteExpiredLots)
+	api.Get("/warehouse/:stockId/waste-report", productsRead, handlers.GetWasteReport)
+	api.Put("/warehouse/:stockId/household", session, handlers.AssignStockHousehold)
+	api.Get("/warehouse/:stockId/notifications", session, handlers.GetNotificationSettings)
+	api.Put("/warehouse/:stockId/notifications", session, handlers.UpdateNotificationSettings)
+	api.Get("/warehouse/:stockId/shares", session, handlers.ListStockShares)
+	api.Post("/warehouse/:stockId/shares", session, handlers.ShareStock)
+	api.Delete("/warehouse/:stockId/shares/:userId", session, handlers.RevokeStockShare)
+	api.Post("/warehouse/:stockId/transfer", session, handlers.TransferStock)
+	api.Delete("/categories/:categoryId", categoriesWrite, handlers.DeleteCategory)
+
+	api.Get("/shopping-list", productsRead, handlers.GetShoppingList)
+	api.Post("/shopping-list/items", productsWrite, handlers.AddShoppingListItem)
+	api.Patch("/shopping-list/items/:itemId", productsWrite, handlers.UpdateShoppingListItem)
+	api.Delete("/shopping-list/items/:itemId", productsWrite, handlers.DeleteShoppingListItem)
+	api.Post("/shopping-list/receive", productsWrite, handlers.ReceiveShoppingList)
+
+	api.Get("/audit", warehouseRead, handlers.ListAudit)
+
+	admin := api.Group("/admin", session, middleware.RequireRole(models.RoleAdmin))
+	admin.Get("/users", handlers.AdminListUsers)
+	admin.Put("/users/:userId/status", handlers.AdminUpdateUserStatus)
+	admin.Get("/users/:userId/status-history", handlers.AdminUserStatusHistory)
+	admin.Put("/users/:userId/role", handlers.AdminUpdateUserRole)
+	admin.Get("/stats", handlers.AdminSystemStats)
 }
@@ -0,0 +1,80 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed from RFC 6238 Appendix B.
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+// TestCodeAtRFC6238 checks the SHA1 test vectors of RFC 6238 Appendix B. The
+// RFC lists 8 digit codes; ours are their last 6 digits.
+func TestCodeAtRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := CodeAt(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("CodeAt at %d: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("CodeAt at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeAtInvalidSecret(t *testing.T) {
+	if _, err := CodeAt("not base32!", 1); err == nil {
+		t.Error("CodeAt accepted an invalid secret")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+	codeAt := func(step int64) string {
+		code, err := CodeAt(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", codeAt(current), 0, current, true},
+		{"one step behind", codeAt(current - 1), 0, current - 1, true},
+		{"one step ahead", codeAt(current + 1), 0, current + 1, true},
+		{"with spaces", codeAt(current)[:3] + " " + codeAt(current)[3:], 0, current, true},
+		{"two steps behind", codeAt(current - 2), 0, 0, false},
+		{"two steps ahead", codeAt(current + 2), 0, 0, false},
+		{"reused step", codeAt(current), current, 0, false},
+		{"step before the last one", codeAt(current - 1), current, 0, false},
+		{"newer than the last step", codeAt(current + 1), current, current + 1, true},
+		{"wrong length", "12345", 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, 1, tt.lastStep)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate(%q) = %d, %v, want %d, %v", tt.code, step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"log"
 
 	docs "my-backend/docs"
+	"my-backend/internal/config"
+	"my-backend/internal/jobs"
 	"my-backend/internal/routes"
 
 	"github.com/gofiber/fiber/v2"
@@ -18,12 +21,24 @@ import (
 // @description     Public HTTP endpoints for the Event Blog backend.
 // @host            localhost:8080
 // @BasePath        /
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 "Bearer <AccessToken>" or "ApiKey <key>"
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, relying on environment variables")
 	}
 
-	app := fiber.New()
+	// Behind the nginx proxy the client address arrives in X-Real-IP. It is
+	// only believed from TRUSTED_PROXIES, otherwise anyone could pick the IP
+	// that login throttling and sessions record.
+	app := fiber.New(fiber.Config{
+		ProxyHeader:             "X-Real-IP",
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          config.List("TRUSTED_PROXIES"),
+		EnableIPValidation:      true,
+	})
 
 	docs.SwaggerInfo.Title = "Event Blog API"
 	docs.SwaggerInfo.Description = "Public HTTP endpoints for the Event Blog backend."
@@ -36,8 +51,9 @@ func main() {
 
 		AllowOrigins: "http://167.71.218.173:3000/, http://localhost:5173, http://localhost:3000",
 
-		AllowMethods:     "GET, POST, PUT, DELETE, OPTIONS",
+		AllowMethods:     "GET, POST, PUT, PATCH, DELETE, OPTIONS",
 		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		ExposeHeaders:    "X-Total-Count, X-Next-Cursor, Retry-After",
 		AllowCredentials: true,
 	}))
 
@@ -45,6 +61,8 @@ func main() {
 	app.Get("/swagger/*", swagger.HandlerDefault)
 	routes.RegisterRoutes(app)
 
+	jobs.Start(context.Background())
+
 	log.Println("Server running on :8080")
 	if err := app.Listen(":8080"); err != nil {
 		log.Fatal(err)
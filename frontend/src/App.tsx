@@ -6,6 +6,7 @@ import AdminDashboard from './components/AdminDashboard'
 import StockPage from './components/StockPage'
 import StockProductsPage from './components/StockProductsPage'
 import type { User } from './types/user'
+import { apiFetch, clearSession, hasSession } from './utils/api'
 
 const LOCAL_STORAGE_USER_KEY = 'authUser'
 
@@ -40,7 +41,7 @@ function App() {
 
   useEffect(() => {
     const cached = localStorage.getItem(LOCAL_STORAGE_USER_KEY)
-    if (!cached) {
+    if (!cached || !hasSession()) {
       setHydrated(true)
       return
     }
@@ -108,10 +109,9 @@ function App() {
   }, [isAuthenticated, normalizedPath, hydrated])
 
   const handleLoginSuccess = (
-    data?: unknown,
+    loggedInUser?: User,
     context?: { email: string },
   ) => {
-    const loggedInUser = data as User | undefined
     setIsAuthenticated(true)
     setUser(loggedInUser)
     const email = loggedInUser?.Email ?? context?.email
@@ -131,6 +131,9 @@ function App() {
   }
 
   const handleLogout = () => {
+    apiFetch('/api/logout', { method: 'POST' })
+      .catch(() => undefined)
+      .finally(clearSession)
     setIsAuthenticated(false)
     setUserEmail(undefined)
     setUser(undefined)
@@ -1,8 +1,15 @@
 import { type FormEvent, useMemo, useState } from 'react'
-import type { AuthForm, AuthMode } from '../types/auth'
+import type {
+  AuthForm,
+  AuthMode,
+  LoginResponse,
+  TwoFactorChallenge,
+} from '../types/auth'
+import type { User } from '../types/user'
+import { saveSession } from '../utils/api'
 
 type AuthFormProps = {
-  onLoginSuccess?: (data?: unknown, context?: { email: string }) => void
+  onLoginSuccess?: (user?: User, context?: { email: string }) => void
 }
 
 const emptyForm: AuthForm = {
@@ -24,6 +31,9 @@ const backendBaseUrl = normalizeBackendUrl(backendHost)
 
 const apiRoutes = {
   login: backendBaseUrl ? `${backendBaseUrl}/api/login` : '/api/login',
+  loginTwoFactor: backendBaseUrl
+    ? `${backendBaseUrl}/api/login/2fa`
+    : '/api/login/2fa',
   signup: backendBaseUrl ? `${backendBaseUrl}/api/register` : '/api/register',
 }
 
@@ -31,6 +41,11 @@ function AuthFormCard({ onLoginSuccess }: AuthFormProps) {
   const [mode, setMode] = useState<AuthMode>('login')
   const [form, setForm] = useState<AuthForm>(emptyForm)
   const [message, setMessage] = useState<string | null>(null)
+  // Set once the password was accepted for an account with two-factor login;
+  // the login is finished by sending a code with its ChallengeToken.
+  const [challenge, setChallenge] = useState<TwoFactorChallenge | null>(null)
+  const [code, setCode] = useState('')
+  const [useRecoveryCode, setUseRecoveryCode] = useState(false)
 
   const title = useMemo(
     () => (mode === 'login' ? 'Welcome back' : 'Create your account'),
@@ -41,12 +56,66 @@ function AuthFormCard({ onLoginSuccess }: AuthFormProps) {
     setForm((prev) => ({ ...prev, [field]: value }))
   }
 
+  const resetChallenge = () => {
+    setChallenge(null)
+    setCode('')
+    setUseRecoveryCode(false)
+  }
+
   const switchMode = (next: AuthMode, opts?: { keepMessage?: boolean }) => {
     setMode(next)
     if (!opts?.keepMessage) {
       setMessage(null)
     }
     setForm(emptyForm)
+    resetChallenge()
+  }
+
+  const completeLogin = (body: LoginResponse) => {
+    saveSession(body)
+    resetChallenge()
+    setMessage('Logged in successfully.')
+    onLoginSuccess?.(body.User, { email: form.email })
+  }
+
+  const handleTwoFactorSubmit = async (event: FormEvent) => {
+    event.preventDefault()
+    if (!challenge) return
+
+    const trimmedCode = code.trim()
+    if (!trimmedCode) {
+      setMessage(
+        useRecoveryCode ? 'Recovery code is required' : 'Code is required',
+      )
+      return
+    }
+
+    try {
+      setMessage('Verifying code...')
+      const response = await fetch(apiRoutes.loginTwoFactor, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({
+          ChallengeToken: challenge.ChallengeToken,
+          ...(useRecoveryCode
+            ? { RecoveryCode: trimmedCode }
+            : { Code: trimmedCode }),
+        }),
+      })
+
+      if (!response.ok) {
+        const errorText = await response.text()
+        throw new Error(errorText || 'Verification failed')
+      }
+
+      completeLogin((await response.json()) as LoginResponse)
+    } catch (err) {
+      const fallback =
+        err instanceof Error
+          ? err.message
+          : 'Could not verify the code. Please try again.'
+      setMessage(fallback)
+    }
   }
 
   const validate = () => {
@@ -89,11 +158,15 @@ function AuthFormCard({ onLoginSuccess }: AuthFormProps) {
           throw new Error(errorText || 'Login failed')
         }
 
-        const body = await response.json().catch(() => null)
-        const successMessage =
-          (body && (body.message || body.Message)) || 'Logged in successfully.'
-        setMessage(successMessage)
-        onLoginSuccess?.(body, { email: form.email })
+        const body = (await response.json()) as
+          | LoginResponse
+          | TwoFactorChallenge
+        if ('TwoFactorRequired' in body) {
+          setChallenge(body)
+          setMessage('Enter the code from your authenticator app.')
+          return
+        }
+        completeLogin(body)
       } catch (err) {
         const fallback =
           err instanceof Error
@@ -166,67 +239,113 @@ function AuthFormCard({ onLoginSuccess }: AuthFormProps) {
         </button>
       </div>
 
-      <form className="auth-form" onSubmit={handleSubmit}>
-        {mode === 'signup' && (
+      {challenge ? (
+        <form className="auth-form" onSubmit={handleTwoFactorSubmit}>
           <label className="field">
-            <span>Display name</span>
+            <span>
+              {useRecoveryCode ? 'Recovery code' : 'Authentication code'}
+            </span>
             <input
-              name="displayName"
+              name="code"
               type="text"
-              placeholder="Your name as shown publicly"
-              autoComplete="name"
-              value={form.displayName}
-              onChange={(e) => handleChange('displayName')(e.target.value)}
+              placeholder={useRecoveryCode ? 'XXXXX-XXXXX' : '123456'}
+              autoComplete="one-time-code"
+              inputMode={useRecoveryCode ? 'text' : 'numeric'}
+              autoFocus
+              value={code}
+              onChange={(e) => setCode(e.target.value)}
             />
           </label>
-        )}
-
-        <label className="field">
-          <span>Email</span>
-          <input
-            name="email"
-            type="email"
-            placeholder="you@example.com"
-            autoComplete="email"
-            value={form.email}
-            onChange={(e) => handleChange('email')(e.target.value)}
-          />
-        </label>
-
-        <label className="field">
-          <span>Password</span>
-          <input
-            name="password"
-            type="password"
-            placeholder="Minimum 8 characters"
-            autoComplete={mode === 'login' ? 'current-password' : 'new-password'}
-            value={form.password}
-            onChange={(e) => handleChange('password')(e.target.value)}
-          />
-        </label>
-
-        {mode === 'signup' && (
+
+          {message && <div className="banner">{message}</div>}
+
+          <button type="submit" className="submit">
+            Verify
+          </button>
+          <button
+            type="button"
+            onClick={() => {
+              setUseRecoveryCode((prev) => !prev)
+              setCode('')
+            }}
+          >
+            {useRecoveryCode
+              ? 'Use an authenticator code'
+              : 'Use a recovery code'}
+          </button>
+          <button
+            type="button"
+            onClick={() => {
+              resetChallenge()
+              setMessage(null)
+            }}
+          >
+            Back
+          </button>
+        </form>
+      ) : (
+        <form className="auth-form" onSubmit={handleSubmit}>
+          {mode === 'signup' && (
+            <label className="field">
+              <span>Display name</span>
+              <input
+                name="displayName"
+                type="text"
+                placeholder="Your name as shown publicly"
+                autoComplete="name"
+                value={form.displayName}
+                onChange={(e) => handleChange('displayName')(e.target.value)}
+              />
+            </label>
+          )}
+
           <label className="field">
-            <span>Confirm password</span>
+            <span>Email</span>
             <input
-              name="confirmPassword"
+              name="email"
+              type="email"
+              placeholder="you@example.com"
+              autoComplete="email"
+              value={form.email}
+              onChange={(e) => handleChange('email')(e.target.value)}
+            />
+          </label>
+
+          <label className="field">
+            <span>Password</span>
+            <input
+              name="password"
               type="password"
-              placeholder="Re-enter your password"
-              autoComplete="new-password"
-              value={form.confirmPassword}
-              onChange={(e) =>
-                handleChange('confirmPassword')(e.target.value)
-              }
+              placeholder="Minimum 8 characters"
+              autoComplete={mode === 'login' ? 'current-password' : 'new-password'}
+              value={form.password}
+              onChange={(e) => handleChange('password')(e.target.value)}
             />
           </label>
-        )}
 
-        {message && <div className="banner">{message}</div>}
+          {mode === 'signup' && (
+            <label className="field">
+              <span>Confirm password</span>
+              <input
+                name="confirmPassword"
+                type="password"
+                placeholder="Re-enter your password"
+                autoComplete="new-password"
+                value={form.confirmPassword}
+                onChange={(e) =>
+                  handleChange('confirmPassword')(e.target.value)
+                }
+              />
+            </label>
+          )}
 
-        <button type="submit" className="submit">
-          {mode === 'login' ? 'Log in' : 'Create account'}
-        </button>
-      </form>
+          {message && <div className="banner">{message}</div>}
+
+          <button type="submit" className="submit">
+            {mode === 'login' ? 'Log in' : 'Create account'}
+          </button>
+        </form>
+      )}
     </div>
   )
 }
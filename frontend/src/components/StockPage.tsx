@@ -1,7 +1,7 @@
 import { type FormEvent, useEffect, useState } from 'react'
 import type { StockForm, StockItem } from '../types/stock'
 import type { User } from '../types/user'
-import { apiUrl } from '../utils/api'
+import { apiFetch } from '../utils/api'
 
 type StockPageProps = {
   user?: User
@@ -28,21 +28,10 @@ function StockPage({ user }: StockPageProps) {
   const userId = user?.UserId ?? user?.UserID ?? ''
 
   const fetchWarehouse = async () => {
-    if (!userId) {
-      setError('Missing user id to load warehouse.')
-      setWarehouse([])
-      if (typeof window !== 'undefined') {
-        localStorage.removeItem('warehouse-cache')
-      }
-      return
-    }
-
     setLoading(true)
     setError(null)
     try {
-      const response = await fetch(
-        apiUrl(`/api/warehouse?userId=${encodeURIComponent(userId)}`),
-      )
+      const response = await apiFetch('/api/warehouse')
       if (!response.ok) {
         const errorText = await response.text()
         throw new Error(errorText || 'Failed to load warehouse')
@@ -74,11 +63,6 @@ function StockPage({ user }: StockPageProps) {
     setSubmitMessage(null)
     setSubmitError(null)
 
-    if (!userId) {
-      setSubmitError('Missing user id from login response.')
-      return
-    }
-
     const stockName = stockForm.stockName.trim()
     if (!stockName) {
       setSubmitError('Warehouse name is required.')
@@ -89,10 +73,9 @@ function StockPage({ user }: StockPageProps) {
     try {
       const payload = {
         StockName: stockName,
-        UserID: userId,
       }
 
-      const response = await fetch(apiUrl('/api/warehouse'), {
+      const response = await apiFetch('/api/warehouse', {
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify(payload),
@@ -119,10 +102,7 @@ function StockPage({ user }: StockPageProps) {
     }
   }
 
-  const visibleWarehouse =
-    userId && warehouse.length > 0
-      ? warehouse.filter((stock) => stock.UserID === userId)
-      : warehouse
+  const visibleWarehouse = warehouse
 
   const openDeleteModal = (stock: StockItem) => {
     setModalOpen(true)
@@ -152,8 +132,8 @@ function StockPage({ user }: StockPageProps) {
     setSubmitError(null)
     setModalError(null)
     try {
-      const response = await fetch(
-        apiUrl(`/api/warehouse/${encodeURIComponent(modalStock.StockID)}`),
+      const response = await apiFetch(
+        `/api/warehouse/${encodeURIComponent(modalStock.StockID)}`,
         {
           method: 'DELETE',
         },
@@ -188,10 +168,9 @@ function StockPage({ user }: StockPageProps) {
     try {
       const payload = {
         StockName: trimmed,
-        UserID: modalStock.UserID || userId,
       }
-      const response = await fetch(
-        apiUrl(`/api/warehouse/${encodeURIComponent(modalStock.StockID)}`),
+      const response = await apiFetch(
+        `/api/warehouse/${encodeURIComponent(modalStock.StockID)}`,
         {
           method: 'PUT',
           headers: { 'Content-Type': 'application/json' },
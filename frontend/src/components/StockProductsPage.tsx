@@ -2,7 +2,7 @@ import { useEffect, useMemo, useState } from 'react'
 import type { ProductItem } from '../types/product'
 import type { StockItem } from '../types/stock'
 import type { User } from '../types/user'
-import { apiUrl } from '../utils/api'
+import { apiFetch } from '../utils/api'
 
 type StockProductsPageProps = {
   stockName: string
@@ -29,6 +29,26 @@ type CategoryDraft = {
   description: string
 }
 
+// The product list is paged; each response carries the cursor of the next
+// page in X-Next-Cursor until the last one.
+const fetchAllProducts = async (stockId: string) => {
+  const products: ProductItem[] = []
+  let cursor: string | null = null
+  do {
+    const params = new URLSearchParams({ stockId, limit: '200' })
+    if (cursor) params.set('cursor', cursor)
+    const response = await apiFetch(`/api/products?${params.toString()}`)
+    if (!response.ok) {
+      const text = await response.text()
+      throw new Error(text || 'Failed to load products')
+    }
+    const page = (await response.json()) as ProductItem[]
+    if (Array.isArray(page)) products.push(...page)
+    cursor = response.headers.get('X-Next-Cursor')
+  } while (cursor)
+  return products
+}
+
 function StockProductsPage({ stockName, onBack }: StockProductsPageProps) {
   const [stock, setStock] = useState<StockItem | null>(null)
   const [products, setProducts] = useState<ProductItem[]>([])
@@ -100,37 +120,23 @@ function StockProductsPage({ stockName, onBack }: StockProductsPageProps) {
     try {
       setStock(matchedStock)
 
-      const [productsRes, categoriesRes] = await Promise.all([
-        fetch(
-          apiUrl(
-            `/api/products?stockId=${encodeURIComponent(matchedStock.StockID)}`,
-          ),
-        ),
-        fetch(
-          apiUrl(
-            `/api/categories?stockId=${encodeURIComponent(
-              matchedStock.StockID,
-            )}`,
-          ),
+      const [productsBody, categoriesRes] = await Promise.all([
+        fetchAllProducts(matchedStock.StockID),
+        apiFetch(
+          `/api/categories?stockId=${encodeURIComponent(matchedStock.StockID)}`,
         ),
       ])
 
-      if (!productsRes.ok) {
-        const text = await productsRes.text()
-        throw new Error(text || 'Failed to load products')
-      }
-
       if (!categoriesRes.ok) {
         const text = await categoriesRes.text()
         throw new Error(text || 'Failed to load categories')
       }
 
-      const productsBody = (await productsRes.json()) as ProductItem[]
       const categoriesBody = (await categoriesRes.json()) as CategoryItem[]
 
-      const filteredProducts = Array.isArray(productsBody)
-        ? productsBody.filter((p) => p.StockID === matchedStock.StockID)
-        : []
+      const filteredProducts = productsBody.filter(
+        (p) => p.StockID === matchedStock.StockID,
+      )
 
       setProducts(filteredProducts)
       const filteredCategories = Array.isArray(categoriesBody)
@@ -190,8 +196,8 @@ function StockProductsPage({ stockName, onBack }: StockProductsPageProps) {
 
     setSubmittingProduct(true)
     try {
-      const response = await fetch(apiUrl('/api/products'), {
-        method: 'PUT',
+      const response = await apiFetch('/api/products', {
+        method: 'POST',
         headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify(payload),
       })
@@ -266,7 +272,7 @@ function StockProductsPage({ stockName, onBack }: StockProductsPageProps) {
     console.log(JSON.stringify(payload))
     setSubmittingCategories(true)
     try {
-      const response = await fetch(apiUrl('/api/categories'), {
+      const response = await apiFetch('/api/categories', {
         method: 'POST',
         headers: { 'Content-Type': 'application/json' },
         body: JSON.stringify(payload),
@@ -310,8 +316,8 @@ function StockProductsPage({ stockName, onBack }: StockProductsPageProps) {
 
     setDeletingCategoryId(categoryId)
     try {
-      const response = await fetch(
-        apiUrl(`/api/categories/${encodeURIComponent(categoryId)}`),
+      const response = await apiFetch(
+        `/api/categories/${encodeURIComponent(categoryId)}`,
         { method: 'DELETE' },
       )
 
@@ -341,8 +347,8 @@ function StockProductsPage({ stockName, onBack }: StockProductsPageProps) {
 
     setDeletingProductId(productId)
     try {
-      const response = await fetch(
-        apiUrl(`/api/products/${encodeURIComponent(productId)}`),
+      const response = await apiFetch(
+        `/api/products/${encodeURIComponent(productId)}`,
         { method: 'DELETE' },
       )
 
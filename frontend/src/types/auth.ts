@@ -1,3 +1,5 @@
+import type { User } from './user'
+
 export type AuthMode = 'login' | 'signup'
 
 export type AuthForm = {
@@ -6,3 +8,19 @@ export type AuthForm = {
   displayName: string
   confirmPassword: string
 }
+
+export type LoginResponse = {
+  AccessToken: string
+  TokenType: string
+  ExpiresAt: string
+  RefreshToken: string
+  RefreshExpiresAt: string
+  SessionID: string
+  User: User
+}
+
+export type TwoFactorChallenge = {
+  TwoFactorRequired: true
+  ChallengeToken: string
+  ExpiresAt: string
+}
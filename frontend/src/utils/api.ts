@@ -1,3 +1,5 @@
+import type { LoginResponse } from '../types/auth'
+
 const normalizeBackendUrl = (host?: string) => {
   if (!host) return ''
   const trimmed = host.trim().replace(/\/+$/, '')
@@ -10,3 +12,84 @@ export const backendBaseUrl = normalizeBackendUrl(backendHost)
 
 export const apiUrl = (path: string) =>
   backendBaseUrl ? `${backendBaseUrl}${path}` : path
+
+const LOCAL_STORAGE_SESSION_KEY = 'authSession'
+
+type StoredSession = {
+  accessToken: string
+  refreshToken: string
+}
+
+const readSession = (): StoredSession | null => {
+  if (typeof window === 'undefined') return null
+  const raw = localStorage.getItem(LOCAL_STORAGE_SESSION_KEY)
+  if (!raw) return null
+  try {
+    const parsed = JSON.parse(raw) as StoredSession
+    return parsed.accessToken ? parsed : null
+  } catch {
+    return null
+  }
+}
+
+export const hasSession = () => readSession() !== null
+
+// saveSession keeps the tokens of a login or refresh response.
+export const saveSession = (login: LoginResponse) => {
+  const session: StoredSession = {
+    accessToken: login.AccessToken,
+    refreshToken: login.RefreshToken,
+  }
+  localStorage.setItem(LOCAL_STORAGE_SESSION_KEY, JSON.stringify(session))
+}
+
+export const clearSession = () => {
+  localStorage.removeItem(LOCAL_STORAGE_SESSION_KEY)
+}
+
+// The backend revokes the whole session when a rotated refresh token is used
+// again, so concurrent requests share a single refresh.
+let pendingRefresh: Promise<string | null> | null = null
+
+const refreshAccessToken = (refreshToken: string) => {
+  if (!pendingRefresh) {
+    pendingRefresh = (async () => {
+      try {
+        const response = await fetch(apiUrl('/api/token/refresh'), {
+          method: 'POST',
+          headers: { 'Content-Type': 'application/json' },
+          body: JSON.stringify({ RefreshToken: refreshToken }),
+        })
+        if (!response.ok) {
+          clearSession()
+          return null
+        }
+        const body = (await response.json()) as LoginResponse
+        saveSession(body)
+        return body.AccessToken
+      } catch {
+        return null
+      } finally {
+        pendingRefresh = null
+      }
+    })()
+  }
+  return pendingRefresh
+}
+
+// apiFetch calls the backend with the stored access token, refreshing it once
+// when the backend answers 401.
+export const apiFetch = async (path: string, init: RequestInit = {}) => {
+  const send = (accessToken?: string) => {
+    const headers = new Headers(init.headers)
+    if (accessToken) headers.set('Authorization', `Bearer ${accessToken}`)
+    return fetch(apiUrl(path), { ...init, headers })
+  }
+
+  const session = readSession()
+  const response = await send(session?.accessToken)
+  if (response.status !== 401 || !session?.refreshToken) return response
+
+  const accessToken = await refreshAccessToken(session.refreshToken)
+  return accessToken ? send(accessToken) : response
+}